@@ -1,42 +1,136 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/lidofinance/terra-monitors/internal/app"
+	"github.com/lidofinance/terra-monitors/internal/app/alerting"
 	"github.com/lidofinance/terra-monitors/internal/app/collector"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
 	"github.com/lidofinance/terra-monitors/internal/app/extractor"
+	"github.com/lidofinance/terra-monitors/internal/app/notifier"
 	"github.com/lidofinance/terra-monitors/internal/pkg/logging"
 )
 
 var addr = flag.String("listen-address", ":8080",
 	"The address to listen on for HTTP requests.")
+var configPath = flag.String("config", "",
+	"The path to a YAML or JSON config file, the environment variables take precedence over its values.")
 
 func main() {
 	flag.Parse()
 
 	logger := logging.NewDefaultLogger()
 
-	cfg, err := config.NewCollectorConfig()
+	cfg, err := config.LoadCollectorConfig(*configPath)
 	if err != nil {
-		logger.Fatalf("Failed to create NewCollectorConfig: %s", err)
+		logger.Fatalf("Failed to load config: %s", err)
 	}
 
-	col, err := collector.New(cfg, logger)
+	// ctx is cancelled on the first SIGINT/SIGTERM, the second one kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// the rules and the notification channels are set up once, they are not reloaded with the config
+	var rules []alerting.Rule
+	if cfg.Alerting.RulesFile != "" {
+		if rules, err = alerting.LoadRules(cfg.Alerting.RulesFile); err != nil {
+			logger.Fatalf("Failed to load alert rules: %s", err)
+		}
+		logger.Infof("Loaded %d alert rules", len(rules))
+	}
+	notifiers, err := notifier.NewNotifiers(cfg, logger)
+	if err != nil {
+		logger.Fatalf("Failed to create notifiers: %s", err)
+	}
+
+	col, err := collector.NewGroup(ctx, cfg, logger)
 	if err != nil {
 		logger.Fatalf("Failed to create collector: %s", err)
 	}
+	dispatcher := notifier.NewDispatcher(ctx, notifiers, cfg.Notifications, logger)
+	router, err := notifier.NewRouter(ctx, cfg.Notifications, dispatcher.Notify, logger)
+	if err != nil {
+		logger.Fatalf("Failed to create notifications router: %s", err)
+	}
+	alerts := alerting.NewEngine(rules, cfg.Alerting.ResolvedRetention, logger)
+	router.Watch(alerts, col)
+	alerts.Watch(col)
+
+	// the config is reloaded on SIGHUP and POST /admin/reload
+	loadConfig := func() (config.CollectorConfig, error) {
+		return config.LoadCollectorConfig(*configPath)
+	}
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-reload:
+				logger.Infoln("Reloading config on SIGHUP")
+				_, _ = col.Reload(loadConfig)
+			}
+		}
+	}()
 
 	var (
 		promExtractor = extractor.NewPromExtractor(col, logger)
-		appInstance   = app.NewAppHTTP(promExtractor)
+		appInstance   = app.NewAppHTTP(promExtractor, alerts, dispatcher)
+		mux           = http.NewServeMux()
 	)
-	http.Handle("/metrics", appInstance)
-	logger.Printf("Starting web server v%s at %s\n", cfg.BassetContractsVersion, *addr)
+	mux.Handle("/metrics", appInstance)
+	// the admin token is not reloaded with the config
+	mux.Handle("/admin/reload", app.RequireAdminToken(cfg.Admin.Token, app.NewReloadHandler(col, loadConfig, logger), logger))
+	mux.HandleFunc("/healthz", app.HealthHandler)
+	mux.Handle("/readyz", app.NewReadyHandler(col, logger))
+	mux.Handle("/status", app.NewStatusHandler(col, logger))
+	mux.Handle("/api/", app.NewAPIHandler(col, alerts, logger))
+	silences := app.RequireAdminToken(cfg.Admin.Token, app.NewSilencesHandler(router, logger), logger)
+	mux.Handle("/admin/silences", silences)
+	mux.Handle("/admin/silences/", silences)
+	mux.Handle("/admin/maintenance", silences)
+	mux.Handle("/admin/maintenance/", silences)
+	server := &http.Server{Addr: *addr, Handler: mux}
+
+	go func() {
+		logger.Printf("Starting web server v%s at %s\n", cfg.BassetContractsVersion, *addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("Failed to ListenAndServe: %v\n", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	logger.Infoln("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Errorf("Failed to shutdown web server: %v\n", err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		col.Stop()
+		dispatcher.Stop()
+		close(stopped)
+	}()
 
-	if err := http.ListenAndServe(*addr, nil); err != nil {
-		logger.Errorf("Failed to ListenAndServe: %v\n", err)
+	select {
+	case <-stopped:
+		logger.Infoln("Collector stopped")
+	case <-shutdownCtx.Done():
+		logger.Warningln("Collector stop timed out, exiting with monitors still running")
 	}
 }
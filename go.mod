@@ -5,6 +5,8 @@ go 1.16
 require (
 	github.com/asaskevich/govalidator v0.0.0-20210307081110-f21760c49a8d // indirect
 	github.com/cosmos/cosmos-sdk v0.44.4
+	github.com/go-openapi/runtime v0.21.0
+	github.com/go-openapi/strfmt v0.21.1
 	github.com/gorilla/mux v1.8.0
 	github.com/lidofinance/terra-fcd-rest-client v0.0.0-20220512130920-2131001551bd
 	github.com/lidofinance/terra-repositories v0.0.0-20211216152128-33a198aeb9d9
@@ -15,6 +17,9 @@ require (
 	github.com/stretchr/testify v1.7.0
 	github.com/vrischmann/envconfig v1.3.0
 	golang.org/x/net v0.0.0-20210716203947-853a461950ff // indirect
+	golang.org/x/sync v0.0.0-20220907140024-f12130a52804
+	golang.org/x/time v0.0.0-20220609170525-579cf78fd858
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 // we need this replaces due to
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RequireAdminToken allows the requests to the admin handler with the token in the Authorization: Bearer header
+// only. All the requests are rejected if the token is empty, so the admin API is never open by mistake.
+func RequireAdminToken(token string, handler http.Handler, logger *logrus.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			writeJSON(w, http.StatusForbidden, APIError{Error: "admin API is disabled, ADMIN_TOKEN is not set"}, logger)
+			return
+		}
+		authorization := r.Header.Get("Authorization")
+		provided := strings.TrimPrefix(authorization, "Bearer ")
+		if provided == authorization || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, APIError{Error: "invalid admin token"}, logger)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
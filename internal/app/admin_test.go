@@ -0,0 +1,37 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireAdminToken(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	request := func(handler http.Handler, authorization string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/maintenance", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	handler := RequireAdminToken("secret", ok, stubs.NewTestLogger())
+	require.Equal(t, http.StatusNoContent, request(handler, "Bearer secret"))
+	require.Equal(t, http.StatusUnauthorized, request(handler, ""))
+	require.Equal(t, http.StatusUnauthorized, request(handler, "Bearer other"))
+	require.Equal(t, http.StatusUnauthorized, request(handler, "secret"))
+
+	// the admin API is disabled without the token
+	disabled := RequireAdminToken("", ok, stubs.NewTestLogger())
+	require.Equal(t, http.StatusForbidden, request(disabled, ""))
+	require.Equal(t, http.StatusForbidden, request(disabled, "Bearer "))
+}
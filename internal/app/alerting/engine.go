@@ -0,0 +1,310 @@
+package alerting
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector"
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// State is the state of an alert.
+type State string
+
+const (
+	// StatePending is the state of the alert whose condition holds for less than the rule For duration.
+	StatePending State = "pending"
+	// StateFiring is the state of the alert whose condition holds for the rule For duration.
+	StateFiring State = "firing"
+	// StateResolved is the state of the fired alert whose condition doesn't hold anymore.
+	StateResolved State = "resolved"
+)
+
+const (
+	AlertNameLabel  = "alertname"
+	AlertStateLabel = "alertstate"
+	SeverityLabel   = "severity"
+)
+
+// Alert is the state of a rule for a single series.
+type Alert struct {
+	Rule     string
+	Severity string
+	Summary  string
+	Labels   map[string]string
+	State    State
+	// Value is the last series value satisfying the rule condition
+	Value float64
+	// ActiveAt is the time the condition started to hold, FiredAt and ResolvedAt are zero until the state changes
+	ActiveAt   time.Time
+	FiredAt    time.Time
+	ResolvedAt time.Time
+}
+
+// Fingerprint identifies the alert by the rule name and the series labels, e.g. Rule{deployment="mainnet"}.
+func (a Alert) Fingerprint() string {
+	return fingerprint(a.Rule, a.Labels)
+}
+
+func (a Alert) copy() Alert {
+	a.Labels = copyLabels(a.Labels)
+	return a
+}
+
+func fingerprint(rule string, labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(rule)
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[name]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// Engine evaluates the alert rules against the collectors snapshots and keeps the alerts state. The fired alerts
+// are kept for the resolvedRetention once resolved, the pending alerts are dropped once their condition doesn't hold.
+type Engine struct {
+	rules             []Rule
+	resolvedRetention time.Duration
+	logger            *logrus.Logger
+	// now returns the current time, it's replaced by the tests
+	now func() time.Time
+
+	// evalLock serializes the evaluations, so the snapshots are applied and the changes are delivered in order
+	evalLock sync.Mutex
+	lock     sync.Mutex
+	// alerts are keyed by the alert fingerprint
+	alerts map[string]*Alert
+	// listeners are called with the alerts once they fire or resolve
+	listeners []func(alert Alert)
+}
+
+func NewEngine(rules []Rule, resolvedRetention time.Duration, logger *logrus.Logger) *Engine {
+	return &Engine{
+		rules:             rules,
+		resolvedRetention: resolvedRetention,
+		logger:            logger,
+		now:               time.Now,
+		alerts:            make(map[string]*Alert),
+	}
+}
+
+// Watch evaluates the rules after every update of the group collectors. The current snapshots are evaluated at once,
+// so the updates made before the call are not missed. The alerts of the deployments removed from the group
+// are resolved.
+func (e *Engine) Watch(g *collector.Group) {
+	g.OnUpdate(e.Evaluate)
+	g.OnRemove(e.Remove)
+	for _, c := range g.Collectors() {
+		e.Evaluate(c)
+	}
+}
+
+// OnChange adds the listener called with the alerts once they fire or resolve. The listeners are called
+// by the evaluation, they must not block.
+func (e *Engine) OnChange(listener func(alert Alert)) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.listeners = append(e.listeners, listener)
+}
+
+// Evaluate evaluates the rules against the collector snapshot, the alerts of other deployments are kept intact.
+// The concurrent evaluations are serialized, and the snapshot is taken once the previous evaluation is over,
+// so an older snapshot is never applied after a newer one.
+func (e *Engine) Evaluate(c *collector.Collector) {
+	e.evalLock.Lock()
+	defer e.evalLock.Unlock()
+	e.notify(e.evaluate(c.Deployment(), c.ChainID(), c.Snapshot(), e.now()))
+}
+
+// Remove resolves the firing alerts of the deployment and drops its pending ones, e.g. once the deployment
+// is removed from the config.
+func (e *Engine) Remove(deployment string) {
+	e.evalLock.Lock()
+	defer e.evalLock.Unlock()
+	e.notify(e.remove(deployment, e.now()))
+}
+
+// notify calls the listeners with the changed alerts in order, it's called with the evalLock held.
+func (e *Engine) notify(changed []Alert) {
+	e.lock.Lock()
+	listeners := e.listeners
+	e.lock.Unlock()
+	for _, alert := range changed {
+		for _, listener := range listeners {
+			listener(alert)
+		}
+	}
+}
+
+// series is a value of a metric with the labels of the metric vector value and the deployment.
+type series struct {
+	labels map[string]string
+	value  float64
+}
+
+// metricSeries returns the series of the metric in the snapshot, the deployment labels are added to every series.
+func metricSeries(name string, snapshot *collector.Snapshot, deploymentLabels map[string]string) []series {
+	var out []series
+	if value, found := snapshot.Metrics[monitors.MetricName(name)]; found {
+		out = append(out, series{labels: copyLabels(deploymentLabels, nil), value: value})
+	}
+	if vector, found := snapshot.Vectors[monitors.MetricName(name)]; found {
+		for _, v := range vector.Values {
+			out = append(out, series{labels: copyLabels(deploymentLabels, v.Labels), value: v.Value})
+		}
+	}
+	return out
+}
+
+func copyLabels(sets ...map[string]string) map[string]string {
+	labels := make(map[string]string)
+	for _, set := range sets {
+		for name, value := range set {
+			labels[name] = value
+		}
+	}
+	return labels
+}
+
+// evaluate updates the alerts of the deployment and returns the copies of the fired and resolved ones.
+func (e *Engine) evaluate(deployment, chainID string, snapshot *collector.Snapshot, now time.Time) []Alert {
+	deploymentLabels := map[string]string{collector.DeploymentLabel: deployment, collector.ChainIDLabel: chainID}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	var changed []Alert
+	active := make(map[string]bool)
+	for _, rule := range e.rules {
+		for _, s := range metricSeries(rule.Metric, snapshot, deploymentLabels) {
+			if !rule.matches(s.labels) || !rule.Op.Compare(s.value, rule.Threshold) {
+				continue
+			}
+			key := fingerprint(rule.Name, s.labels)
+			active[key] = true
+			alert, found := e.alerts[key]
+			if !found || alert.State == StateResolved {
+				alert = &Alert{
+					Rule:     rule.Name,
+					Severity: rule.Severity,
+					Summary:  rule.Summary,
+					Labels:   s.labels,
+					State:    StatePending,
+					ActiveAt: now,
+				}
+				e.alerts[key] = alert
+			}
+			alert.Value = s.value
+			if alert.State == StatePending && now.Sub(alert.ActiveAt) >= rule.For {
+				alert.State, alert.FiredAt = StateFiring, now
+				e.logger.Warningf("alert %s is firing for %v: value %v %s %v",
+					rule.Name, alert.Labels, alert.Value, rule.Op, rule.Threshold)
+				changed = append(changed, alert.copy())
+			}
+		}
+	}
+
+	for key, alert := range e.alerts {
+		switch {
+		// the resolved alerts of all the deployments expire, including the removed ones
+		case alert.State == StateResolved && now.Sub(alert.ResolvedAt) > e.resolvedRetention:
+			delete(e.alerts, key)
+		case alert.Labels[collector.DeploymentLabel] != deployment || active[key]:
+		default:
+			changed = append(changed, e.resolve(key, alert, now)...)
+		}
+	}
+	return changed
+}
+
+// remove resolves the alerts of the deployment and returns the copies of the resolved ones.
+func (e *Engine) remove(deployment string, now time.Time) []Alert {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	var changed []Alert
+	for key, alert := range e.alerts {
+		if alert.Labels[collector.DeploymentLabel] == deployment {
+			changed = append(changed, e.resolve(key, alert, now)...)
+		}
+	}
+	return changed
+}
+
+// resolve drops the pending alert and resolves the firing one, it's called with the lock held.
+func (e *Engine) resolve(key string, alert *Alert, now time.Time) []Alert {
+	switch alert.State {
+	case StatePending:
+		delete(e.alerts, key)
+	case StateFiring:
+		alert.State, alert.ResolvedAt = StateResolved, now
+		e.logger.Infof("alert %s is resolved for %v", alert.Rule, alert.Labels)
+		return []Alert{alert.copy()}
+	}
+	return nil
+}
+
+// Alerts returns the pending, firing and recently resolved alerts sorted by the rule name and the labels.
+func (e *Engine) Alerts() []Alert {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	alerts := make([]Alert, 0, len(e.alerts))
+	for _, alert := range e.alerts {
+		alerts = append(alerts, alert.copy())
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Fingerprint() < alerts[j].Fingerprint()
+	})
+	return alerts
+}
+
+// Describe sends no descriptors, which makes Engine an unchecked collector: the ALERTS series of different rules
+// have different label names.
+func (e *Engine) Describe(chan<- *prometheus.Desc) {
+}
+
+// Collect exports the pending and firing alerts as the ALERTS series valued 1 with the alert labels.
+func (e *Engine) Collect(ch chan<- prometheus.Metric) {
+	for _, alert := range e.Alerts() {
+		if alert.State == StateResolved {
+			continue
+		}
+		labels := copyLabels(alert.Labels, map[string]string{
+			AlertNameLabel:  alert.Rule,
+			AlertStateLabel: string(alert.State),
+			SeverityLabel:   alert.Severity,
+		})
+		names := make([]string, 0, len(labels))
+		for name := range labels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		values := make([]string, 0, len(names))
+		for _, name := range names {
+			values = append(values, labels[name])
+		}
+		desc := prometheus.NewDesc("ALERTS", "Pending and firing alerts of the built-in alerting.", names, nil)
+		metric, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, 1, values...)
+		if err != nil {
+			e.logger.Errorf("failed to collect alert %s: %v", alert.Rule, err)
+			continue
+		}
+		ch <- metric
+	}
+}
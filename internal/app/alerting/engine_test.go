@@ -0,0 +1,194 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector"
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func testSnapshot(rate float64, jailed map[string]float64) *collector.Snapshot {
+	vector := collector.VectorSnapshot{LabelNames: []string{monitors.ValidatorAddressLabel}}
+	for address, value := range jailed {
+		vector.Values = append(vector.Values, collector.LabeledValue{
+			Labels: monitors.Labels{monitors.ValidatorAddressLabel: address},
+			Value:  value,
+		})
+	}
+	return &collector.Snapshot{
+		Metrics: map[monitors.MetricName]float64{"bluna_exchange_rate": rate},
+		Vectors: map[monitors.MetricName]collector.VectorSnapshot{"jailed_validator": vector},
+	}
+}
+
+func testEngine() *Engine {
+	return NewEngine([]Rule{
+		{Name: "ExchangeRateDrop", Metric: "bluna_exchange_rate", Op: LessThan, Threshold: 1, For: time.Minute,
+			Severity: "critical"},
+		{Name: "ValidatorJailed", Metric: "jailed_validator", Op: GreaterThan, Threshold: 0, Severity: DefaultSeverity},
+	}, 10*time.Minute, stubs.NewTestLogger())
+}
+
+func alertStates(e *Engine) map[string]State {
+	states := make(map[string]State)
+	for _, alert := range e.Alerts() {
+		states[alert.Rule+"/"+alert.Labels[collector.DeploymentLabel]+"/"+
+			alert.Labels[monitors.ValidatorAddressLabel]] = alert.State
+	}
+	return states
+}
+
+func TestEngineStates(t *testing.T) {
+	req := require.New(t)
+	e := testEngine()
+	start := time.Now()
+
+	e.evaluate("mainnet", "columbus-5", testSnapshot(0.99, map[string]float64{"val1": 1, "val2": 0}), start)
+	req.Equal(map[string]State{
+		"ExchangeRateDrop/mainnet/":    StatePending,
+		"ValidatorJailed/mainnet/val1": StateFiring,
+	}, alertStates(e))
+
+	// the condition holds for the rule For duration
+	e.evaluate("mainnet", "columbus-5", testSnapshot(0.98, map[string]float64{"val1": 1}), start.Add(time.Minute))
+	req.Equal(map[string]State{
+		"ExchangeRateDrop/mainnet/":    StateFiring,
+		"ValidatorJailed/mainnet/val1": StateFiring,
+	}, alertStates(e))
+	alerts := e.Alerts()
+	req.Equal(0.98, alerts[0].Value)
+	req.Equal(start, alerts[0].ActiveAt)
+	req.Equal(start.Add(time.Minute), alerts[0].FiredAt)
+
+	// the alerts of other deployments are kept
+	e.evaluate("testnet", "bombay-12", testSnapshot(1, nil), start.Add(2*time.Minute))
+	req.Len(e.Alerts(), 2)
+
+	e.evaluate("mainnet", "columbus-5", testSnapshot(1, map[string]float64{"val1": 1}), start.Add(3*time.Minute))
+	req.Equal(map[string]State{
+		"ExchangeRateDrop/mainnet/":    StateResolved,
+		"ValidatorJailed/mainnet/val1": StateFiring,
+	}, alertStates(e))
+	req.Equal(start.Add(3*time.Minute), e.Alerts()[0].ResolvedAt)
+
+	// the resolved alert starts over once the condition holds again
+	e.evaluate("mainnet", "columbus-5", testSnapshot(0.5, map[string]float64{"val1": 0}), start.Add(4*time.Minute))
+	req.Equal(map[string]State{
+		"ExchangeRateDrop/mainnet/":    StatePending,
+		"ValidatorJailed/mainnet/val1": StateResolved,
+	}, alertStates(e))
+
+	// the pending alert is dropped and the resolved one is kept for the retention
+	e.evaluate("mainnet", "columbus-5", testSnapshot(1, nil), start.Add(10*time.Minute))
+	req.Equal(map[string]State{"ValidatorJailed/mainnet/val1": StateResolved}, alertStates(e))
+	e.evaluate("mainnet", "columbus-5", testSnapshot(1, nil), start.Add(15*time.Minute))
+	req.Empty(e.Alerts())
+}
+
+func TestEngineRuleLabels(t *testing.T) {
+	req := require.New(t)
+	e := NewEngine([]Rule{{
+		Name:      "MainnetValidatorJailed",
+		Metric:    "jailed_validator",
+		Labels:    map[string]string{collector.DeploymentLabel: "mainnet", monitors.ValidatorAddressLabel: "val2"},
+		Op:        Equal,
+		Threshold: 1,
+	}}, time.Minute, stubs.NewTestLogger())
+
+	jailed := map[string]float64{"val1": 1, "val2": 1}
+	e.evaluate("testnet", "bombay-12", testSnapshot(1, jailed), time.Now())
+	req.Empty(e.Alerts())
+	e.evaluate("mainnet", "columbus-5", testSnapshot(1, jailed), time.Now())
+	req.Equal(map[string]State{"MainnetValidatorJailed/mainnet/val2": StateFiring}, alertStates(e))
+}
+
+func TestEngineCollect(t *testing.T) {
+	req := require.New(t)
+	e := testEngine()
+	start := time.Now()
+	e.evaluate("mainnet", "columbus-5", testSnapshot(0.99, map[string]float64{"val1": 1}), start)
+	e.evaluate("testnet", "bombay-12", testSnapshot(1, map[string]float64{"val3": 1}), start)
+	e.evaluate("testnet", "bombay-12", testSnapshot(1, nil), start.Add(time.Minute))
+
+	registry := prometheus.NewRegistry()
+	req.NoError(registry.Register(e))
+	families, err := registry.Gather()
+	req.NoError(err)
+	req.Len(families, 1)
+	req.Equal("ALERTS", families[0].GetName())
+
+	// the resolved alert of testnet isn't exported
+	var series []map[string]string
+	for _, metric := range families[0].GetMetric() {
+		req.Equal(1.0, metric.GetGauge().GetValue())
+		labels := make(map[string]string)
+		for _, pair := range metric.GetLabel() {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+		series = append(series, labels)
+	}
+	req.ElementsMatch([]map[string]string{
+		{
+			AlertNameLabel: "ExchangeRateDrop", AlertStateLabel: "pending", SeverityLabel: "critical",
+			collector.DeploymentLabel: "mainnet", collector.ChainIDLabel: "columbus-5",
+		},
+		{
+			AlertNameLabel: "ValidatorJailed", AlertStateLabel: "firing", SeverityLabel: DefaultSeverity,
+			collector.DeploymentLabel: "mainnet", collector.ChainIDLabel: "columbus-5",
+			monitors.ValidatorAddressLabel: "val1",
+		},
+	}, series)
+}
+
+func TestEngineChanges(t *testing.T) {
+	req := require.New(t)
+	e := testEngine()
+	start := time.Now()
+
+	changed := e.evaluate("mainnet", "columbus-5", testSnapshot(0.99, map[string]float64{"val1": 1}), start)
+	req.Len(changed, 1, "the pending alert isn't reported")
+	req.Equal("ValidatorJailed", changed[0].Rule)
+	req.Equal(StateFiring, changed[0].State)
+
+	req.Empty(e.evaluate("mainnet", "columbus-5", testSnapshot(0.99, map[string]float64{"val1": 1}), start))
+
+	changed = e.evaluate("mainnet", "columbus-5", testSnapshot(0.99, nil), start.Add(time.Minute))
+	req.Len(changed, 2)
+	states := map[string]State{changed[0].Rule: changed[0].State, changed[1].Rule: changed[1].State}
+	req.Equal(map[string]State{"ExchangeRateDrop": StateFiring, "ValidatorJailed": StateResolved}, states)
+}
+
+func TestEngineRemove(t *testing.T) {
+	req := require.New(t)
+	e := testEngine()
+	start := time.Now()
+	e.now = func() time.Time {
+		return start.Add(time.Minute)
+	}
+	var changed []Alert
+	e.OnChange(func(alert Alert) {
+		changed = append(changed, alert)
+	})
+
+	e.evaluate("mainnet", "columbus-5", testSnapshot(0.99, map[string]float64{"val1": 1}), start)
+	e.evaluate("testnet", "bombay-12", testSnapshot(1, map[string]float64{"val2": 1}), start)
+	req.Len(e.Alerts(), 3)
+
+	// the firing alert of the removed deployment is resolved, the pending one is dropped
+	e.Remove("mainnet")
+	req.Len(changed, 1)
+	req.Equal(StateResolved, changed[0].State)
+	req.Equal(map[string]State{
+		"ValidatorJailed/mainnet/val1": StateResolved,
+		"ValidatorJailed/testnet/val2": StateFiring,
+	}, alertStates(e))
+
+	// the resolved alerts of the removed deployment expire once any deployment is evaluated
+	e.evaluate("testnet", "bombay-12", testSnapshot(1, map[string]float64{"val2": 1}), start.Add(time.Hour))
+	req.Equal(map[string]State{"ValidatorJailed/testnet/val2": StateFiring}, alertStates(e))
+}
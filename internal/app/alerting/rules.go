@@ -0,0 +1,138 @@
+package alerting
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Comparison is the operator comparing a metric value with the rule threshold.
+type Comparison string
+
+const (
+	GreaterThan    Comparison = ">"
+	GreaterOrEqual Comparison = ">="
+	LessThan       Comparison = "<"
+	LessOrEqual    Comparison = "<="
+	Equal          Comparison = "=="
+	NotEqual       Comparison = "!="
+)
+
+// Compare reports whether the value compared with the threshold satisfies the operator.
+func (c Comparison) Compare(value, threshold float64) bool {
+	switch c {
+	case GreaterThan:
+		return value > threshold
+	case GreaterOrEqual:
+		return value >= threshold
+	case LessThan:
+		return value < threshold
+	case LessOrEqual:
+		return value <= threshold
+	case Equal:
+		return value == threshold
+	case NotEqual:
+		return value != threshold
+	default:
+		return false
+	}
+}
+
+func (c Comparison) valid() bool {
+	switch c {
+	case GreaterThan, GreaterOrEqual, LessThan, LessOrEqual, Equal, NotEqual:
+		return true
+	default:
+		return false
+	}
+}
+
+// DefaultSeverity is the severity of the rules not specifying it.
+const DefaultSeverity = "warning"
+
+// Rule fires an alert for every series of the metric matching the labels once the series value compared with
+// the threshold satisfies the operator for the For duration.
+type Rule struct {
+	// Name is the unique name of the rule, it's the alertname label of the alerts
+	Name   string `yaml:"name"`
+	Metric string `yaml:"metric"`
+	// Labels match the series having the same label values, the deployment and chain_id labels can be matched too
+	Labels    map[string]string `yaml:"labels"`
+	Op        Comparison        `yaml:"op"`
+	Threshold float64           `yaml:"threshold"`
+	// For is the time the condition must hold before the pending alert fires, 0 fires the alert at once
+	For      time.Duration `yaml:"for"`
+	Severity string        `yaml:"severity"`
+	// Summary is a human-readable description of the alert, optional
+	Summary string `yaml:"summary"`
+}
+
+// matches reports whether the series labels match the rule labels.
+func (r Rule) matches(labels map[string]string) bool {
+	for name, value := range r.Labels {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// RulesFile is the format of the alert rules file.
+type RulesFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// LoadRules reads the rules from the YAML or JSON file at path and validates them.
+func LoadRules(path string) ([]Rule, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+	// JSON is a subset of YAML, so the same decoder is used for both formats
+	var file RulesFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file %s: %w", path, err)
+	}
+	for i := range file.Rules {
+		if file.Rules[i].Severity == "" {
+			file.Rules[i].Severity = DefaultSeverity
+		}
+	}
+	if err := ValidateRules(file.Rules); err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %w", path, err)
+	}
+	return file.Rules, nil
+}
+
+// ValidateRules checks all the rules and reports all the errors at once.
+func ValidateRules(rules []Rule) error {
+	var errs []string
+	addErr := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+	names := make(map[string]bool, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" {
+			addErr("rule #%d: name is required", i+1)
+		} else if names[rule.Name] {
+			addErr("rule %s: duplicate name", rule.Name)
+		}
+		names[rule.Name] = true
+		if rule.Metric == "" {
+			addErr("rule %s: metric is required", rule.Name)
+		}
+		if !rule.Op.valid() {
+			addErr("rule %s: unsupported op \"%s\", expected one of >, >=, <, <=, ==, !=", rule.Name, rule.Op)
+		}
+		if rule.For < 0 {
+			addErr("rule %s: for must not be negative, got %s", rule.Name, rule.For)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
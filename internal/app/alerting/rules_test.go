@@ -0,0 +1,75 @@
+package alerting
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeRules(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadRules(t *testing.T) {
+	req := require.New(t)
+	rules, err := LoadRules(writeRules(t, `
+rules:
+  - name: ExchangeRateDrop
+    metric: bluna_exchange_rate
+    labels:
+      deployment: mainnet
+    op: "<"
+    threshold: 1
+    for: 5m
+    severity: critical
+    summary: bLuna exchange rate is below 1
+  - name: ValidatorJailed
+    metric: jailed_validator
+    op: ">"
+    threshold: 0
+`))
+	req.NoError(err)
+	req.Equal([]Rule{
+		{
+			Name:      "ExchangeRateDrop",
+			Metric:    "bluna_exchange_rate",
+			Labels:    map[string]string{"deployment": "mainnet"},
+			Op:        LessThan,
+			Threshold: 1,
+			For:       5 * time.Minute,
+			Severity:  "critical",
+			Summary:   "bLuna exchange rate is below 1",
+		},
+		{
+			Name:     "ValidatorJailed",
+			Metric:   "jailed_validator",
+			Op:       GreaterThan,
+			Severity: DefaultSeverity,
+		},
+	}, rules)
+
+	_, err = LoadRules(writeRules(t, `rules: [{name: A, metric: m, op: ">", treshold: 1}]`))
+	req.Error(err)
+
+	_, err = LoadRules(filepath.Join(t.TempDir(), "missing.yaml"))
+	req.Error(err)
+}
+
+func TestValidateRules(t *testing.T) {
+	req := require.New(t)
+	req.NoError(ValidateRules([]Rule{{Name: "A", Metric: "m", Op: NotEqual}}))
+
+	err := ValidateRules([]Rule{
+		{Name: "A", Metric: "m", Op: GreaterThan},
+		{Name: "A", Op: "=>", For: -time.Second},
+		{Metric: "m", Op: Equal},
+	})
+	req.EqualError(err, `rule A: duplicate name; rule A: metric is required; `+
+		`rule A: unsupported op "=>", expected one of >, >=, <, <=, ==, !=; rule A: for must not be negative, got -1s; `+
+		`rule #3: name is required`)
+}
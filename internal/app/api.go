@@ -0,0 +1,317 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/alerting"
+	"github.com/lidofinance/terra-monitors/internal/app/collector"
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// APIMetricsResponse is the /api/v1/metrics and /api/v1/metrics/{name} response body.
+type APIMetricsResponse struct {
+	Deployments []APIDeploymentMetrics `json:"deployments"`
+}
+
+// APIDeploymentMetrics are the current metrics of a deployment.
+type APIDeploymentMetrics struct {
+	Deployment string      `json:"deployment"`
+	ChainID    string      `json:"chain_id"`
+	Metrics    []APIMetric `json:"metrics"`
+}
+
+// APIMetric is the current value of a metric. Value is set for the single value metrics, Values for the metric
+// vectors and Histogram for the histograms. The values are decimal strings: Value and Values keep the exact decimals
+// set by the monitor (see monitors.DecimalMetricValue and monitors.MetricVector.SetDecimal), the histograms values
+// are formatted from float64.
+type APIMetric struct {
+	Name    string `json:"name"`
+	Help    string `json:"help"`
+	Unit    string `json:"unit,omitempty"`
+	Kind    string `json:"kind"`
+	Monitor string `json:"monitor"`
+	// UpdatedAt is the time of the last run of the monitor
+	UpdatedAt time.Time         `json:"updated_at"`
+	Value     string            `json:"value,omitempty"`
+	Values    []APILabeledValue `json:"values,omitempty"`
+	Histogram *APIHistogram     `json:"histogram,omitempty"`
+}
+
+// APILabeledValue is a value of a metric vector.
+type APILabeledValue struct {
+	Labels monitors.Labels `json:"labels"`
+	Value  string          `json:"value"`
+}
+
+// APIHistogram is the value of a histogram metric, Buckets are the cumulative counts keyed by the upper bounds.
+type APIHistogram struct {
+	Count   uint64            `json:"count"`
+	Sum     string            `json:"sum"`
+	Buckets map[string]uint64 `json:"buckets"`
+}
+
+// APIValidatorResponse is the /api/v1/validators/{valoper} response body.
+type APIValidatorResponse struct {
+	ValidatorAddress string                   `json:"validator_address"`
+	Deployments      []APIValidatorDeployment `json:"deployments"`
+}
+
+// APIValidatorDeployment are the current metrics of a validator in a deployment, the metric vectors values
+// are limited to the validator ones.
+type APIValidatorDeployment struct {
+	Deployment string      `json:"deployment"`
+	ChainID    string      `json:"chain_id"`
+	Moniker    string      `json:"moniker,omitempty"`
+	Metrics    []APIMetric `json:"metrics"`
+}
+
+// APIAlertsResponse is the /api/v1/alerts response body.
+type APIAlertsResponse struct {
+	Alerts []APIAlert `json:"alerts"`
+}
+
+// APIAlert is an alert of the built-in alerting. The times are omitted until the alert reaches the state.
+type APIAlert struct {
+	Rule       string            `json:"rule"`
+	Severity   string            `json:"severity"`
+	Summary    string            `json:"summary,omitempty"`
+	State      string            `json:"state"`
+	Labels     map[string]string `json:"labels"`
+	Value      string            `json:"value"`
+	ActiveAt   time.Time         `json:"active_at"`
+	FiredAt    *time.Time        `json:"fired_at,omitempty"`
+	ResolvedAt *time.Time        `json:"resolved_at,omitempty"`
+}
+
+// APIError is the response body of the failed API requests.
+type APIError struct {
+	Error string `json:"error"`
+}
+
+// API is the read-only JSON API over the current snapshots of the collectors. All the endpoints accept
+// the optional deployment query parameter limiting the response to a single deployment.
+type API struct {
+	group  *collector.Group
+	alerts *alerting.Engine
+	logger *logrus.Logger
+}
+
+// NewAPIHandler creates the router serving the API under /api/v1.
+func NewAPIHandler(g *collector.Group, alerts *alerting.Engine, logger *logrus.Logger) http.Handler {
+	api := API{group: g, alerts: alerts, logger: logger}
+	router := mux.NewRouter()
+	v1 := router.PathPrefix("/api/v1").Methods(http.MethodGet).Subrouter()
+	v1.HandleFunc("/metrics", api.metrics)
+	v1.HandleFunc("/metrics/{name}", api.metric)
+	v1.HandleFunc("/validators/{valoper}", api.validator)
+	v1.HandleFunc("/alerts", api.alertsList)
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, APIError{Error: "not found"}, logger)
+	})
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusMethodNotAllowed, APIError{Error: "method not allowed"}, logger)
+	})
+	return router
+}
+
+func (a API) metrics(w http.ResponseWriter, r *http.Request) {
+	response := APIMetricsResponse{Deployments: []APIDeploymentMetrics{}}
+	for _, c := range a.collectors(r) {
+		response.Deployments = append(response.Deployments, APIDeploymentMetrics{
+			Deployment: c.Deployment(),
+			ChainID:    c.ChainID(),
+			Metrics:    snapshotMetrics(c, nil, nil),
+		})
+	}
+	writeJSON(w, http.StatusOK, response, a.logger)
+}
+
+func (a API) metric(w http.ResponseWriter, r *http.Request) {
+	name := monitors.MetricName(mux.Vars(r)["name"])
+	matchName := func(desc monitors.MetricDesc) bool {
+		return desc.Name == name || desc.DeprecatedAlias == name
+	}
+
+	response := APIMetricsResponse{Deployments: []APIDeploymentMetrics{}}
+	for _, c := range a.collectors(r) {
+		metrics := snapshotMetrics(c, matchName, nil)
+		if len(metrics) == 0 {
+			continue
+		}
+		response.Deployments = append(response.Deployments, APIDeploymentMetrics{
+			Deployment: c.Deployment(),
+			ChainID:    c.ChainID(),
+			Metrics:    metrics,
+		})
+	}
+	if len(response.Deployments) == 0 {
+		writeJSON(w, http.StatusNotFound, APIError{Error: fmt.Sprintf("metric %s not found", name)}, a.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, response, a.logger)
+}
+
+func (a API) validator(w http.ResponseWriter, r *http.Request) {
+	valoper := mux.Vars(r)["valoper"]
+	isVector := func(desc monitors.MetricDesc) bool {
+		return desc.IsVector()
+	}
+	matchValidator := func(labels monitors.Labels) bool {
+		return labels[monitors.ValidatorAddressLabel] == valoper
+	}
+
+	response := APIValidatorResponse{ValidatorAddress: valoper, Deployments: []APIValidatorDeployment{}}
+	for _, c := range a.collectors(r) {
+		metrics := snapshotMetrics(c, isVector, matchValidator)
+		if len(metrics) == 0 {
+			continue
+		}
+		deployment := APIValidatorDeployment{
+			Deployment: c.Deployment(),
+			ChainID:    c.ChainID(),
+			Metrics:    metrics,
+		}
+		for _, metric := range metrics {
+			if moniker := metric.Values[0].Labels[monitors.MonikerLabel]; moniker != "" {
+				deployment.Moniker = moniker
+				break
+			}
+		}
+		response.Deployments = append(response.Deployments, deployment)
+	}
+	if len(response.Deployments) == 0 {
+		writeJSON(w, http.StatusNotFound, APIError{Error: fmt.Sprintf("validator %s not found", valoper)}, a.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, response, a.logger)
+}
+
+// alertsList responds with the alerts limited by the optional state query parameter.
+func (a API) alertsList(w http.ResponseWriter, r *http.Request) {
+	deployment, state := r.URL.Query().Get("deployment"), alerting.State(r.URL.Query().Get("state"))
+	switch state {
+	case "", alerting.StatePending, alerting.StateFiring, alerting.StateResolved:
+	default:
+		writeJSON(w, http.StatusBadRequest, APIError{Error: fmt.Sprintf("unknown state %s", state)}, a.logger)
+		return
+	}
+
+	response := APIAlertsResponse{Alerts: []APIAlert{}}
+	for _, alert := range a.alerts.Alerts() {
+		if deployment != "" && alert.Labels[collector.DeploymentLabel] != deployment {
+			continue
+		}
+		if state != "" && alert.State != state {
+			continue
+		}
+		response.Alerts = append(response.Alerts, newAPIAlert(alert))
+	}
+	writeJSON(w, http.StatusOK, response, a.logger)
+}
+
+func newAPIAlert(alert alerting.Alert) APIAlert {
+	a := APIAlert{
+		Rule:     alert.Rule,
+		Severity: alert.Severity,
+		Summary:  alert.Summary,
+		State:    string(alert.State),
+		Labels:   alert.Labels,
+		Value:    monitors.FormatFloat(alert.Value),
+		ActiveAt: alert.ActiveAt.UTC(),
+	}
+	if !alert.FiredAt.IsZero() {
+		firedAt := alert.FiredAt.UTC()
+		a.FiredAt = &firedAt
+	}
+	if !alert.ResolvedAt.IsZero() {
+		resolvedAt := alert.ResolvedAt.UTC()
+		a.ResolvedAt = &resolvedAt
+	}
+	return a
+}
+
+// collectors returns the collectors of the group limited by the deployment query parameter.
+func (a API) collectors(r *http.Request) []*collector.Collector {
+	deployment := r.URL.Query().Get("deployment")
+	if deployment == "" {
+		return a.group.Collectors()
+	}
+	var collectors []*collector.Collector
+	for _, c := range a.group.Collectors() {
+		if c.Deployment() == deployment {
+			collectors = append(collectors, c)
+		}
+	}
+	return collectors
+}
+
+// snapshotMetrics returns the metrics of the collector snapshot matching the descs filter sorted by name, the nil
+// filter matches all the metrics. The metric vectors values are limited by the labels filter, the vectors without
+// the matching values are omitted. The metrics not provided by their monitors yet are omitted as well.
+func snapshotMetrics(
+	c *collector.Collector,
+	matchDesc func(monitors.MetricDesc) bool,
+	matchLabels func(monitors.Labels) bool,
+) []APIMetric {
+	snapshot := c.Snapshot()
+	metrics := []APIMetric{}
+	for _, desc := range c.MetricDescs() {
+		if matchDesc != nil && !matchDesc(desc) {
+			continue
+		}
+		monitor, _ := c.MetricMonitor(desc.Name)
+		metric := APIMetric{
+			Name:      string(desc.Name),
+			Help:      desc.Help,
+			Unit:      desc.Unit,
+			Kind:      desc.Kind.String(),
+			Monitor:   monitor,
+			UpdatedAt: snapshot.UpdatedAt[monitor].UTC(),
+		}
+
+		if value, found := snapshot.Decimals[desc.Name]; found {
+			metric.Value = value
+		} else if vector, found := snapshot.Vectors[desc.Name]; found {
+			for _, v := range vector.Values {
+				if matchLabels == nil || matchLabels(v.Labels) {
+					metric.Values = append(metric.Values, APILabeledValue{Labels: v.Labels, Value: v.Decimal})
+				}
+			}
+			if len(metric.Values) == 0 {
+				continue
+			}
+			sortLabeledValues(metric.Values, vector.LabelNames)
+		} else if histogram, found := snapshot.Histograms[desc.Name]; found {
+			metric.Histogram = &APIHistogram{
+				Count:   histogram.Count,
+				Sum:     monitors.FormatFloat(histogram.Sum),
+				Buckets: make(map[string]uint64, len(histogram.Buckets)),
+			}
+			for bound, count := range histogram.Buckets {
+				metric.Histogram.Buckets[monitors.FormatFloat(bound)] = count
+			}
+		} else {
+			continue
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
+// sortLabeledValues sorts the values by the label values in the labelNames order.
+func sortLabeledValues(values []APILabeledValue, labelNames []string) {
+	sort.Slice(values, func(i, j int) bool {
+		for _, name := range labelNames {
+			if values[i].Labels[name] != values[j].Labels[name] {
+				return values[i].Labels[name] < values[j].Labels[name]
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/alerting"
+	"github.com/lidofinance/terra-monitors/internal/app/collector"
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	cosmostypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+// hubStateResponse has the values losing precision once converted to float64.
+const hubStateResponse = `{"height": "1", "result": {
+	"exchange_rate": "1.000123456789012345",
+	"total_bond_amount": "123456789012345678901"
+}}`
+
+const testValoper = "terravaloper1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq"
+
+func newTestAPI(t *testing.T) http.Handler {
+	api, _ := newTestAPIGroup(t)
+	return api
+}
+
+// newTestAPIGroup returns the API handler and the group of the collectors it serves.
+func newTestAPIGroup(t *testing.T) (http.Handler, *collector.Group) {
+	ts := stubs.NewServerWithResponse(hubStateResponse)
+	t.Cleanup(ts.Close)
+
+	cfg := stubs.NewTestCollectorConfig(ts.URL)
+	cfg.BassetContractsVersion = config.V1Contracts
+	cfg.UpdateDataInterval = time.Hour
+	cfg.Deployment = "mainnet"
+	cfg.ChainID = "columbus-5"
+	cfg.EnabledMonitors = []string{"HubState"}
+
+	logger := stubs.NewTestLogger()
+	g, err := collector.NewGroup(context.Background(), cfg, logger)
+	require.NoError(t, err)
+	t.Cleanup(g.Stop)
+	require.Eventually(t, g.Ready, 5*time.Second, time.Millisecond)
+
+	alerts := alerting.NewEngine([]alerting.Rule{{
+		Name:      "ExchangeRateAboveOne",
+		Metric:    "bluna_exchange_rate",
+		Op:        alerting.GreaterThan,
+		Threshold: 1,
+		Severity:  "critical",
+	}}, time.Hour, logger)
+	alerts.Watch(g)
+	return NewAPIHandler(g, alerts, logger), g
+}
+
+func get(t *testing.T, handler http.Handler, target string, body interface{}) int {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), body))
+	return rec.Code
+}
+
+func TestAPIMetrics(t *testing.T) {
+	req := require.New(t)
+	api := newTestAPI(t)
+
+	var resp APIMetricsResponse
+	req.Equal(http.StatusOK, get(t, api, "/api/v1/metrics", &resp))
+	req.Len(resp.Deployments, 1)
+	req.Equal("mainnet", resp.Deployments[0].Deployment)
+	req.Equal("columbus-5", resp.Deployments[0].ChainID)
+	metrics := resp.Deployments[0].Metrics
+	req.Len(metrics, 2)
+	req.Equal("bluna_bonded_amount", metrics[0].Name)
+	req.Equal("123456789012345678901.000000000000000000", metrics[0].Value)
+	req.Equal("uluna", metrics[0].Unit)
+	req.Equal("bluna_exchange_rate", metrics[1].Name)
+	req.Equal("1.000123456789012345", metrics[1].Value)
+	req.Equal("HubState", metrics[1].Monitor)
+	req.Equal("gauge", metrics[1].Kind)
+	req.False(metrics[1].UpdatedAt.IsZero())
+
+	resp = APIMetricsResponse{}
+	req.Equal(http.StatusOK, get(t, api, "/api/v1/metrics?deployment=testnet", &resp))
+	req.Empty(resp.Deployments)
+}
+
+func TestAPIMetric(t *testing.T) {
+	req := require.New(t)
+	api := newTestAPI(t)
+
+	var resp APIMetricsResponse
+	req.Equal(http.StatusOK, get(t, api, "/api/v1/metrics/bluna_exchange_rate", &resp))
+	req.Len(resp.Deployments, 1)
+	req.Len(resp.Deployments[0].Metrics, 1)
+	req.Equal("1.000123456789012345", resp.Deployments[0].Metrics[0].Value)
+
+	var apiErr APIError
+	req.Equal(http.StatusNotFound, get(t, api, "/api/v1/metrics/unknown_metric", &apiErr))
+	req.Equal("metric unknown_metric not found", apiErr.Error)
+}
+
+func TestAPIValidatorNotFound(t *testing.T) {
+	var apiErr APIError
+	code := get(t, newTestAPI(t), "/api/v1/validators/"+testValoper, &apiErr)
+	require.Equal(t, http.StatusNotFound, code)
+	require.Contains(t, apiErr.Error, "not found")
+}
+
+// validatorMonitor provides a metric vector with the value losing precision once converted to float64.
+type validatorMonitor struct {
+	vector *monitors.MetricVector
+}
+
+func (m *validatorMonitor) Name() string {
+	return "ValidatorMonitor"
+}
+
+func (m *validatorMonitor) Handler(context.Context) error {
+	rate := cosmostypes.MustNewDecFromStr("0.123456789012345678")
+	value, err := rate.Float64()
+	if err != nil {
+		return err
+	}
+	m.vector.SetDecimal(monitors.Labels{
+		monitors.ValidatorAddressLabel: testValoper,
+		monitors.MonikerLabel:          "validator",
+	}, rate, value)
+	return nil
+}
+
+func (m *validatorMonitor) GetMetrics() map[monitors.MetricName]monitors.MetricValue {
+	return nil
+}
+
+func (m *validatorMonitor) GetMetricVectors() map[monitors.MetricName]*monitors.MetricVector {
+	return map[monitors.MetricName]*monitors.MetricVector{"test_validator_rate": m.vector}
+}
+
+func (m *validatorMonitor) MetricDescs() []monitors.MetricDesc {
+	return []monitors.MetricDesc{{
+		Name:       "test_validator_rate",
+		Help:       "Test validator rate.",
+		LabelNames: []string{monitors.ValidatorAddressLabel, monitors.MonikerLabel},
+	}}
+}
+
+func TestAPIValidator(t *testing.T) {
+	req := require.New(t)
+	api, g := newTestAPIGroup(t)
+
+	c := g.Collectors()[0]
+	m := &validatorMonitor{vector: monitors.NewMetricVector(monitors.ValidatorAddressLabel, monitors.MonikerLabel)}
+	req.NoError(c.RegisterMonitor(config.CollectorConfig{UpdateDataInterval: time.Hour}, m))
+	req.Eventually(c.Ready, 5*time.Second, time.Millisecond)
+
+	var resp APIValidatorResponse
+	req.Equal(http.StatusOK, get(t, api, "/api/v1/validators/"+testValoper, &resp))
+	req.Len(resp.Deployments, 1)
+	req.Equal("validator", resp.Deployments[0].Moniker)
+	req.Len(resp.Deployments[0].Metrics, 1)
+	values := resp.Deployments[0].Metrics[0].Values
+	req.Len(values, 1)
+	req.Equal("0.123456789012345678", values[0].Value, "the exact decimal must be served instead of the float64 value")
+	req.NotEqual(values[0].Value, monitors.FormatFloat(0.123456789012345678))
+}
+
+func TestAPIAlerts(t *testing.T) {
+	req := require.New(t)
+	api := newTestAPI(t)
+
+	var resp APIAlertsResponse
+	req.Equal(http.StatusOK, get(t, api, "/api/v1/alerts", &resp))
+	req.Len(resp.Alerts, 1)
+	alert := resp.Alerts[0]
+	req.Equal("ExchangeRateAboveOne", alert.Rule)
+	req.Equal("critical", alert.Severity)
+	req.Equal(string(alerting.StateFiring), alert.State)
+	req.Equal(map[string]string{collector.DeploymentLabel: "mainnet", collector.ChainIDLabel: "columbus-5"}, alert.Labels)
+	req.Equal(monitors.FormatFloat(1.000123456789012345), alert.Value)
+	req.NotNil(alert.FiredAt)
+	req.Nil(alert.ResolvedAt)
+
+	resp = APIAlertsResponse{}
+	req.Equal(http.StatusOK, get(t, api, "/api/v1/alerts?state=pending", &resp))
+	req.Empty(resp.Alerts)
+
+	resp = APIAlertsResponse{}
+	req.Equal(http.StatusOK, get(t, api, "/api/v1/alerts?deployment=testnet", &resp))
+	req.Empty(resp.Alerts)
+
+	var apiErr APIError
+	req.Equal(http.StatusBadRequest, get(t, api, "/api/v1/alerts?state=unknown", &apiErr))
+	req.Equal("unknown state unknown", apiErr.Error)
+}
@@ -4,20 +4,23 @@ import (
 	"net/http"
 
 	"github.com/lidofinance/terra-monitors/internal/app/extractor"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type AppHTTP struct {
-	prom extractor.PromExtractor
+	handler http.Handler
 }
 
 func (a AppHTTP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	a.prom.UpdateMetrics()
-	promhttp.Handler().ServeHTTP(w, r)
+	a.handler.ServeHTTP(w, r)
 }
 
-func NewAppHTTP(p extractor.PromExtractor) AppHTTP {
+// NewAppHTTP registers the extractor along with the additional collectors, e.g. the alerting engine.
+func NewAppHTTP(p *extractor.PromExtractor, collectors ...prometheus.Collector) AppHTTP {
+	prometheus.MustRegister(p)
+	prometheus.MustRegister(collectors...)
 	return AppHTTP{
-		prom: p,
+		handler: promhttp.Handler(),
 	}
 }
@@ -3,93 +3,69 @@ package collector
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
 	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
+	"github.com/lidofinance/terra-monitors/internal/pkg/state"
 	"github.com/lidofinance/terra-repositories/delegations"
-	"github.com/lidofinance/terra-repositories/signinfo"
 
 	"github.com/sirupsen/logrus"
 )
 
-func New(cfg config.CollectorConfig, logger *logrus.Logger) (*Collector, error) {
+// New creates a collector with all the monitors registered and running in background.
+// The monitors are stopped once the ctx is cancelled or Stop is called.
+func New(ctx context.Context, cfg config.CollectorConfig, logger *logrus.Logger) (*Collector, error) {
+	store, err := state.Open(cfg.State.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state store: %w", err)
+	}
+	return newGroupCollector(ctx, cfg, logger, source.NewRateLimiter(cfg.RateLimit), store, nil)
+}
+
+// newGroupCollector creates a collector limiting the API requests by the limiter and keeping the monitors state
+// in the store, both may be shared with other collectors. onUpdate is called after every update of the collector
+// snapshot, it's optional.
+func newGroupCollector(
+	ctx context.Context,
+	cfg config.CollectorConfig,
+	logger *logrus.Logger,
+	limiter *source.RateLimiter,
+	store *state.Store,
+	onUpdate func(c *Collector),
+) (*Collector, error) {
+	c := newCollector(ctx, logger, nil)
+	c.limiter, c.store, c.onUpdate = limiter, store, onUpdate
+	if _, err := c.apply(cfg); err != nil {
+		c.Stop()
+		return nil, err
+	}
+	return c, nil
+}
+
+func newCollector(ctx context.Context, logger *logrus.Logger, apiClient *client.TerraRESTApis) *Collector {
+	ctx, cancel := context.WithCancel(ctx)
 	c := &Collector{
 		Metrics:       make(map[monitors.MetricName]monitors.Monitor),
 		MetricVectors: make(map[monitors.MetricName]monitors.Monitor),
+		descs:         make(map[monitors.MetricName]monitors.MetricDesc),
+		metricNames:   make(map[monitors.MetricName]string),
 		logger:        logger,
-		apiClient:     utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
-	}
-	ctx := context.Background()
-
-	hubStateMonitor := monitors.NewHubStateMonitor(cfg, logger)
-	c.RegisterMonitor(ctx, cfg, hubStateMonitor)
-
-	rewardStateMonitor := monitors.NewRewardStateMonitor(cfg, logger)
-	c.RegisterMonitor(ctx, cfg, &rewardStateMonitor)
-
-	blunaTokenInfoMonitor := monitors.NewBlunaTokenInfoMonitor(cfg, logger)
-	c.RegisterMonitor(ctx, cfg, blunaTokenInfoMonitor)
-
-	valRepoCfg := repositories.ValidatorsRepositoryConfig{
-		BAssetContractsVersion:     cfg.BassetContractsVersion,
-		HubContract:                cfg.Addresses.HubContract,
-		ValidatorsRegistryContract: cfg.Addresses.ValidatorsRegistryContract,
+		apiClient:     apiClient,
+		requestStats:  source.NewRequestStats(),
+		instrumented:  make(map[string]*registeredMonitor),
+		ctx:           ctx,
+		cancel:        cancel,
 	}
-	validatorsRepository, err := repositories.NewValidatorsRepository(valRepoCfg, c.apiClient)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialise a validators repository: %v", err)
-	}
-	delegatorsRepository := delegations.New(c.apiClient)
-	signInfoRepository := signinfo.New(c.apiClient)
-
-	slashingMonitor := monitors.NewSlashingMonitor(cfg, logger, validatorsRepository, signInfoRepository)
-	c.RegisterMonitor(ctx, cfg, slashingMonitor)
-
-	updateGlobalIndexMonitor := monitors.NewUpdateGlobalIndexMonitor(cfg, logger)
-	c.RegisterMonitor(ctx, cfg, updateGlobalIndexMonitor)
-
-	hubParameters := monitors.NewHubParametersMonitor(cfg, logger)
-	c.RegisterMonitor(ctx, cfg, &hubParameters)
-
-	delegationsDistributionMonitor := monitors.NewDelegationsDistributionMonitor(cfg, logger, validatorsRepository,
-		delegatorsRepository)
-	c.RegisterMonitor(ctx, cfg, delegationsDistributionMonitor)
-
-	configCRC32Monitor := monitors.NewConfigsCRC32Monitor(cfg, logger)
-	c.RegisterMonitor(ctx, cfg, configCRC32Monitor)
-
-	whitelistedValidatorsMonitor := monitors.NewWhitelistedValidatorsMonitor(cfg, logger, validatorsRepository)
-	c.RegisterMonitor(ctx, cfg, &whitelistedValidatorsMonitor)
-
-	validatorsFeeMonitor := monitors.NewValidatorsFeeMonitor(cfg, logger, validatorsRepository)
-	c.RegisterMonitor(ctx, cfg, validatorsFeeMonitor)
-
-	oracleVotesMonitor := monitors.NewOracleVotesMonitor(cfg, logger, validatorsRepository)
-	c.RegisterMonitor(ctx, cfg, oracleVotesMonitor)
-
-	balanceMonitor := monitors.NewOperatorBotBalanceMonitor(cfg, logger)
-	c.RegisterMonitor(ctx, cfg, balanceMonitor)
-
-	failedRedelegationsMonitor := monitors.NewFailedRedelegationsMonitor(cfg, logger, validatorsRepository, delegatorsRepository)
-	c.RegisterMonitor(ctx, cfg, failedRedelegationsMonitor)
-
-	missedBlocksMonitor := monitors.NewMissedBlocksMonitor(cfg, logger, validatorsRepository)
-	c.RegisterMonitor(ctx, cfg, missedBlocksMonitor)
-
-	slashingParamsMonitor := monitors.NewSlashingParamsMonitor(cfg, logger)
-	c.RegisterMonitor(ctx, cfg, slashingParamsMonitor)
-
-	oracleParamsMonitor := monitors.NewOracleParamsMonitor(cfg, logger)
-	c.RegisterMonitor(ctx, cfg, oracleParamsMonitor)
-
-	stakedLunaMonitor := monitors.NewStakedLunaAmountMonitor(cfg, logger)
-	c.RegisterMonitor(ctx, cfg, stakedLunaMonitor)
-
-	return c, nil
+	c.snapshot.Store(newSnapshot())
+	return c
 }
 
 type Collector struct {
@@ -97,18 +73,87 @@ type Collector struct {
 	MetricVectors map[monitors.MetricName]monitors.Monitor
 	Monitors      []monitors.Monitor
 	logger        *logrus.Logger
+	// apiClient is shared by all the monitors, it's built by source and httpClientCfg
 	apiClient     *client.TerraRESTApis
+	source        config.Source
+	httpClientCfg config.HTTPClientConfig
+	// failover is the transport of apiClient, its endpoints are probed by probeCfg until probeCancel is called
+	failover    *source.FailoverTransport
+	probeCfg    config.SourceProbeConfig
+	probeCancel context.CancelFunc
+	// onUpdate is called after every update of the snapshot
+	onUpdate func(c *Collector)
+	// limiter limits the API requests of apiClient, it's kept when the API client is rebuilt
+	limiter *source.RateLimiter
+	// requestStats counts the API request errors of the monitors, it's kept when the API client is rebuilt
+	requestStats *source.RequestStats
+	// store keeps the monitors state across the restarts, it's nil for the collectors built by the tests
+	store *state.Store
+	// validatorsRepository is shared by all the validators monitors, it's built by validatorsRepositoryCfg
+	validatorsRepository    *repositories.CachedValidatorsRepository
+	validatorsRepositoryCfg repositories.ValidatorsRepositoryConfig
+	// deployment and chainID label the metrics of the collector
+	deployment string
+	chainID    string
+
+	// descs keeps the descriptors of the metrics and metric vectors declared by the monitors
+	descs map[monitors.MetricName]monitors.MetricDesc
+	// metricNames maps the declared metric names and deprecated aliases to the monitor name
+	metricNames map[monitors.MetricName]string
+	// instrumented keeps the registered monitors with their run stats, keyed by the monitor name
+	instrumented map[string]*registeredMonitor
+	// lock guards the registered monitors and metrics, which are changed by Reload
+	lock sync.RWMutex
+	// reloadLock serializes the Reload calls
+	reloadLock sync.Mutex
+	// snapshot keeps *Snapshot, updated after every monitor run
+	snapshot     atomic.Value
+	snapshotLock sync.Mutex
+
+	// ctx is the root context of all the monitors, it is cancelled by Stop
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// registeredMonitor is a monitor run in background by the collector.
+type registeredMonitor struct {
+	*instrumentedMonitor
+	// fingerprint describes the config inputs of the monitor, the monitor is rebuilt on reload once it changes
+	fingerprint string
+	schedule    schedule
+	// cancel stops the monitor runs loop, done is closed once the loop is finished
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
-func (c Collector) GetApiClient() *client.TerraRESTApis {
+func (c *Collector) GetApiClient() *client.TerraRESTApis {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	return c.apiClient
 }
 
-func (c Collector) GetLogger() *logrus.Logger {
+func (c *Collector) GetLogger() *logrus.Logger {
 	return c.logger
 }
 
-func (c Collector) ProvidedMetrics() []monitors.MetricName {
+// Deployment returns the name of the monitored deployment.
+func (c *Collector) Deployment() string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.deployment
+}
+
+// ChainID returns the chain id of the monitored deployment.
+func (c *Collector) ChainID() string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.chainID
+}
+
+func (c *Collector) ProvidedMetrics() []monitors.MetricName {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	var metrics []monitors.MetricName
 	for m := range c.Metrics {
 		metrics = append(metrics, m)
@@ -116,7 +161,9 @@ func (c Collector) ProvidedMetrics() []monitors.MetricName {
 	return metrics
 }
 
-func (c Collector) ProvidedMetricVectors() []monitors.MetricName {
+func (c *Collector) ProvidedMetricVectors() []monitors.MetricName {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	var metrics []monitors.MetricName
 	for m := range c.MetricVectors {
 		metrics = append(metrics, m)
@@ -124,56 +171,341 @@ func (c Collector) ProvidedMetricVectors() []monitors.MetricName {
 	return metrics
 }
 
-func (c Collector) Get(metric monitors.MetricName) (float64, error) {
-	monitor, found := c.Metrics[metric]
-	if !found {
-		return 0, fmt.Errorf("monitor for metric \"%s\" not found", metric)
+// Snapshot returns the last consistent copy of the monitors data. It's safe for concurrent use and doesn't block
+// the running monitors.
+func (c *Collector) Snapshot() *Snapshot {
+	return c.snapshot.Load().(*Snapshot)
+}
+
+// updateSnapshot replaces the monitor data in the collector snapshot.
+func (c *Collector) updateSnapshot(m monitors.Monitor) {
+	c.snapshotLock.Lock()
+	c.snapshot.Store(c.Snapshot().with(m, time.Now()))
+	c.snapshotLock.Unlock()
+	if c.onUpdate != nil {
+		c.onUpdate(c)
+	}
+}
+
+// removeFromSnapshot deletes the monitor data from the collector snapshot.
+func (c *Collector) removeFromSnapshot(m monitors.Monitor) {
+	c.snapshotLock.Lock()
+	defer c.snapshotLock.Unlock()
+	c.snapshot.Store(c.Snapshot().without(m))
+}
+
+// MetricMonitor returns the name of the monitor providing the metric, the deprecated aliases are resolved as well.
+func (c *Collector) MetricMonitor(name monitors.MetricName) (string, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	monitor, found := c.metricNames[name]
+	return monitor, found
+}
+
+// MetricDescs returns the descriptors of the registered metrics and metric vectors sorted by the metric name.
+func (c *Collector) MetricDescs() []monitors.MetricDesc {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	descs := make([]monitors.MetricDesc, 0, len(c.descs))
+	for _, desc := range c.descs {
+		descs = append(descs, desc)
+	}
+	sort.Slice(descs, func(i, j int) bool {
+		return descs[i].Name < descs[j].Name
+	})
+	return descs
+}
+
+// RegisterMonitor validates the metrics declared by the monitor and starts updating its data in background,
+// the first update is started immediately and doesn't block the registration.
+func (c *Collector) RegisterMonitor(cfg config.CollectorConfig, m monitors.Monitor) error {
+	return c.registerMonitor(cfg, m, "")
+}
+
+func (c *Collector) registerMonitor(cfg config.CollectorConfig, m monitors.Monitor, fingerprint string) error {
+	c.lock.Lock()
+	if err := c.validateMetricDescs(m); err != nil {
+		c.lock.Unlock()
+		return fmt.Errorf("failed to register monitor %s: %w", m.Name(), err)
 	}
-	return monitor.GetMetrics()[metric].Get(), nil
+	rm := c.addMonitor(cfg, m, fingerprint)
+	c.lock.Unlock()
+
+	c.startMonitor(rm, true)
+	return nil
+}
+
+// addMonitor adds the validated monitor and its metrics to the collector, it's called with the lock held.
+// The monitor is not started.
+func (c *Collector) addMonitor(cfg config.CollectorConfig, m monitors.Monitor, fingerprint string) *registeredMonitor {
+	rm := &registeredMonitor{
+		instrumentedMonitor: &instrumentedMonitor{Monitor: m, onRun: c.updateSnapshot, requestStats: c.requestStats},
+		fingerprint:         fingerprint,
+		schedule:            newSchedule(cfg, m.Name()),
+	}
+	for _, desc := range m.MetricDescs() {
+		if desc.IsVector() {
+			c.MetricVectors[desc.Name] = m
+		} else {
+			c.Metrics[desc.Name] = m
+		}
+		c.descs[desc.Name] = desc
+	}
+	addMetricNames(c.metricNames, m)
+	c.Monitors = append(c.Monitors, m)
+	c.instrumented[m.Name()] = rm
+	return rm
+}
+
+// startMonitor runs the monitor in background until the collector is stopped or the monitor runs loop is cancelled.
+// The first run is started immediately if runNow is set, otherwise it's scheduled after the monitor interval.
+func (c *Collector) startMonitor(rm *registeredMonitor, runNow bool) {
+	ctx, cancel := context.WithCancel(c.ctx)
+	rm.cancel, rm.done = cancel, make(chan struct{})
+
+	c.wg.Add(1)
+	go func(done chan struct{}) {
+		defer c.wg.Done()
+		defer close(done)
+		var failures int
+		if runNow {
+			// first initial data fetching
+			if err := rm.Handler(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				failures++
+				c.logger.Errorf("failed to update %s data: %+v\n", rm.Name(), err)
+			}
+		}
+		runMonitor(ctx, rm, rm.schedule, failures, c.logger)
+	}(rm.done)
+}
+
+// Ready reports whether every registered monitor has completed its first run, successfully or not.
+// The collector is not ready until the monitors added or rebuilt by Reload complete their first runs as well.
+func (c *Collector) Ready() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	for _, rm := range c.instrumented {
+		if rm.Stats().LastRun.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// stopMonitor cancels the monitor runs loop and waits for it.
+func (c *Collector) stopMonitor(rm *registeredMonitor) {
+	rm.cancel()
+	<-rm.done
 }
 
-func (c Collector) GetVector(metric monitors.MetricName) (*monitors.MetricVector, error) {
-	monitor, found := c.MetricVectors[metric]
+// unregisterMonitor stops the monitor and removes its metrics from the collector. The metrics values are kept
+// in the snapshot if keepValues is set, so they are exported until a monitor providing the same metrics replaces them.
+func (c *Collector) unregisterMonitor(name string, keepValues bool) {
+	c.lock.Lock()
+	rm, found := c.instrumented[name]
 	if !found {
-		return nil, fmt.Errorf("monitor for metric vector \"%s\" not found", metric)
+		c.lock.Unlock()
+		return
+	}
+	delete(c.instrumented, name)
+	for _, desc := range rm.MetricDescs() {
+		delete(c.Metrics, desc.Name)
+		delete(c.MetricVectors, desc.Name)
+		delete(c.descs, desc.Name)
+		delete(c.metricNames, desc.Name)
+		delete(c.metricNames, desc.DeprecatedAlias)
 	}
-	return monitor.GetMetricVectors()[metric], nil
+	for i, m := range c.Monitors {
+		if m.Name() == name {
+			c.Monitors = append(c.Monitors[:i:i], c.Monitors[i+1:]...)
+			break
+		}
+	}
+	c.lock.Unlock()
+
+	// the monitor must be stopped before the snapshot update, otherwise its last run could restore the data
+	c.stopMonitor(rm)
+	if !keepValues {
+		c.removeFromSnapshot(rm.Monitor)
+	}
+}
+
+// MonitorsStats returns the run stats of the registered monitors, keyed by the monitor name.
+func (c *Collector) MonitorsStats() map[string]MonitorStats {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	stats := make(map[string]MonitorStats, len(c.instrumented))
+	for name, m := range c.instrumented {
+		stats[name] = m.Stats()
+	}
+	return stats
+}
+
+// MonitorStatus describes a registered monitor, its metrics and schedule.
+type MonitorStatus struct {
+	Name string
+	// Metrics are the names of the metrics and metric vectors declared by the monitor
+	Metrics  []monitors.MetricName
+	Interval time.Duration
+	Stats    MonitorStats
 }
 
-func findMaps(key monitors.MetricName, maps ...map[monitors.MetricName]monitors.Monitor) (monitors.Monitor, bool) {
-	for _, m := range maps {
-		if wantedMonitor, found := m[key]; found {
-			return wantedMonitor, true
+// MonitorsStatus returns the status of the registered monitors sorted by the monitor name.
+func (c *Collector) MonitorsStatus() []MonitorStatus {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	statuses := make([]MonitorStatus, 0, len(c.instrumented))
+	for name, rm := range c.instrumented {
+		status := MonitorStatus{Name: name, Interval: rm.schedule.interval, Stats: rm.Stats()}
+		for _, desc := range rm.MetricDescs() {
+			status.Metrics = append(status.Metrics, desc.Name)
 		}
+		sort.Slice(status.Metrics, func(i, j int) bool {
+			return status.Metrics[i] < status.Metrics[j]
+		})
+		statuses = append(statuses, status)
 	}
-	return nil, false
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
 }
 
-func (c *Collector) RegisterMonitor(ctx context.Context, cfg config.CollectorConfig, m monitors.Monitor) {
-	for metric := range m.GetMetrics() {
-		if wantedMonitor, found := findMaps(metric, c.Metrics, c.MetricVectors); found {
-			panic(fmt.Sprintf("register monitor %s failed. metrics collision. Monitor %s has declared metric %s", m.Name(), wantedMonitor.Name(), metric))
+// Stop cancels the monitors context and waits for the running Handler calls to return.
+func (c *Collector) Stop() {
+	c.cancel()
+	c.wg.Wait()
+}
+
+// sharedAPIClient returns the API client shared by the monitors with its failover transport. The current client
+// is reused unless the source or the HTTP client config is changed.
+func (c *Collector) sharedAPIClient(cfg config.CollectorConfig) (*client.TerraRESTApis, *source.FailoverTransport) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if c.apiClient != nil && reflect.DeepEqual(c.source, cfg.Source) && c.httpClientCfg == cfg.HTTPClient {
+		return c.apiClient, c.failover
+	}
+	return source.NewClient(cfg.Source, cfg.HTTPClient, c.limiter, c.logger)
+}
+
+// restartProbe probes the endpoints of the failover transport in background by the config, the previous probe
+// is stopped. The probe is stopped once the collector is stopped.
+func (c *Collector) restartProbe(cfg config.SourceProbeConfig, failover *source.FailoverTransport) {
+	if c.probeCancel != nil {
+		c.probeCancel()
+		c.probeCancel = nil
+	}
+	if cfg.Interval <= 0 || failover == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.probeCancel = cancel
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		failover.Probe(ctx, cfg.Interval, cfg.Timeout)
+	}()
+}
+
+// EndpointsStats returns the stats of the source endpoints sorted by priority and the number of failovers
+// since the API client was built.
+func (c *Collector) EndpointsStats() ([]source.EndpointStats, uint64) {
+	c.lock.RLock()
+	failover := c.failover
+	c.lock.RUnlock()
+	if failover == nil {
+		return nil, 0
+	}
+	return failover.EndpointsStats(), failover.Failovers()
+}
+
+// SourceReachable reports whether any source endpoint is not known to be down, i.e. its circuit breaker
+// is not open and its last probe has not failed.
+func (c *Collector) SourceReachable() bool {
+	stats, _ := c.EndpointsStats()
+	if stats == nil {
+		return false
+	}
+	for _, s := range stats {
+		if s.Breaker != source.BreakerOpen && (s.LastProbe.IsZero() || s.ProbeUp()) {
+			return true
 		}
+	}
+	return false
+}
 
-		c.Metrics[metric] = m
+// newMonitorDeps creates the dependencies of the monitors built by the config. The validators repository cache
+// is reused unless the repository config or the API client is changed.
+func (c *Collector) newMonitorDeps(cfg config.CollectorConfig, apiClient *client.TerraRESTApis) (monitorDeps, error) {
+	valRepoCfg := validatorsRepositoryConfig(cfg)
+	c.lock.RLock()
+	validatorsRepository := c.validatorsRepository
+	if apiClient != c.apiClient || valRepoCfg != c.validatorsRepositoryCfg {
+		validatorsRepository = nil
 	}
-	for metric := range m.GetMetricVectors() {
-		if wantedMonitor, found := findMaps(metric, c.Metrics, c.MetricVectors); found {
-			panic(fmt.Sprintf("register monitor %s failed. metrics collision. Monitor %s has declared metric %s", m.Name(), wantedMonitor.Name(), metric))
+	c.lock.RUnlock()
+
+	if validatorsRepository == nil {
+		repository, err := repositories.NewValidatorsRepository(valRepoCfg, apiClient)
+		if err != nil {
+			return monitorDeps{}, fmt.Errorf("failed to initialise a validators repository: %v", err)
 		}
+		validatorsRepository = repositories.NewCachedValidatorsRepository(
+			repository,
+			cfg.ValidatorsCache.TTL,
+			cfg.ValidatorsCache.MaxStale,
+			validatorsFetchTimeout(cfg.HTTPClient),
+		)
+	}
+	validatorsRepository.SetTTL(cfg.ValidatorsCache.TTL, cfg.ValidatorsCache.MaxStale)
+
+	deps := monitorDeps{
+		cfg:                   cfg,
+		logger:                c.logger,
+		apiClient:             apiClient,
+		validatorsRepository:  validatorsRepository,
+		delegationsRepository: delegations.New(apiClient),
+		store:                 c.store,
+	}
+	return deps, nil
+}
 
-		c.MetricVectors[metric] = m
+// validatorsFetchTimeout returns the time limit of a validators repository request shared by the monitors:
+// the request timeout of every attempt with the retries and the backoffs between them, 0 means no limit.
+func validatorsFetchTimeout(cfg config.HTTPClientConfig) time.Duration {
+	if cfg.RequestTimeout <= 0 {
+		return 0
 	}
-	c.Monitors = append(c.Monitors, m)
+	return time.Duration(cfg.MaxRetries+1)*cfg.RequestTimeout + time.Duration(cfg.MaxRetries)*cfg.MaxRetryBackoff
+}
 
-	// first initial data fetching
-	err := m.Handler(ctx)
-	if err != nil {
-		c.logger.Errorf("failed to update %s data: %+v\n", m.Name(), err)
+// validatorsRepositoryConfig returns the validators repository config with the address of the validators source
+// only, so the repository is rebuilt by the same address changes as the monitors using it (see validatorsAddresses).
+func validatorsRepositoryConfig(cfg config.CollectorConfig) repositories.ValidatorsRepositoryConfig {
+	repositoryCfg := repositories.ValidatorsRepositoryConfig{BAssetContractsVersion: cfg.BassetContractsVersion}
+	if validatorsSource(cfg) == config.AddressValidatorsRegistryContract {
+		repositoryCfg.ValidatorsRegistryContract = cfg.Addresses.ValidatorsRegistryContract
+	} else {
+		repositoryCfg.HubContract = cfg.Addresses.HubContract
 	}
+	return repositoryCfg
+}
 
-	// running fetching data in background
-	tk := time.NewTicker(cfg.UpdateDataInterval)
+// RequestStats returns the API request errors and retries counters of the monitors.
+func (c *Collector) RequestStats() *source.RequestStats {
+	return c.requestStats
+}
 
-	go monitors.MustRunMonitor(ctx, m, tk, c.logger)
+// ValidatorsCacheStats returns the stats of the validators repository cache shared by the monitors.
+func (c *Collector) ValidatorsCacheStats() repositories.CacheStats {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if c.validatorsRepository == nil {
+		return repositories.CacheStats{}
+	}
+	return c.validatorsRepository.Stats()
 }
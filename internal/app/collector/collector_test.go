@@ -0,0 +1,252 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+type blockingMonitor struct {
+	calls   int32
+	running int32
+}
+
+func (m *blockingMonitor) Name() string {
+	return "BlockingMonitor"
+}
+
+func (m *blockingMonitor) Handler(ctx context.Context) error {
+	atomic.AddInt32(&m.calls, 1)
+	atomic.AddInt32(&m.running, 1)
+	defer atomic.AddInt32(&m.running, -1)
+	// emulates a hung FCD request which is released only by the context cancellation
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (m *blockingMonitor) GetMetrics() map[monitors.MetricName]monitors.MetricValue {
+	return nil
+}
+
+func (m *blockingMonitor) GetMetricVectors() map[monitors.MetricName]*monitors.MetricVector {
+	return nil
+}
+
+func (m *blockingMonitor) MetricDescs() []monitors.MetricDesc {
+	return nil
+}
+
+func TestCollectorStop(t *testing.T) {
+	req := require.New(t)
+
+	logger := stubs.NewTestLogger()
+	c := newCollector(context.Background(), logger, nil)
+	m := &blockingMonitor{}
+
+	// the initial fetching is released by the collector context cancellation
+	req.NoError(c.RegisterMonitor(config.CollectorConfig{UpdateDataInterval: time.Millisecond}, m))
+	req.Eventually(func() bool {
+		return atomic.LoadInt32(&m.running) == 1
+	}, time.Second, time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		c.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		req.FailNow("collector has not been stopped")
+	}
+
+	calls := atomic.LoadInt32(&m.calls)
+	time.Sleep(10 * time.Millisecond)
+	req.Equal(calls, atomic.LoadInt32(&m.calls), "monitor must not be called after Stop")
+	req.Equal(int32(0), atomic.LoadInt32(&m.running))
+}
+
+// waitReady waits for the first runs of the collector monitors.
+func waitReady(t *testing.T, c *Collector) {
+	require.Eventually(t, c.Ready, time.Second, time.Millisecond, "the monitors first runs must be completed")
+}
+
+func TestRegisterMonitorDoesNotBlock(t *testing.T) {
+	req := require.New(t)
+
+	c := newCollector(context.Background(), stubs.NewTestLogger(), nil)
+	defer c.Stop()
+	req.True(c.Ready(), "the collector without monitors is ready")
+
+	release := make(chan struct{})
+	blocked := &releasedMonitor{release: release}
+	start := time.Now()
+	req.NoError(c.RegisterMonitor(config.CollectorConfig{UpdateDataInterval: time.Hour}, blocked))
+	req.NoError(c.RegisterMonitor(config.CollectorConfig{UpdateDataInterval: time.Hour}, &failingMonitor{}))
+	req.Less(int64(time.Since(start)), int64(100*time.Millisecond))
+
+	// the failed first run counts, the blocked one keeps the collector not ready
+	req.Eventually(func() bool {
+		return !c.MonitorsStats()["FailingMonitor"].LastRun.IsZero()
+	}, time.Second, time.Millisecond)
+	req.False(c.Ready())
+
+	close(release)
+	waitReady(t, c)
+}
+
+// releasedMonitor runs until the release channel is closed.
+type releasedMonitor struct {
+	slowMonitor
+	release chan struct{}
+}
+
+func (m *releasedMonitor) Name() string {
+	return "ReleasedMonitor"
+}
+
+func (m *releasedMonitor) Handler(ctx context.Context) error {
+	select {
+	case <-m.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestMonitorsStats(t *testing.T) {
+	req := require.New(t)
+
+	c := newCollector(context.Background(), stubs.NewTestLogger(), nil)
+	defer c.Stop()
+
+	healthy, failing := &slowMonitor{}, &failingMonitor{}
+	req.NoError(c.RegisterMonitor(config.CollectorConfig{UpdateDataInterval: time.Hour}, healthy))
+	req.NoError(c.RegisterMonitor(config.CollectorConfig{UpdateDataInterval: time.Hour}, failing))
+	waitReady(t, c)
+
+	stats := c.MonitorsStats()
+	req.Len(stats, 2)
+
+	healthyStats := stats[healthy.Name()]
+	req.True(healthyStats.Up())
+	req.Equal(uint64(1), healthyStats.Successes)
+	req.Equal(uint64(0), healthyStats.Failures)
+	req.False(healthyStats.LastSuccess.IsZero())
+	req.GreaterOrEqual(healthyStats.LastDuration, 5*time.Millisecond)
+
+	failingStats := stats[failing.Name()]
+	req.False(failingStats.Up())
+	req.Equal(uint64(0), failingStats.Successes)
+	req.Equal(uint64(1), failingStats.Failures)
+	req.True(failingStats.LastSuccess.IsZero())
+	req.EqualError(failingStats.LastError, "failed")
+}
+
+type failingMonitor struct {
+	slowMonitor
+}
+
+func (m *failingMonitor) Name() string {
+	return "FailingMonitor"
+}
+
+func (m *failingMonitor) Handler(ctx context.Context) error {
+	return errors.New("failed")
+}
+
+type valueMonitor struct {
+	metrics       map[monitors.MetricName]monitors.MetricValue
+	metricVectors map[monitors.MetricName]*monitors.MetricVector
+}
+
+func newValueMonitor() *valueMonitor {
+	return &valueMonitor{
+		metrics: map[monitors.MetricName]monitors.MetricValue{
+			"test_metric": &monitors.SimpleMetricValue{},
+		},
+		metricVectors: map[monitors.MetricName]*monitors.MetricVector{
+			"test_vector": monitors.NewMetricVector(monitors.MonikerLabel),
+		},
+	}
+}
+
+func (m *valueMonitor) Name() string {
+	return "ValueMonitor"
+}
+
+func (m *valueMonitor) Handler(ctx context.Context) error {
+	m.metrics["test_metric"].Add(1)
+	m.metricVectors["test_vector"].Add(monitors.Labels{monitors.MonikerLabel: "validator"}, 2)
+	return nil
+}
+
+func (m *valueMonitor) GetMetrics() map[monitors.MetricName]monitors.MetricValue {
+	return m.metrics
+}
+
+func (m *valueMonitor) GetMetricVectors() map[monitors.MetricName]*monitors.MetricVector {
+	return m.metricVectors
+}
+
+func (m *valueMonitor) MetricDescs() []monitors.MetricDesc {
+	return []monitors.MetricDesc{
+		{Name: "test_metric", Help: "Test metric."},
+		{Name: "test_vector", Help: "Test vector.", LabelNames: []string{monitors.MonikerLabel}},
+	}
+}
+
+func TestCollectorSnapshot(t *testing.T) {
+	req := require.New(t)
+
+	c := newCollector(context.Background(), stubs.NewTestLogger(), nil)
+	defer c.Stop()
+
+	empty := c.Snapshot()
+	req.Empty(empty.Metrics)
+
+	m := newValueMonitor()
+	req.NoError(c.RegisterMonitor(config.CollectorConfig{UpdateDataInterval: time.Hour}, m))
+	waitReady(t, c)
+
+	snapshot := c.Snapshot()
+	req.Equal(1.0, snapshot.Metrics["test_metric"])
+	req.Equal([]string{monitors.MonikerLabel}, snapshot.Vectors["test_vector"].LabelNames)
+	req.Equal([]LabeledValue{{Labels: monitors.Labels{monitors.MonikerLabel: "validator"}, Value: 2, Decimal: "2"}}, snapshot.Vectors["test_vector"].Values)
+	req.False(snapshot.UpdatedAt[m.Name()].IsZero())
+
+	// the previously taken snapshots are immutable
+	req.NoError(c.instrumented[m.Name()].Handler(context.Background()))
+	req.Equal(1.0, snapshot.Metrics["test_metric"])
+	req.Equal(2.0, c.Snapshot().Metrics["test_metric"])
+	req.Empty(empty.Metrics)
+}
+
+func TestMonitorsStatus(t *testing.T) {
+	req := require.New(t)
+
+	c := newCollector(context.Background(), stubs.NewTestLogger(), nil)
+	defer c.Stop()
+	req.NoError(c.RegisterMonitor(config.CollectorConfig{UpdateDataInterval: time.Hour}, newValueMonitor()))
+	req.NoError(c.RegisterMonitor(config.CollectorConfig{UpdateDataInterval: time.Minute}, &failingMonitor{}))
+	waitReady(t, c)
+
+	statuses := c.MonitorsStatus()
+	req.Len(statuses, 2)
+	req.Equal("FailingMonitor", statuses[0].Name)
+	req.Equal(time.Minute, statuses[0].Interval)
+	req.EqualError(statuses[0].Stats.LastError, "failed")
+	req.Equal("ValueMonitor", statuses[1].Name)
+	req.Equal([]monitors.MetricName{"test_metric", "test_vector"}, statuses[1].Metrics)
+	req.True(statuses[1].Stats.Up())
+	req.False(c.SourceReachable(), "the collector without an API client has no reachable source")
+}
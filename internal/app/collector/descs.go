@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+)
+
+var (
+	metricNameRe = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRe  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// validateMetricDescs checks the metric descriptors declared by the monitor against the metrics provided by it
+// and the metrics already registered in the collector.
+func (c *Collector) validateMetricDescs(m monitors.Monitor) error {
+	return checkMetricDescs(m, c.metricNames)
+}
+
+// checkMetricDescs checks the metric descriptors declared by the monitor against the metrics provided by it
+// and the metric names registered by other monitors, which map the names to the monitor name.
+func checkMetricDescs(m monitors.Monitor, metricNames map[monitors.MetricName]string) error {
+	metrics := m.GetMetrics()
+	vectors := m.GetMetricVectors()
+	declared := make(map[monitors.MetricName]bool)
+	for _, desc := range m.MetricDescs() {
+		if err := validateMetricDesc(desc); err != nil {
+			return fmt.Errorf("invalid metric \"%s\" descriptor: %w", desc.Name, err)
+		}
+
+		names := []monitors.MetricName{desc.Name}
+		if desc.DeprecatedAlias != "" {
+			names = append(names, desc.DeprecatedAlias)
+		}
+		for _, name := range names {
+			if declared[name] {
+				return fmt.Errorf("metric \"%s\" is declared twice", name)
+			}
+			if owner, found := metricNames[name]; found {
+				return fmt.Errorf("metrics collision, monitor %s has declared metric \"%s\"", owner, name)
+			}
+			declared[name] = true
+		}
+
+		if desc.IsVector() {
+			vector, found := vectors[desc.Name]
+			if !found {
+				return fmt.Errorf("metric vector \"%s\" is declared but not provided", desc.Name)
+			}
+			if vector.Kind() != desc.Kind {
+				return fmt.Errorf("metric vector \"%s\" is declared as %s, but provided as %s", desc.Name, desc.Kind, vector.Kind())
+			}
+			if strings.Join(vector.LabelNames(), ",") != strings.Join(desc.LabelNames, ",") {
+				return fmt.Errorf("metric vector \"%s\" is declared with labels %v, but provided with labels %v",
+					desc.Name, desc.LabelNames, vector.LabelNames())
+			}
+		} else {
+			value, found := metrics[desc.Name]
+			if !found {
+				return fmt.Errorf("metric \"%s\" is declared but not provided", desc.Name)
+			}
+			if value.Kind() != desc.Kind {
+				return fmt.Errorf("metric \"%s\" is declared as %s, but provided as %s", desc.Name, desc.Kind, value.Kind())
+			}
+		}
+	}
+
+	for name := range metrics {
+		if !declared[name] {
+			return fmt.Errorf("metric \"%s\" is provided but not declared", name)
+		}
+	}
+	for name := range vectors {
+		if !declared[name] {
+			return fmt.Errorf("metric vector \"%s\" is provided but not declared", name)
+		}
+	}
+	return nil
+}
+
+// addMetricNames adds the names and deprecated aliases of the metrics declared by the monitor to metricNames.
+func addMetricNames(metricNames map[monitors.MetricName]string, m monitors.Monitor) {
+	for _, desc := range m.MetricDescs() {
+		metricNames[desc.Name] = m.Name()
+		if desc.DeprecatedAlias != "" {
+			metricNames[desc.DeprecatedAlias] = m.Name()
+		}
+	}
+}
+
+func validateMetricDesc(desc monitors.MetricDesc) error {
+	if !metricNameRe.MatchString(string(desc.Name)) {
+		return errors.New("invalid metric name")
+	}
+	if desc.DeprecatedAlias != "" && !metricNameRe.MatchString(string(desc.DeprecatedAlias)) {
+		return fmt.Errorf("invalid deprecated alias \"%s\"", desc.DeprecatedAlias)
+	}
+	if desc.Help == "" {
+		return errors.New("help is empty")
+	}
+
+	switch desc.Kind {
+	case monitors.GaugeKind:
+	case monitors.CounterKind:
+		if !strings.HasSuffix(string(desc.Name), "_total") {
+			return errors.New("counter name must have \"_total\" suffix")
+		}
+	case monitors.HistogramKind:
+		if desc.IsVector() {
+			return errors.New("histogram vectors are not supported")
+		}
+	default:
+		return fmt.Errorf("unknown metric kind %s", desc.Kind)
+	}
+
+	seen := make(map[string]bool)
+	for _, label := range desc.LabelNames {
+		if !labelNameRe.MatchString(label) || strings.HasPrefix(label, "__") {
+			return fmt.Errorf("invalid label name \"%s\"", label)
+		}
+		if label == DeploymentLabel || label == ChainIDLabel {
+			return fmt.Errorf("label name \"%s\" is reserved", label)
+		}
+		if seen[label] {
+			return fmt.Errorf("duplicated label name \"%s\"", label)
+		}
+		seen[label] = true
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+type descsMonitor struct {
+	valueMonitor
+	descs []monitors.MetricDesc
+}
+
+func (m *descsMonitor) MetricDescs() []monitors.MetricDesc {
+	return m.descs
+}
+
+func TestValidateMetricDescs(t *testing.T) {
+	validVector := monitors.MetricDesc{Name: "test_vector", Help: "Test vector.", LabelNames: []string{monitors.MonikerLabel}}
+
+	tests := []struct {
+		name  string
+		descs []monitors.MetricDesc
+		err   string
+	}{
+		{
+			name: "valid",
+			descs: []monitors.MetricDesc{
+				{Name: "test_metric", Help: "Test metric.", Unit: "uluna", DeprecatedAlias: "old_test_metric"},
+				validVector,
+			},
+		},
+		{
+			name:  "not declared metric",
+			descs: []monitors.MetricDesc{validVector},
+			err:   "metric \"test_metric\" is provided but not declared",
+		},
+		{
+			name: "not provided metric",
+			descs: []monitors.MetricDesc{
+				{Name: "test_metric", Help: "Test metric."},
+				{Name: "unknown_metric", Help: "Unknown metric."},
+				validVector,
+			},
+			err: "metric \"unknown_metric\" is declared but not provided",
+		},
+		{
+			name: "reserved label",
+			descs: []monitors.MetricDesc{
+				{Name: "test_metric", Help: "Test metric."},
+				{Name: "test_vector", Help: "Test vector.", LabelNames: []string{DeploymentLabel}},
+			},
+			err: "invalid metric \"test_vector\" descriptor: label name \"deployment\" is reserved",
+		},
+		{
+			name:  "empty help",
+			descs: []monitors.MetricDesc{{Name: "test_metric"}, validVector},
+			err:   "invalid metric \"test_metric\" descriptor: help is empty",
+		},
+		{
+			name:  "invalid name",
+			descs: []monitors.MetricDesc{{Name: "test-metric", Help: "Test metric."}, validVector},
+			err:   "invalid metric \"test-metric\" descriptor: invalid metric name",
+		},
+		{
+			name:  "kind mismatch",
+			descs: []monitors.MetricDesc{{Name: "test_metric", Help: "Test metric.", Kind: monitors.HistogramKind}, validVector},
+			err:   "metric \"test_metric\" is declared as histogram, but provided as gauge",
+		},
+		{
+			name:  "counter without suffix",
+			descs: []monitors.MetricDesc{{Name: "test_metric", Help: "Test metric.", Kind: monitors.CounterKind}, validVector},
+			err:   "invalid metric \"test_metric\" descriptor: counter name must have \"_total\" suffix",
+		},
+		{
+			name: "labels mismatch",
+			descs: []monitors.MetricDesc{
+				{Name: "test_metric", Help: "Test metric."},
+				{Name: "test_vector", Help: "Test vector.", LabelNames: []string{monitors.ValidatorAddressLabel}},
+			},
+			err: "metric vector \"test_vector\" is declared with labels [validator_address], but provided with labels [moniker]",
+		},
+		{
+			name: "alias collision",
+			descs: []monitors.MetricDesc{
+				{Name: "test_metric", Help: "Test metric.", DeprecatedAlias: "test_vector"},
+				validVector,
+			},
+			err: "metric \"test_vector\" is declared twice",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCollector(context.Background(), stubs.NewTestLogger(), nil)
+			m := &descsMonitor{valueMonitor: *newValueMonitor(), descs: tt.descs}
+			err := c.validateMetricDescs(m)
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRegisterMonitorCollision(t *testing.T) {
+	req := require.New(t)
+
+	c := newCollector(context.Background(), stubs.NewTestLogger(), nil)
+	defer c.Stop()
+
+	cfg := config.CollectorConfig{UpdateDataInterval: time.Hour}
+	req.NoError(c.RegisterMonitor(cfg, newValueMonitor()))
+	err := c.RegisterMonitor(cfg, newValueMonitor())
+	req.EqualError(err, "failed to register monitor ValueMonitor: metrics collision, monitor ValueMonitor has declared metric \"test_metric\"")
+}
@@ -0,0 +1,241 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
+	"github.com/lidofinance/terra-monitors/internal/pkg/state"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DeploymentLabel and ChainIDLabel are added to every exported series, so the monitors can't use them.
+	DeploymentLabel = "deployment"
+	ChainIDLabel    = "chain_id"
+)
+
+// ConfigLoader loads the actual config for Group.Reload.
+type ConfigLoader func() (config.CollectorConfig, error)
+
+// ReloadStats describes the history of the group config reloads.
+type ReloadStats struct {
+	LastReload  time.Time
+	LastSuccess time.Time
+	LastError   error
+	Successes   uint64
+	Failures    uint64
+}
+
+// Group is a set of collectors, one per monitored deployment.
+type Group struct {
+	collectors []*Collector
+	logger     *logrus.Logger
+	// ctx is the parent context of the collectors created on reload
+	ctx context.Context
+	// limiter limits the API requests of all the collectors
+	limiter *source.RateLimiter
+	// store keeps the monitors state of all the collectors, the state file is not changed by Reload
+	store *state.Store
+
+	lock        sync.RWMutex
+	reloadLock  sync.Mutex
+	reloadStats ReloadStats
+	// listeners are called after every update of a collector snapshot
+	listeners []func(c *Collector)
+	// removeListeners are called with the deployments removed by Reload
+	removeListeners []func(deployment string)
+}
+
+// NewGroup creates a collector for every deployment of the config. The collectors are stopped once the ctx
+// is cancelled or Stop is called. The API requests of all the collectors are limited by the same rate limiter,
+// the monitors state of all the collectors is kept in the same state file.
+func NewGroup(ctx context.Context, cfg config.CollectorConfig, logger *logrus.Logger) (*Group, error) {
+	store, err := state.Open(cfg.State.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state store: %w", err)
+	}
+	g := &Group{ctx: ctx, logger: logger, limiter: source.NewRateLimiter(cfg.RateLimit), store: store}
+	for _, deploymentCfg := range cfg.DeploymentConfigs() {
+		c, err := newGroupCollector(ctx, deploymentCfg, logger, g.limiter, g.store, g.notifyUpdate)
+		if err != nil {
+			g.Stop()
+			return nil, fmt.Errorf("failed to create collector of deployment %s: %w", deploymentCfg.Deployment, err)
+		}
+		g.collectors = append(g.collectors, c)
+	}
+	return g, nil
+}
+
+// Collectors returns the collectors of the group, the list is changed by Reload.
+func (g *Group) Collectors() []*Collector {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	return append([]*Collector{}, g.collectors...)
+}
+
+// Reload loads the config and applies it to the collectors: the collectors of the removed deployments are stopped,
+// the ones of the new deployments are created and the rest are reloaded with Collector.Reload.
+// The reload outcome is logged and recorded to ReloadStats.
+func (g *Group) Reload(load ConfigLoader) (map[string]ReloadResult, error) {
+	g.reloadLock.Lock()
+	defer g.reloadLock.Unlock()
+
+	results, err := g.reload(load)
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.reloadStats.LastReload = time.Now()
+	g.reloadStats.LastError = err
+	if err != nil {
+		g.reloadStats.Failures++
+		g.logger.Errorf("failed to reload config: %v", err)
+		return results, err
+	}
+	g.reloadStats.LastSuccess = g.reloadStats.LastReload
+	g.reloadStats.Successes++
+	g.logger.Infoln("config reloaded")
+	return results, nil
+}
+
+// reload validates the config of every deployment and builds the collectors of the new deployments first,
+// the group is changed only once all of them succeed, so the failed reload leaves the group as is.
+func (g *Group) reload(load ConfigLoader) (map[string]ReloadResult, error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	current := make(map[string]*Collector)
+	for _, c := range g.Collectors() {
+		current[c.Deployment()] = c
+	}
+
+	var (
+		collectors []*Collector
+		plans      []*reloadPlan
+		added      = make(map[*Collector]bool)
+	)
+	for _, deploymentCfg := range cfg.DeploymentConfigs() {
+		c, found := current[deploymentCfg.Deployment]
+		if found {
+			delete(current, deploymentCfg.Deployment)
+		} else {
+			// the new collector is started by the plan commit
+			c = newCollector(g.ctx, g.logger, nil)
+			c.limiter, c.store, c.onUpdate = g.limiter, g.store, g.notifyUpdate
+			added[c] = true
+		}
+		plan, err := c.prepareReload(deploymentCfg)
+		if err != nil {
+			for _, p := range plans {
+				p.cancel()
+			}
+			for c := range added {
+				c.Stop()
+			}
+			return nil, fmt.Errorf("failed to reload deployment %s: %w", deploymentCfg.Deployment, err)
+		}
+		collectors = append(collectors, c)
+		plans = append(plans, plan)
+	}
+
+	results := make(map[string]ReloadResult)
+	for i, plan := range plans {
+		result := plan.commit()
+		if c := collectors[i]; added[c] {
+			g.logger.Infof("deployment %s added", c.Deployment())
+		} else {
+			results[c.Deployment()] = result
+			g.logger.Infof("deployment %s reloaded: %s", c.Deployment(), result)
+		}
+	}
+
+	g.setCollectors(collectors)
+	for name, c := range current {
+		c.Stop()
+		g.logger.Infof("deployment %s removed", name)
+		g.notifyRemove(name)
+	}
+	return results, nil
+}
+
+func (g *Group) setCollectors(collectors []*Collector) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.collectors = collectors
+}
+
+// ReloadStats returns the history of the config reloads.
+func (g *Group) ReloadStats() ReloadStats {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	return g.reloadStats
+}
+
+// OnUpdate adds the listener called after every update of the snapshot of any collector of the group,
+// i.e. after every monitor run. The listeners are called concurrently by the monitors, they must not block.
+func (g *Group) OnUpdate(listener func(c *Collector)) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.listeners = append(g.listeners, listener)
+}
+
+// OnRemove adds the listener called with the name of every deployment removed by Reload, once its collector
+// is stopped.
+func (g *Group) OnRemove(listener func(deployment string)) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.removeListeners = append(g.removeListeners, listener)
+}
+
+func (g *Group) notifyRemove(deployment string) {
+	g.lock.RLock()
+	listeners := g.removeListeners
+	g.lock.RUnlock()
+	for _, listener := range listeners {
+		listener(deployment)
+	}
+}
+
+func (g *Group) notifyUpdate(c *Collector) {
+	g.lock.RLock()
+	listeners := g.listeners
+	g.lock.RUnlock()
+	for _, listener := range listeners {
+		listener(c)
+	}
+}
+
+// Ready reports whether the monitors of all the collectors have completed their first runs.
+func (g *Group) Ready() bool {
+	for _, c := range g.Collectors() {
+		if !c.Ready() {
+			return false
+		}
+	}
+	return true
+}
+
+// RateLimiterWaitStats returns the time the API requests of the collectors spent waiting in the rate limiter
+// by the endpoint host.
+func (g *Group) RateLimiterWaitStats() map[string]source.WaitStats {
+	return g.limiter.WaitStats()
+}
+
+// Stop stops all the collectors of the group and waits for them.
+func (g *Group) Stop() {
+	var wg sync.WaitGroup
+	for _, c := range g.Collectors() {
+		wg.Add(1)
+		go func(c *Collector) {
+			defer wg.Done()
+			c.Stop()
+		}(c)
+	}
+	wg.Wait()
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/bank"
@@ -25,10 +25,10 @@ type OperatorBotBalanceMonitor struct {
 	balanceUST SimpleMetricValue
 }
 
-func NewOperatorBotBalanceMonitor(cfg config.CollectorConfig, logger *logrus.Logger) *OperatorBotBalanceMonitor {
+func NewOperatorBotBalanceMonitor(cfg config.CollectorConfig, logger *logrus.Logger, apiClient *client.TerraRESTApis) *OperatorBotBalanceMonitor {
 	m := OperatorBotBalanceMonitor{
 		BotAddress: cfg.Addresses.UpdateGlobalIndexBotAddress,
-		apiClient:  utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient:  apiClient,
 		logger:     logger,
 		balanceUST: SimpleMetricValue{},
 	}
@@ -39,6 +39,12 @@ func (m *OperatorBotBalanceMonitor) Name() string {
 	return "OperatorBotBalanceMonitor"
 }
 
+func (m *OperatorBotBalanceMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{Name: OperatorBotBalance, Help: "UST balance of the operator bot account.", Unit: "UST"},
+	}
+}
+
 func (m *OperatorBotBalanceMonitor) GetMetrics() map[MetricName]MetricValue {
 	return map[MetricName]MetricValue{
 		OperatorBotBalance: &m.balanceUST,
@@ -59,7 +65,7 @@ func (m *OperatorBotBalanceMonitor) Handler(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to get \"%s\" account balance: %w", m.BotAddress, err)
 	}
-	err = resp.GetPayload().Validate(nil)
+	err = source.ValidatePayload(ctx, resp.GetPayload())
 	if err != nil {
 		return fmt.Errorf("failed to validate response: %w", err)
 	}
@@ -76,7 +82,7 @@ func (m *OperatorBotBalanceMonitor) Handler(ctx context.Context) error {
 				return fmt.Errorf("failed to parse coins uusd amount: %s: %s", coin.Amount, err)
 			}
 
-			m.balanceUST.Set(float64(amountFloat / 1_000_000))
+			m.balanceUST.SetDecimal(amount.QuoInt64(1_000_000), amountFloat/1_000_000)
 			m.logger.Infof("successfully retrieved \"%s\" account balance info\n", m.BotAddress)
 			return nil
 		}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io/ioutil"
 
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
 	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
 	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 	"github.com/stretchr/testify/suite"
@@ -28,7 +29,8 @@ func (suite *BalanceMonitorTestSuite) TestBalance() {
 	cfg := stubs.NewTestCollectorConfig(ts.URL)
 
 	logger := stubs.NewTestLogger()
-	m := NewOperatorBotBalanceMonitor(cfg, logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m := NewOperatorBotBalanceMonitor(cfg, logger, apiClient)
 
 	err = m.Handler(context.Background())
 	suite.NoError(err)
@@ -49,7 +51,8 @@ func (suite *BalanceMonitorTestSuite) TestNoUUSDBalance() {
 
 	logger := stubs.NewTestLogger()
 
-	m := NewOperatorBotBalanceMonitor(cfg, logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m := NewOperatorBotBalanceMonitor(cfg, logger, apiClient)
 	err = m.Handler(context.Background())
 	suite.Error(err)
 
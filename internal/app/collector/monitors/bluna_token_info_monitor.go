@@ -8,7 +8,6 @@ import (
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/types"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/wasm"
@@ -21,12 +20,12 @@ var (
 	BlunaTotalSupply MetricName = "bluna_total_supply"
 )
 
-func NewBlunaTokenInfoMonitor(cfg config.CollectorConfig, logger *logrus.Logger) *BlunaTokenInfoMonitor {
+func NewBlunaTokenInfoMonitor(cfg config.CollectorConfig, logger *logrus.Logger, apiClient *client.TerraRESTApis) *BlunaTokenInfoMonitor {
 	m := BlunaTokenInfoMonitor{
 		State:           &types.TokenInfoResponse{},
 		ContractAddress: cfg.Addresses.BlunaTokenInfoContract,
 		metrics:         make(map[MetricName]MetricValue),
-		apiClient:       utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient:       apiClient,
 		logger:          logger,
 		lock:            sync.RWMutex{},
 	}
@@ -47,6 +46,12 @@ func (h *BlunaTokenInfoMonitor) Name() string {
 	return "BlunaTokenInfo"
 }
 
+func (h *BlunaTokenInfoMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{Name: BlunaTotalSupply, Help: "Total supply of the bLuna token.", Unit: "ubluna"},
+	}
+}
+
 func (h *BlunaTokenInfoMonitor) InitMetrics() {
 	h.setStringMetric(BlunaTotalSupply, "0")
 }
@@ -101,7 +106,7 @@ func (h *BlunaTokenInfoMonitor) setStringMetric(m MetricName, rawValue string) {
 		h.metrics[m] = &SimpleMetricValue{}
 	}
 
-	h.metrics[m].Set(value)
+	setDecimal(h.metrics[m], v, value)
 }
 
 func (h *BlunaTokenInfoMonitor) GetMetrics() map[MetricName]MetricValue {
@@ -9,7 +9,6 @@ import (
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/types"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/wasm"
@@ -36,23 +35,32 @@ type ConfigsCRC32Monitor struct {
 	contractsVersion string
 }
 
-func NewConfigsCRC32Monitor(cfg config.CollectorConfig, logger *logrus.Logger) *ConfigsCRC32Monitor {
+func NewConfigsCRC32Monitor(cfg config.CollectorConfig, logger *logrus.Logger, apiClient *client.TerraRESTApis) *ConfigsCRC32Monitor {
 	m := ConfigsCRC32Monitor{
-		Contracts: map[string]string{
-			cfg.Addresses.AirDropRegistryContract: AirDropRegistryConfigCRC32,
-			cfg.Addresses.HubContract:             HubConfigCRC32,
-			cfg.Addresses.RewardContract:          BlunaRewardConfigCRC32,
-		},
+		Contracts:        make(map[string]string),
 		metrics:          make(map[MetricName]MetricValue),
 		metricVectors:    make(map[MetricName]*MetricVector),
-		apiClient:        utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient:        apiClient,
 		logger:           logger,
 		lock:             sync.RWMutex{},
 		contractsVersion: cfg.BassetContractsVersion,
 	}
+	contracts := map[string]string{
+		cfg.Addresses.AirDropRegistryContract: AirDropRegistryConfigCRC32,
+		cfg.Addresses.HubContract:             HubConfigCRC32,
+		cfg.Addresses.RewardContract:          BlunaRewardConfigCRC32,
+	}
 	if m.contractsVersion == config.V2Contracts {
-		m.Contracts[cfg.Addresses.ValidatorsRegistryContract] = ValidatorsRegistryConfigCRC32
-		m.Contracts[cfg.Addresses.RewardsDispatcherContract] = RewardDispatcherConfigCRC32
+		contracts[cfg.Addresses.ValidatorsRegistryContract] = ValidatorsRegistryConfigCRC32
+		contracts[cfg.Addresses.RewardsDispatcherContract] = RewardDispatcherConfigCRC32
+	}
+	for contract, label := range contracts {
+		// the dummy contracts are not deployed yet, there is nothing to query
+		if !config.IsAddressSet(contract) {
+			logger.Warningf("%s: %s contract address is not set, skipping", m.Name(), label)
+			continue
+		}
+		m.Contracts[contract] = label
 	}
 
 	m.InitMetrics()
@@ -60,14 +68,26 @@ func NewConfigsCRC32Monitor(cfg config.CollectorConfig, logger *logrus.Logger) *
 	return &m
 }
 
-func (m *ConfigsCRC32Monitor) providedMetricVectors() []MetricName {
-	return []MetricName{ConfigCRC32}
+func (m *ConfigsCRC32Monitor) providedMetricVectors() map[MetricName][]string {
+	return map[MetricName][]string{
+		ConfigCRC32: {ConfigLabel, ContractAddressLabel},
+	}
 }
 
 func (m *ConfigsCRC32Monitor) Name() string {
 	return "ConfigsCRC32Monitor"
 }
 
+func (m *ConfigsCRC32Monitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{
+			Name:       ConfigCRC32,
+			Help:       "CRC32 checksum of the contract config, it changes once the config is changed.",
+			LabelNames: []string{ConfigLabel, ContractAddressLabel},
+		},
+	}
+}
+
 func (m *ConfigsCRC32Monitor) InitMetrics() {
 	initMetrics(nil, m.providedMetricVectors(), nil, m.metricVectors)
 }
@@ -110,7 +130,10 @@ func (m *ConfigsCRC32Monitor) Handler(ctx context.Context) error {
 			m.logger.Errorf("failed to marshal %s: %+v", m.Name(), err)
 		}
 
-		tmpMetricVectors[ConfigCRC32].Set(label, float64(crc32.ChecksumIEEE(data)))
+		tmpMetricVectors[ConfigCRC32].Set(
+			Labels{ConfigLabel: label, ContractAddressLabel: contract},
+			float64(crc32.ChecksumIEEE(data)),
+		)
 	}
 
 	m.lock.Lock()
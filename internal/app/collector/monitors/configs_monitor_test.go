@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 
 	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
 	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/types"
@@ -39,7 +40,8 @@ func (suite *DetectorChangesTestSuite) TestHubParameters() {
 	cfg := stubs.NewTestCollectorConfig(ts.URL)
 
 	logger := stubs.NewTestLogger()
-	hubParametersMonitor := NewHubParametersMonitor(cfg, logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	hubParametersMonitor := NewHubParametersMonitor(cfg, logger, apiClient)
 
 	err = hubParametersMonitor.Handler(context.Background())
 	suite.NoError(err)
@@ -65,7 +67,8 @@ func (suite *DetectorChangesTestSuite) TestHubParameters() {
 
 	ts = stubs.NewServerWithResponse(string(responseData))
 	cfg = stubs.NewTestCollectorConfig(ts.URL)
-	hubParametersMonitor = NewHubParametersMonitor(cfg, logger)
+	apiClient, _ = source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	hubParametersMonitor = NewHubParametersMonitor(cfg, logger, apiClient)
 
 	err = hubParametersMonitor.Handler(context.Background())
 	suite.NoError(err)
@@ -81,9 +84,13 @@ func (suite *DetectorChangesTestSuite) TestConfigsMonitorV2() {
 	ts := stubs.NewServerWithRandomJson()
 	cfg := stubs.NewTestCollectorConfig(ts.URL)
 	cfg.BassetContractsVersion = config.V2Contracts
+	cfg.Addresses.ValidatorsRegistryContract = "terra_validators_registry"
+	cfg.Addresses.RewardsDispatcherContract = "terra_rewards_dispatcher"
+	cfg.Addresses.AirDropRegistryContract = "terra_airdrop_registry"
 	logger := stubs.NewTestLogger()
-	m1 := NewConfigsCRC32Monitor(cfg, logger)
-	savedMetrics := NewMetricVector()
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m1 := NewConfigsCRC32Monitor(cfg, logger, apiClient)
+	savedMetrics := NewMetricVector(ConfigLabel, ContractAddressLabel)
 
 	err := m1.Handler(context.Background())
 	suite.NoError(err)
@@ -100,7 +107,7 @@ func (suite *DetectorChangesTestSuite) TestConfigsMonitorV2() {
 	for _, label := range m1.metricVectors[ConfigCRC32].Labels() {
 		var found bool
 		for _, wantedLabel := range savedMetrics.Labels() {
-			if label == wantedLabel {
+			if label[ConfigLabel] == wantedLabel[ConfigLabel] && label[ContractAddressLabel] == wantedLabel[ContractAddressLabel] {
 				found = true
 				break
 			}
@@ -109,3 +116,22 @@ func (suite *DetectorChangesTestSuite) TestConfigsMonitorV2() {
 		suite.NotEqual(m1.metricVectors[ConfigCRC32].Get(label), savedMetrics.Get(label))
 	}
 }
+
+func (suite *DetectorChangesTestSuite) TestConfigsMonitorSkipsDummyContracts() {
+	ts := stubs.NewServerWithRandomJson()
+	cfg := stubs.NewTestCollectorConfig(ts.URL)
+	cfg.BassetContractsVersion = config.V2Contracts
+	logger := stubs.NewTestLogger()
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m := NewConfigsCRC32Monitor(cfg, logger, apiClient)
+
+	// the test config has dummy airdrop registry, validators registry and rewards dispatcher addresses
+	suite.Equal(map[string]string{
+		types.HubContract:    HubConfigCRC32,
+		types.RewardContract: BlunaRewardConfigCRC32,
+	}, m.Contracts)
+
+	err := m.Handler(context.Background())
+	suite.NoError(err)
+	suite.Equal(2, len(m.metricVectors[ConfigCRC32].Labels()))
+}
@@ -62,13 +62,23 @@ func (m *DelegationsDistributionMonitor) Name() string {
 	return "DelegationsDistribution"
 }
 
+func (m *DelegationsDistributionMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{
+			Name:       DelegationsDistributionImbalance,
+			Help:       "Whether the hub delegation to the validator is an outlier of the delegations distribution (1) or not (0).",
+			LabelNames: validatorLabelNames,
+		},
+	}
+}
+
 func (m *DelegationsDistributionMonitor) providedMetrics() []MetricName {
 	return []MetricName{}
 }
 
-func (m *DelegationsDistributionMonitor) providedMetricVectors() []MetricName {
-	return []MetricName{
-		DelegationsDistributionImbalance,
+func (m *DelegationsDistributionMonitor) providedMetricVectors() map[MetricName][]string {
+	return map[MetricName][]string{
+		DelegationsDistributionImbalance: validatorLabelNames,
 	}
 }
 
@@ -98,11 +108,11 @@ func (m *DelegationsDistributionMonitor) Handler(ctx context.Context) error {
 			return fmt.Errorf("failed to GetValidatorInfo: %w", err)
 		}
 
-		label := fmt.Sprintf("%s (%s)", delegationsResponse[idx].ValidatorAddress, validatorInfo.Moniker)
-		tmpMetricVectors[DelegationsDistributionImbalance].Set(label, 0)
+		labels := validatorLabels(delegationsResponse[idx].ValidatorAddress, validatorInfo.Moniker)
+		tmpMetricVectors[DelegationsDistributionImbalance].Set(labels, 0)
 
 		if containsInt(outliers, idx) {
-			tmpMetricVectors[DelegationsDistributionImbalance].Set(label, 1)
+			tmpMetricVectors[DelegationsDistributionImbalance].Set(labels, 1)
 		}
 	}
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
 	"github.com/lidofinance/terra-monitors/internal/app/collector/types"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
 	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
 	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
@@ -16,7 +17,7 @@ import (
 )
 
 const (
-	TestDelegationsDistributionOutlierLabel = "terravalcons1ezj3lps8nqwytt42at2sgt7seq9hk708g0sp09 (Test validator)"
+	TestDelegationsDistributionOutlierAddress = "terravalcons1ezj3lps8nqwytt42at2sgt7seq9hk708g0sp09"
 )
 
 type DelegationsDistributionTestSuite struct {
@@ -46,7 +47,7 @@ func (suite *DelegationsDistributionTestSuite) TestDelegationsDistributionNoPani
 	cfg.BassetContractsVersion = "2"
 	cfg.NetworkGeneration = "columbus-5"
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
@@ -56,7 +57,7 @@ func (suite *DelegationsDistributionTestSuite) TestDelegationsDistributionNoPani
 	suite.NoError(err)
 
 	for _, value := range m.metricVectors[DelegationsDistributionImbalance].values {
-		suite.Equal(float64(0), value)
+		suite.Equal(float64(0), value.value)
 	}
 }
 
@@ -79,7 +80,7 @@ func (suite *DelegationsDistributionTestSuite) TestDelegationsDistributionPanic(
 	cfg.BassetContractsVersion = "2"
 	cfg.NetworkGeneration = "columbus-5"
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
@@ -88,11 +89,11 @@ func (suite *DelegationsDistributionTestSuite) TestDelegationsDistributionPanic(
 	err = m.Handler(context.Background())
 	suite.NoError(err)
 
-	for idx, value := range m.metricVectors[DelegationsDistributionImbalance].values {
-		if idx == TestDelegationsDistributionOutlierLabel {
-			suite.Equal(float64(1), value)
+	for _, value := range m.metricVectors[DelegationsDistributionImbalance].values {
+		if value.labels[ValidatorAddressLabel] == TestDelegationsDistributionOutlierAddress {
+			suite.Equal(float64(1), value.value)
 		} else {
-			suite.Equal(float64(0), value)
+			suite.Equal(float64(0), value.value)
 		}
 	}
 }
@@ -7,7 +7,6 @@ import (
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-repositories/delegations"
@@ -16,7 +15,7 @@ import (
 )
 
 const (
-	FailedRedelegations = "failed_redelegations"
+	FailedRedelegations MetricName = "failed_redelegations"
 )
 
 type FailedRedelegationsMonitor struct {
@@ -36,13 +35,14 @@ type FailedRedelegationsMonitor struct {
 func NewFailedRedelegationsMonitor(
 	cfg config.CollectorConfig,
 	logger *logrus.Logger,
+	apiClient *client.TerraRESTApis,
 	repository repositories.ValidatorsRepository,
 	delegationsRepository *delegations.Repository,
 ) *FailedRedelegationsMonitor {
 	m := FailedRedelegationsMonitor{
 		metrics:       make(map[MetricName]MetricValue),
 		metricVectors: make(map[MetricName]*MetricVector),
-		apiClient:     utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient:     apiClient,
 		logger:        logger,
 
 		validatorsRepository:  repository,
@@ -59,14 +59,24 @@ func (m *FailedRedelegationsMonitor) Name() string {
 	return "FailedRedelegationsMonitor"
 }
 
-func (m *FailedRedelegationsMonitor) providedMetrics() []MetricName {
-	return []MetricName{
-		FailedRedelegations,
+func (m *FailedRedelegationsMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{
+			Name:       FailedRedelegations,
+			Help:       "Whether the hub still has a delegation to the validator removed from the whitelist (1) or not (0).",
+			LabelNames: validatorLabelNames,
+		},
+	}
+}
+
+func (m *FailedRedelegationsMonitor) providedMetricVectors() map[MetricName][]string {
+	return map[MetricName][]string{
+		FailedRedelegations: validatorLabelNames,
 	}
 }
 
 func (m *FailedRedelegationsMonitor) InitMetrics() {
-	initMetrics([]MetricName{}, []MetricName{FailedRedelegations}, m.metrics, m.metricVectors)
+	initMetrics([]MetricName{}, m.providedMetricVectors(), m.metrics, m.metricVectors)
 }
 
 func (m *FailedRedelegationsMonitor) GetMetrics() map[MetricName]MetricValue {
@@ -79,7 +89,7 @@ func (m *FailedRedelegationsMonitor) GetMetricVectors() map[MetricName]*MetricVe
 
 func (m *FailedRedelegationsMonitor) Handler(ctx context.Context) error {
 	tmpMetricVectors := make(map[MetricName]*MetricVector)
-	initMetrics(nil, []MetricName{FailedRedelegations}, nil, tmpMetricVectors)
+	initMetrics(nil, m.providedMetricVectors(), nil, tmpMetricVectors)
 
 	whitelistedValidators, err := m.validatorsRepository.GetValidatorsAddresses(ctx)
 	if err != nil {
@@ -97,14 +107,14 @@ func (m *FailedRedelegationsMonitor) Handler(ctx context.Context) error {
 			return fmt.Errorf("failed to GetValidatorInfo: %w", err)
 		}
 
-		label := fmt.Sprintf("%s (%s)", delegation.ValidatorAddress, validatorInfo.Moniker)
+		labels := validatorLabels(delegation.ValidatorAddress, validatorInfo.Moniker)
 
-		tmpMetricVectors[FailedRedelegations].Set(label, 0)
+		tmpMetricVectors[FailedRedelegations].Set(labels, 0)
 
 		// if delegated amount is greater than zero and the whitelisted validators don't contain a validator
 		// that means a redelegation was not successful
 		if !delegation.DelegationAmount.IsZero() && !containsString(whitelistedValidators, delegation.ValidatorAddress) {
-			tmpMetricVectors[FailedRedelegations].Set(label, 1)
+			tmpMetricVectors[FailedRedelegations].Set(labels, 1)
 		}
 	}
 
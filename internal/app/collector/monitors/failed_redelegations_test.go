@@ -8,6 +8,7 @@ import (
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
 	"github.com/lidofinance/terra-monitors/internal/app/collector/types"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
 	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
 	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
@@ -54,19 +55,19 @@ func (suite *FailedRedelegationsMonitorTestSuite) TestRedelegationFailedRequest(
 	cfg.BassetContractsVersion = "2"
 	cfg.NetworkGeneration = "columbus-5"
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
 	delRepository := delegations.New(apiClient)
-	m := NewFailedRedelegationsMonitor(cfg, logger, valRepository, delRepository)
+	m := NewFailedRedelegationsMonitor(cfg, logger, apiClient, valRepository, delRepository)
 	err = m.Handler(context.Background())
 	suite.NoError(err)
 
 	metricVectors := m.GetMetricVectors()
 
-	failedRedelegationValidatorLabel := fmt.Sprintf("%s (%s)", TestFailedRedelegationValidatorAddress, types.TestMoniker)
-	delegationValidatorAddressWithNonZeroSharesLabel := fmt.Sprintf("%s (%s)", TestDelegationValidatorAddressWithNonZeroShares, TestMoniker1)
+	failedRedelegationValidatorLabel := validatorLabels(TestFailedRedelegationValidatorAddress, types.TestMoniker)
+	delegationValidatorAddressWithNonZeroSharesLabel := validatorLabels(TestDelegationValidatorAddressWithNonZeroShares, TestMoniker1)
 
 	expectedFailedValidatorsRedelegations := 1.0
 	actualFailedValidatorsRedelegations := metricVectors[FailedRedelegations].Get(failedRedelegationValidatorLabel)
@@ -98,18 +99,18 @@ func (suite *FailedRedelegationsMonitorTestSuite) TestRedelegationSucceedRequest
 	cfg.BassetContractsVersion = "2"
 	cfg.NetworkGeneration = "columbus-5"
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
 	delRepository := delegations.New(apiClient)
-	m := NewFailedRedelegationsMonitor(cfg, logger, valRepository, delRepository)
+	m := NewFailedRedelegationsMonitor(cfg, logger, apiClient, valRepository, delRepository)
 	err = m.Handler(context.Background())
 	suite.NoError(err)
 
 	metricVectors := m.GetMetricVectors()
 
-	label := fmt.Sprintf("%s (%s)", TestValidatorAddress, types.TestMoniker)
+	label := validatorLabels(TestValidatorAddress, types.TestMoniker)
 	failedValidatorsRedelegations := metricVectors[FailedRedelegations].Get(label)
 
 	suite.Equal(0.0, failedValidatorsRedelegations)
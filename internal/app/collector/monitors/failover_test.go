@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
 	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
 	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 	"github.com/stretchr/testify/suite"
@@ -30,7 +31,8 @@ func (suite *BalanceMonitorTestSuite) TestFailover() {
 	logger := stubs.NewTestLogger()
 	incorrectURL := "http://127.0.0.1:1234"
 	cfg := stubs.NewTestCollectorConfig(incorrectURL)
-	m := NewOperatorBotBalanceMonitor(cfg, logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m := NewOperatorBotBalanceMonitor(cfg, logger, apiClient)
 	err = m.Handler(context.Background())
 	suite.Error(err)
 
@@ -40,7 +42,8 @@ func (suite *BalanceMonitorTestSuite) TestFailover() {
 	connectionRefusedLogMessagePattern := "connect: connection refused"
 	ts := stubs.NewServerWithResponse(string(balanceInfo))
 	cfg = stubs.NewTestCollectorConfig(incorrectURL, ts.URL)
-	m = NewOperatorBotBalanceMonitor(cfg, logger)
+	apiClient, _ = source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m = NewOperatorBotBalanceMonitor(cfg, logger, apiClient)
 	err = m.Handler(context.Background())
 	suite.NoError(err)
 	actualMessages := fmt.Sprintln(logger.Out)
@@ -50,7 +53,8 @@ func (suite *BalanceMonitorTestSuite) TestFailover() {
 	// no error log is expected).
 	logger = stubs.NewTestLogger()
 	cfg = stubs.NewTestCollectorConfig(ts.URL, incorrectURL)
-	m = NewOperatorBotBalanceMonitor(cfg, logger)
+	apiClient, _ = source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m = NewOperatorBotBalanceMonitor(cfg, logger, apiClient)
 	err = m.Handler(context.Background())
 	suite.NoError(err)
 	actualMessages = fmt.Sprintln(logger.Out)
@@ -8,7 +8,6 @@ import (
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/types"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/wasm"
@@ -33,12 +32,12 @@ type HubParametersMonitor struct {
 	logger          *logrus.Logger
 }
 
-func NewHubParametersMonitor(cfg config.CollectorConfig, logger *logrus.Logger) HubParametersMonitor {
+func NewHubParametersMonitor(cfg config.CollectorConfig, logger *logrus.Logger, apiClient *client.TerraRESTApis) HubParametersMonitor {
 	m := HubParametersMonitor{
 		metrics:         make(map[MetricName]MetricValue),
 		State:           &types.HubParameters{},
 		ContractAddress: cfg.Addresses.HubContract,
-		apiClient:       utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient:       apiClient,
 		logger:          logger,
 	}
 	m.InitMetrics()
@@ -49,6 +48,16 @@ func NewHubParametersMonitor(cfg config.CollectorConfig, logger *logrus.Logger)
 func (h HubParametersMonitor) Name() string {
 	return "HubParameters"
 }
+
+func (h *HubParametersMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{Name: HubParametersEpochPeriod, Help: "Epoch period of the hub contract.", Unit: "seconds"},
+		{Name: HubParametersUnbondingPeriod, Help: "Unbonding period of the hub contract.", Unit: "seconds"},
+		{Name: HubParametersPegRecoveryFee, Help: "Peg recovery fee of the hub contract."},
+		{Name: HubParametersErThreshold, Help: "Exchange rate threshold of the hub contract."},
+		{Name: HubParametersCRC32, Help: "CRC32 checksum of the hub contract parameters, it changes once the parameters are changed."},
+	}
+}
 func (h *HubParametersMonitor) providedMetrics() []MetricName {
 	return []MetricName{
 		HubParametersCRC32,
@@ -82,7 +91,7 @@ func (h *HubParametersMonitor) setStringMetric(m MetricName, rawValue string) {
 	if h.metrics[m] == nil {
 		h.metrics[m] = &SimpleMetricValue{}
 	}
-	h.metrics[m].Set(value)
+	setDecimal(h.metrics[m], v, value)
 }
 
 func (h *HubParametersMonitor) updateMetrics() {
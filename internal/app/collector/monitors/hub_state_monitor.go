@@ -7,7 +7,6 @@ import (
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/types"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/wasm"
@@ -21,7 +20,7 @@ var (
 	BlunaExchangeRate MetricName = "bluna_exchange_rate"
 )
 
-func NewHubStateMonitor(cfg config.CollectorConfig, logger *logrus.Logger) Monitor {
+func NewHubStateMonitor(cfg config.CollectorConfig, logger *logrus.Logger, apiClient *client.TerraRESTApis) Monitor {
 
 	switch cfg.BassetContractsVersion {
 	case config.V1Contracts:
@@ -30,7 +29,7 @@ func NewHubStateMonitor(cfg config.CollectorConfig, logger *logrus.Logger) Monit
 				State:      &types.HubStateResponseV1{},
 				HubAddress: cfg.Addresses.HubContract,
 				metrics:    make(map[MetricName]MetricValue),
-				apiClient:  utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+				apiClient:  apiClient,
 				logger:     logger,
 			}
 			m1.InitMetrics()
@@ -42,7 +41,7 @@ func NewHubStateMonitor(cfg config.CollectorConfig, logger *logrus.Logger) Monit
 				State:      &types.HubStateResponseV2{},
 				HubAddress: cfg.Addresses.HubContract,
 				metrics:    make(map[MetricName]MetricValue),
-				apiClient:  utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+				apiClient:  apiClient,
 				logger:     logger,
 			}
 			m2.InitMetrics()
@@ -65,6 +64,13 @@ func (h HubStateMonitor) Name() string {
 	return "HubState"
 }
 
+func (h *HubStateMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{Name: BlunaBondedAmount, Help: "Amount of Luna bonded for bLuna by the hub contract.", Unit: "uluna"},
+		{Name: BlunaExchangeRate, Help: "bLuna to Luna exchange rate of the hub contract."},
+	}
+}
+
 func (h *HubStateMonitor) InitMetrics() {
 	h.setStringMetric(BlunaBondedAmount, "0")
 	h.setStringMetric(BlunaExchangeRate, "0")
@@ -119,7 +125,7 @@ func (h *HubStateMonitor) setStringMetric(m MetricName, rawValue string) {
 		h.metrics[m] = &SimpleMetricValue{}
 	}
 
-	h.metrics[m].Set(value)
+	setDecimal(h.metrics[m], v, value)
 }
 
 func (h HubStateMonitor) GetMetrics() map[MetricName]MetricValue {
@@ -31,6 +31,15 @@ func (h HubStateMonitorV2) Name() string {
 	return "HubState"
 }
 
+func (h *HubStateMonitorV2) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{Name: BlunaBondedAmount, Help: "Amount of Luna bonded for bLuna by the hub contract.", Unit: "uluna"},
+		{Name: BlunaExchangeRate, Help: "bLuna to Luna exchange rate of the hub contract."},
+		{Name: StlunaBondedAmount, Help: "Amount of Luna bonded for stLuna by the hub contract.", Unit: "uluna"},
+		{Name: StlunaExchangeRate, Help: "stLuna to Luna exchange rate of the hub contract."},
+	}
+}
+
 func (h *HubStateMonitorV2) InitMetrics() {
 	h.setStringMetric(BlunaBondedAmount, "0")
 	h.setStringMetric(BlunaExchangeRate, "0")
@@ -88,7 +97,7 @@ func (h *HubStateMonitorV2) setStringMetric(m MetricName, rawValue string) {
 	if h.metrics[m] == nil {
 		h.metrics[m] = &SimpleMetricValue{}
 	}
-	h.metrics[m].Set(value)
+	setDecimal(h.metrics[m], v, value)
 }
 
 func (h HubStateMonitorV2) GetMetrics() map[MetricName]MetricValue {
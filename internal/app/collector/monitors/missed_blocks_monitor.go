@@ -8,7 +8,9 @@ import (
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
+	"github.com/lidofinance/terra-monitors/internal/pkg/state"
+	"github.com/lidofinance/terra-monitors/internal/pkg/workerpool"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/tendermint_rpc"
@@ -18,37 +20,58 @@ import (
 )
 
 const (
-	MissedBlocksForPeriod MetricName = "missed_blocks_for_period"
-	InitialBlocksAmount              = 10
+	MissedBlocksTotal               MetricName = "missed_blocks_total"
+	MissedBlocksNumFailedBlocks     MetricName = "missed_blocks_num_failed_blocks"
+	MissedBlocksNumFailedValidators MetricName = "missed_blocks_num_failed_validators"
+	InitialBlocksAmount                        = 10
 )
 
+// missedBlocksState is the MissedBlocksMonitor state kept across the restarts.
+type missedBlocksState struct {
+	LatestCommittedChecked int           `json:"latest_committed_checked"`
+	MissedBlocks           []vectorValue `json:"missed_blocks"`
+}
+
 type MissedBlocksMonitor struct {
 	networkGeneration      string
 	validators             map[string]string // map valoper address -> valcons address
 	latestCommittedChecked int
+	metrics                map[MetricName]MetricValue
 	metricVectors          map[MetricName]*MetricVector
 	apiClient              *client.TerraRESTApis
 	validatorsRepository   repositories.ValidatorsRepository
+	fetchConcurrency       int
 	logger                 *logrus.Logger
 	lock                   sync.RWMutex
+
+	// maxBackfillBlocks limits the blocks checked at once, 0 means no limit
+	maxBackfillBlocks int
+	state             monitorState
 }
 
 func NewMissedBlocksMonitor(
 	cfg config.CollectorConfig,
 	logger *logrus.Logger,
+	apiClient *client.TerraRESTApis,
 	repository repositories.ValidatorsRepository,
+	store *state.Store,
 ) *MissedBlocksMonitor {
 	m := &MissedBlocksMonitor{
 		networkGeneration:    cfg.NetworkGeneration,
 		validators:           make(map[string]string),
+		metrics:              make(map[MetricName]MetricValue),
 		metricVectors:        make(map[MetricName]*MetricVector),
-		apiClient:            utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient:            apiClient,
 		validatorsRepository: repository,
+		fetchConcurrency:     cfg.FetchConcurrency,
+		maxBackfillBlocks:    cfg.State.MaxBackfillBlocks,
 		logger:               logger,
 		lock:                 sync.RWMutex{},
 	}
+	m.state = newMonitorState(store, cfg, m.Name(), logger)
 
 	m.InitMetrics()
+	m.restoreState()
 
 	return m
 }
@@ -57,18 +80,54 @@ func (m *MissedBlocksMonitor) Name() string {
 	return "MissedBlocks"
 }
 
-func (m *MissedBlocksMonitor) providedMetrics() []MetricName {
-	return []MetricName{}
+func (m *MissedBlocksMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{
+			Name:       MissedBlocksTotal,
+			Help:       "Number of the blocks not signed by the validator since the monitor start.",
+			Unit:       "blocks",
+			Kind:       CounterKind,
+			LabelNames: validatorLabelNames,
+		},
+		{
+			Name: MissedBlocksNumFailedBlocks,
+			Help: "Number of the blocks failed to fetch in the last run, they are not checked for the missed signatures.",
+			Unit: "blocks",
+		},
+		{Name: MissedBlocksNumFailedValidators, Help: "Number of the whitelisted validators failed to fetch in the last run."},
+	}
 }
 
-func (m *MissedBlocksMonitor) providedMetricVectors() []MetricName {
+func (m *MissedBlocksMonitor) providedMetrics() []MetricName {
 	return []MetricName{
-		MissedBlocksForPeriod,
+		MissedBlocksNumFailedBlocks,
+		MissedBlocksNumFailedValidators,
 	}
 }
 
+func (m *MissedBlocksMonitor) providedMetricVectors() map[MetricName][]string {
+	return map[MetricName][]string{
+		MissedBlocksTotal: validatorLabelNames,
+	}
+}
+
+// InitMetrics creates the missed blocks counters. The counters are accumulated by Handler and never reset.
 func (m *MissedBlocksMonitor) InitMetrics() {
-	initMetrics(nil, m.providedMetricVectors(), nil, m.metricVectors)
+	initMetrics(m.providedMetrics(), nil, m.metrics, nil)
+	for metric, labelNames := range m.providedMetricVectors() {
+		m.metricVectors[metric] = NewCounterVector(labelNames...)
+	}
+}
+
+// restoreState resumes checking the blocks from the saved cursor and restores the accumulated counters.
+func (m *MissedBlocksMonitor) restoreState() {
+	var saved missedBlocksState
+	if !m.state.load(&saved) {
+		return
+	}
+	m.latestCommittedChecked = saved.LatestCommittedChecked
+	restoreVector(m.metricVectors[MissedBlocksTotal], saved.MissedBlocks)
+	m.logger.Infof("%s resumed from block %d", m.Name(), m.latestCommittedChecked)
 }
 
 func GetValidatorsSignedTheBlock(block *models.BlockQuery) map[string]struct{} {
@@ -79,91 +138,110 @@ func GetValidatorsSignedTheBlock(block *models.BlockQuery) map[string]struct{} {
 	return addresses
 }
 
-func (m *MissedBlocksMonitor) FetchLatestBlocks(ctx context.Context) ([]*models.BlockQuery, error) {
-	var blocks []*models.BlockQuery
-	var wg sync.WaitGroup
-	var lock sync.Mutex
-
+// FetchLatestBlocks fetches the blocks committed since the last checked one with at most fetchConcurrency
+// requests at once and returns them with the last committed block they check up to. The blocks failed to fetch
+// are logged and counted by the returned result, the blocks after the first failed one are left for the next
+// call, so the blocks are neither skipped nor checked twice.
+func (m *MissedBlocksMonitor) FetchLatestBlocks(ctx context.Context) ([]*models.BlockQuery, int, workerpool.Result, error) {
 	req := tendermint_rpc.GetBlocksLatestParams{}
 	req.SetContext(ctx)
 
 	resp, err := m.apiClient.TendermintRPC.GetBlocksLatest(&req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get latest block info: %w", err)
+		return nil, 0, workerpool.Result{}, fmt.Errorf("failed to get latest block info: %w", err)
 	}
 
-	if err := resp.GetPayload().Validate(nil); err != nil {
-		return nil, fmt.Errorf("failed to validate latest block response: %w", err)
+	if err := source.ValidatePayload(ctx, resp.GetPayload()); err != nil {
+		return nil, 0, workerpool.Result{}, fmt.Errorf("failed to validate latest block response: %w", err)
 	}
 	// last committed = 'height' - 1
 	lastCommitted, err := strconv.Atoi(resp.GetPayload().Block.LastCommit.Height)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse commits height: %w", err)
+		return nil, 0, workerpool.Result{}, fmt.Errorf("failed to parse commits height: %w", err)
 	}
 
+	checked := m.latestCommittedChecked
 	// no new blocks
-	if lastCommitted == m.latestCommittedChecked {
-		return nil, nil
+	if lastCommitted == checked {
+		return nil, checked, workerpool.Result{}, nil
 	}
 
-	blocks = append(blocks, resp.GetPayload())
-
-	if m.latestCommittedChecked == 0 {
-		m.latestCommittedChecked = lastCommitted - InitialBlocksAmount
+	if checked == 0 {
+		checked = lastCommitted - InitialBlocksAmount
+	} else if m.maxBackfillBlocks > 0 && lastCommitted-checked > m.maxBackfillBlocks {
+		m.logger.Warningf("skipping blocks %d-%d: the gap exceeds the max backfill of %d blocks",
+			checked+1, lastCommitted-m.maxBackfillBlocks, m.maxBackfillBlocks)
+		checked = lastCommitted - m.maxBackfillBlocks
 	}
 
 	//fetching needed blocks to check signatures
-	for committed := m.latestCommittedChecked + 1; committed < lastCommitted; committed++ {
-		wg.Add(1)
-		go func(committed int) {
-			defer wg.Done()
-			req := tendermint_rpc.GetBlocksHeightParams{}
-			req.SetContext(ctx)
-			// fetching block with height = committed + 1
-			// we are checking signatures for committed block "height - 1" witch number in Block.LastCommit.Height field
-			height := committed + 1
-			req.SetHeight(int64(height))
-
-			resp, err := m.apiClient.TendermintRPC.GetBlocksHeight(&req)
-			if err != nil {
-				m.logger.Errorf("failed to get latest block info: %+v\n", err)
-				return
-			}
+	firstCommitted := checked + 1
+	var fetchedBlocks []*models.BlockQuery
+	if lastCommitted > firstCommitted {
+		fetchedBlocks = make([]*models.BlockQuery, lastCommitted-firstCommitted)
+	}
+	result := workerpool.Run(ctx, m.fetchConcurrency, len(fetchedBlocks), func(ctx context.Context, i int) error {
+		req := tendermint_rpc.GetBlocksHeightParams{}
+		req.SetContext(ctx)
+		// fetching block with height = committed + 1
+		// we are checking signatures for committed block "height - 1" witch number in Block.LastCommit.Height field
+		height := firstCommitted + i + 1
+		req.SetHeight(int64(height))
+
+		resp, err := m.apiClient.TendermintRPC.GetBlocksHeight(&req)
+		if err != nil {
+			return fmt.Errorf("failed to get block %d info: %w", height, err)
+		}
 
-			if err := resp.GetPayload().Validate(nil); err != nil {
-				m.logger.Errorf("failed to validate latest block response: %+v", err)
-				return
-			}
-			lock.Lock()
-			defer lock.Unlock()
-			blocks = append(blocks, resp.GetPayload())
-		}(committed)
-	}
-	wg.Wait()
-	m.latestCommittedChecked = lastCommitted
-	return blocks, nil
+		if err := source.ValidatePayload(ctx, resp.GetPayload()); err != nil {
+			return fmt.Errorf("failed to validate block %d response: %w", height, err)
+		}
+		fetchedBlocks[i] = resp.GetPayload()
+		return nil
+	})
+	firstFailed := len(fetchedBlocks)
+	for i, err := range result.Errors {
+		m.logger.Errorf("failed to fetch block: %v", err)
+		if i < firstFailed {
+			firstFailed = i
+		}
+	}
+
+	// the fetched block i checks the committed block firstCommitted + i, the latest block checks lastCommitted
+	var blocks []*models.BlockQuery
+	if result.Failed() == 0 {
+		blocks = append(blocks, resp.GetPayload())
+		checked = lastCommitted
+	} else {
+		checked = firstCommitted + firstFailed - 1
+	}
+	blocks = append(blocks, fetchedBlocks[:firstFailed]...)
+	return blocks, checked, result, nil
 }
 
 func (m *MissedBlocksMonitor) Handler(ctx context.Context) error {
 	// tmp* for 2stage nonblocking update data
+	tmpMetrics := make(map[MetricName]MetricValue)
 	tmpMetricVectors := make(map[MetricName]*MetricVector)
-	initMetrics(nil, []MetricName{MissedBlocksForPeriod}, nil, tmpMetricVectors)
+	initMetrics(m.providedMetrics(), m.providedMetricVectors(), tmpMetrics, tmpMetricVectors)
 
-	blocks, err := m.FetchLatestBlocks(ctx)
+	blocks, checked, fetchedBlocks, err := m.FetchLatestBlocks(ctx)
 
 	if err != nil {
 		return fmt.Errorf("failed to fetch blocks: %w", err)
 	}
-	if len(blocks) == 0 {
+	if len(blocks) == 0 && fetchedBlocks.Failed() == 0 {
 		m.logger.Infoln("no new blocks")
 		return nil
 	}
 
-	validatorsInfo, err := getValidatorsInfo(ctx, m.validatorsRepository)
+	validatorsInfo, fetchedValidators, err := getValidatorsInfo(ctx, m.logger, m.validatorsRepository, m.fetchConcurrency)
 
 	if err != nil {
 		return fmt.Errorf("failed to getValidatorsInfo: %w", err)
 	}
+	tmpMetrics[MissedBlocksNumFailedBlocks].Set(float64(fetchedBlocks.Failed()))
+	tmpMetrics[MissedBlocksNumFailedValidators].Set(float64(fetchedValidators.Failed()))
 
 	for _, validatorInfo := range validatorsInfo {
 
@@ -178,34 +256,42 @@ func (m *MissedBlocksMonitor) Handler(ctx context.Context) error {
 				m.validators[validatorInfo.Address] = consAddress
 			}
 			signedValidators := GetValidatorsSignedTheBlock(block)
-			tmpMetricVectors[MissedBlocksForPeriod].Add(validatorInfo.Moniker, 0)
+			labels := validatorLabels(validatorInfo.Address, validatorInfo.Moniker)
+			tmpMetricVectors[MissedBlocksTotal].Add(labels, 0)
 			if _, found := signedValidators[consAddress]; !found {
-				tmpMetricVectors[MissedBlocksForPeriod].Add(validatorInfo.Moniker, 1)
+				tmpMetricVectors[MissedBlocksTotal].Add(labels, 1)
 			}
 		}
 	}
 
 	m.lock.Lock()
 	defer m.lock.Unlock()
+	copyMetrics(tmpMetrics, m.metrics)
+	// the cursor is advanced with the counters, so the failed run checks the same blocks again
+	m.latestCommittedChecked = checked
 	// accumulating missed blocks
-	for _, label := range tmpMetricVectors[MissedBlocksForPeriod].Labels() {
-		m.metricVectors[MissedBlocksForPeriod].Add(label, tmpMetricVectors[MissedBlocksForPeriod].Get(label))
+	for _, labels := range tmpMetricVectors[MissedBlocksTotal].Labels() {
+		m.metricVectors[MissedBlocksTotal].Add(labels, tmpMetricVectors[MissedBlocksTotal].Get(labels))
 	}
+	m.state.save(missedBlocksState{
+		LatestCommittedChecked: m.latestCommittedChecked,
+		MissedBlocks:           vectorValues(m.metricVectors[MissedBlocksTotal]),
+	})
 
 	m.logger.Infoln("updated", m.Name())
 	return nil
 }
 
 func (m *MissedBlocksMonitor) GetMetrics() map[MetricName]MetricValue {
-	return nil
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.metrics
 }
 
 func (m *MissedBlocksMonitor) GetMetricVectors() map[MetricName]*MetricVector {
-	// we need a delta value between checks, so we are dropping missed blocks counter once value is read
-	m.lock.Lock()
-	defer m.lock.Unlock()
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	r := make(map[MetricName]*MetricVector)
 	copyVectors(m.metricVectors, r)
-	initMetrics(nil, []MetricName{MissedBlocksForPeriod}, nil, m.metricVectors)
 	return r
 }
@@ -10,6 +10,8 @@ import (
 	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
 	"github.com/lidofinance/terra-monitors/internal/app/collector/types"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
+	"github.com/lidofinance/terra-monitors/internal/pkg/state"
 	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
 	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
@@ -72,22 +74,200 @@ func (suite *MissedBlocksMonitorTestSuite) testMissedBlocks(networkGeneration st
 	cfg.BassetContractsVersion = config.V1Contracts
 	cfg.NetworkGeneration = networkGeneration
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
 
-	m := NewMissedBlocksMonitor(cfg, logger, valRepository)
+	m := NewMissedBlocksMonitor(cfg, logger, apiClient, valRepository, nil)
 	err = m.Handler(context.Background())
 	suite.NoError(err)
 
 	metricVectors := m.GetMetricVectors()
 
 	expectedValidatorsLabelsCount := 2
-	suite.Equal(expectedValidatorsLabelsCount, len(metricVectors[MissedBlocksForPeriod].Labels()))
+	suite.Equal(expectedValidatorsLabelsCount, len(metricVectors[MissedBlocksTotal].Labels()))
 	//"Test validator" has signed the block
-	suite.Equal(0.0, metricVectors[MissedBlocksForPeriod].Get("Test validator"))
+	suite.Equal(0.0, metricVectors[MissedBlocksTotal].Get(validatorLabels(types.TestValAddress, "Test validator")))
 	// "Test validator2" has not signed the block
 	// we have checked 10 blocks and  all 11 with no "Test validators2" sign
-	suite.Equal(10.0, metricVectors[MissedBlocksForPeriod].Get("Test validator2"))
+	suite.Equal(10.0, metricVectors[MissedBlocksTotal].Get(validatorLabels(types.TestValAddress2, "Test validator2")))
+	suite.Equal(CounterKind, metricVectors[MissedBlocksTotal].Kind())
+
+	// the counters are not reset on read
+	metricVectors = m.GetMetricVectors()
+	suite.Equal(10.0, metricVectors[MissedBlocksTotal].Get(validatorLabels(types.TestValAddress2, "Test validator2")))
+}
+
+func (suite *MissedBlocksMonitorTestSuite) TestMissedBlocksPartialFailure() {
+	dir, err := utils.GetTerraMonitorsPath()
+	suite.NoError(err)
+
+	// moniker - Test validator, the info of "Test validator2" is not available
+	validatorInfoData, err := ioutil.ReadFile(dir + "test_data/columbus-5/validators/first.json")
+	suite.NoError(err)
+
+	blockInfoBz, err := ioutil.ReadFile(dir + "test_data/columbus-5/block_info.json")
+	suite.NoError(err)
+
+	whitelistedValidators, err := ioutil.ReadFile(dir + "test_data/columbus-5/validators/two_whitelisted_validators.json")
+	suite.NoError(err)
+
+	testServerResponses := map[string]string{
+		fmt.Sprintf("/staking/validators/%s", types.TestValAddress): string(validatorInfoData),
+		"/blocks/latest": string(blockInfoBz),
+		fmt.Sprintf("/wasm/contracts/%s/store", types.HubContract): string(whitelistedValidators),
+	}
+	blockInfo := models.BlockQuery{}
+	err = json.Unmarshal(blockInfoBz, &blockInfo)
+	suite.NoError(err)
+	// the block 5 is not available
+	for i := 2; i <= 11; i++ {
+		if i == 5 {
+			continue
+		}
+		blockInfo.Block.LastCommit.Height = strconv.Itoa(i)
+		blockInfoUpdated, err := json.Marshal(blockInfo)
+		suite.NoError(err)
+		testServerResponses[fmt.Sprintf("/blocks/%d", i)] = string(blockInfoUpdated)
+	}
+
+	testServer := stubs.NewServerWithRoutedResponse(testServerResponses)
+
+	cfg := stubs.NewTestCollectorConfig(testServer.URL)
+	cfg.BassetContractsVersion = config.V1Contracts
+	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
+	logger := stubs.NewTestLogger()
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+
+	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
+	suite.NoError(err)
+
+	m := NewMissedBlocksMonitor(cfg, logger, apiClient, valRepository, nil)
+	err = m.Handler(context.Background())
+	suite.NoError(err)
+
+	metrics := m.GetMetrics()
+	suite.Equal(1.0, metrics[MissedBlocksNumFailedBlocks].Get())
+	suite.Equal(1.0, metrics[MissedBlocksNumFailedValidators].Get())
+
+	metricVectors := m.GetMetricVectors()
+	suite.Equal(1, len(metricVectors[MissedBlocksTotal].Labels()))
+	suite.Equal(0.0, metricVectors[MissedBlocksTotal].Get(validatorLabels(types.TestValAddress, "Test validator")))
+}
+
+// newResumedMissedBlocksMonitor creates the monitor of the blocks up to the committed block 11, "Test validator2"
+// hasn't signed any of them. The monitor state is kept by the store, the missing blocks are not available.
+func (suite *MissedBlocksMonitorTestSuite) newResumedMissedBlocksMonitor(
+	store *state.Store,
+	maxBackfillBlocks int,
+	missingBlocks ...int,
+) *MissedBlocksMonitor {
+	dir, err := utils.GetTerraMonitorsPath()
+	suite.NoError(err)
+
+	testServerResponses := map[string]string{}
+	for path, file := range map[string]string{
+		fmt.Sprintf("/staking/validators/%s", types.TestValAddress):  "validators/first.json",
+		fmt.Sprintf("/staking/validators/%s", types.TestValAddress2): "validators/second.json",
+		"/blocks/latest": "block_info.json",
+		fmt.Sprintf("/wasm/contracts/%s/store", types.HubContract): "validators/two_whitelisted_validators.json",
+	} {
+		data, err := ioutil.ReadFile(dir + "test_data/columbus-5/" + file)
+		suite.NoError(err)
+		testServerResponses[path] = string(data)
+	}
+	blockInfo := models.BlockQuery{}
+	suite.NoError(json.Unmarshal([]byte(testServerResponses["/blocks/latest"]), &blockInfo))
+	for i := 2; i <= 11; i++ {
+		blockInfo.Block.LastCommit.Height = strconv.Itoa(i)
+		blockInfoUpdated, err := json.Marshal(blockInfo)
+		suite.NoError(err)
+		testServerResponses[fmt.Sprintf("/blocks/%d", i)] = string(blockInfoUpdated)
+	}
+	for _, i := range missingBlocks {
+		delete(testServerResponses, fmt.Sprintf("/blocks/%d", i))
+	}
+	testServer := stubs.NewServerWithRoutedResponse(testServerResponses)
+	suite.T().Cleanup(testServer.Close)
+
+	cfg := stubs.NewTestCollectorConfig(testServer.URL)
+	cfg.BassetContractsVersion = config.V1Contracts
+	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
+	cfg.Deployment = "mainnet"
+	cfg.State.MaxBackfillBlocks = maxBackfillBlocks
+	logger := stubs.NewTestLogger()
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+
+	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
+	suite.NoError(err)
+	return NewMissedBlocksMonitor(cfg, logger, apiClient, valRepository, store)
+}
+
+func (suite *MissedBlocksMonitorTestSuite) TestMissedBlocksResume() {
+	store, err := state.Open("")
+	suite.NoError(err)
+	suite.NoError(store.Save("mainnet/MissedBlocks", missedBlocksState{
+		LatestCommittedChecked: 5,
+		MissedBlocks: []vectorValue{
+			{Labels: validatorLabels(types.TestValAddress2, "Test validator2"), Value: 7},
+		},
+	}))
+
+	m := suite.newResumedMissedBlocksMonitor(store, 100)
+	// the restored counters are served before the first run
+	suite.Equal(7.0, m.GetMetricVectors()[MissedBlocksTotal].Get(validatorLabels(types.TestValAddress2, "Test validator2")))
+
+	suite.NoError(m.Handler(context.Background()))
+	// the committed blocks 6-11 are checked
+	metricVectors := m.GetMetricVectors()
+	suite.Equal(13.0, metricVectors[MissedBlocksTotal].Get(validatorLabels(types.TestValAddress2, "Test validator2")))
+	suite.Equal(0.0, metricVectors[MissedBlocksTotal].Get(validatorLabels(types.TestValAddress, "Test validator")))
+
+	var saved missedBlocksState
+	found, err := store.Load("mainnet/MissedBlocks", &saved)
+	suite.NoError(err)
+	suite.True(found)
+	suite.Equal(11, saved.LatestCommittedChecked)
+	suite.Len(saved.MissedBlocks, 2)
+}
+
+func (suite *MissedBlocksMonitorTestSuite) TestMissedBlocksMaxBackfill() {
+	store, err := state.Open("")
+	suite.NoError(err)
+	suite.NoError(store.Save("mainnet/MissedBlocks", missedBlocksState{LatestCommittedChecked: 1}))
+
+	m := suite.newResumedMissedBlocksMonitor(store, 3)
+	suite.NoError(m.Handler(context.Background()))
+	// only the committed blocks 9-11 are checked
+	metricVectors := m.GetMetricVectors()
+	suite.Equal(3.0, metricVectors[MissedBlocksTotal].Get(validatorLabels(types.TestValAddress2, "Test validator2")))
+}
+
+func (suite *MissedBlocksMonitorTestSuite) TestMissedBlocksResumeAfterFailedBlock() {
+	store, err := state.Open("")
+	suite.NoError(err)
+	suite.NoError(store.Save("mainnet/MissedBlocks", missedBlocksState{LatestCommittedChecked: 5}))
+	missed := validatorLabels(types.TestValAddress2, "Test validator2")
+
+	// the block 8 checking the committed block 7 is not available, only the committed block 6 is checked
+	m := suite.newResumedMissedBlocksMonitor(store, 100, 8)
+	suite.NoError(m.Handler(context.Background()))
+	suite.Equal(1.0, m.GetMetrics()[MissedBlocksNumFailedBlocks].Get())
+	suite.Equal(1.0, m.GetMetricVectors()[MissedBlocksTotal].Get(missed))
+
+	var saved missedBlocksState
+	found, err := store.Load("mainnet/MissedBlocks", &saved)
+	suite.NoError(err)
+	suite.True(found)
+	suite.Equal(6, saved.LatestCommittedChecked)
+
+	// the restarted monitor checks the committed blocks 7-11 once
+	m = suite.newResumedMissedBlocksMonitor(store, 100)
+	suite.NoError(m.Handler(context.Background()))
+	suite.Equal(6.0, m.GetMetricVectors()[MissedBlocksTotal].Get(missed))
+	found, err = store.Load("mainnet/MissedBlocks", &saved)
+	suite.NoError(err)
+	suite.True(found)
+	suite.Equal(11, saved.LatestCommittedChecked)
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/types"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
 	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
 	"github.com/stretchr/testify/suite"
 )
@@ -29,7 +30,8 @@ func (suite *MonitorTestSuite) TestSuccessfulQueryRequest() {
 	ts := stubs.NewServerWithResponse(types.BlunaTokenInfo)
 	cfg := stubs.NewTestCollectorConfig(ts.URL)
 	logger := stubs.NewTestLogger()
-	blunaTokenInfoMonitor := NewBlunaTokenInfoMonitor(cfg, logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	blunaTokenInfoMonitor := NewBlunaTokenInfoMonitor(cfg, logger, apiClient)
 
 	err := blunaTokenInfoMonitor.Handler(context.Background())
 	suite.Require().NoError(err)
@@ -42,7 +44,8 @@ func (suite *MonitorTestSuite) TestBadQueryRequest() {
 	ts := stubs.NewServerWithError(expectedErr)
 	cfg := stubs.NewTestCollectorConfig(ts.URL)
 	logger := stubs.NewTestLogger()
-	blunaTokenInfoMonitor := NewBlunaTokenInfoMonitor(cfg, logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	blunaTokenInfoMonitor := NewBlunaTokenInfoMonitor(cfg, logger, apiClient)
 
 	err := blunaTokenInfoMonitor.Handler(context.Background())
 	suite.Require().Error(err)
@@ -54,13 +57,32 @@ func (suite *MonitorTestSuite) TestConnectionRefusedRequest() {
 	ts := stubs.NewServerWithClosedConnectionError()
 	cfg := stubs.NewTestCollectorConfig(ts.URL)
 	logger := stubs.NewTestLogger()
-	blunaTokenInfoMonitor := NewBlunaTokenInfoMonitor(cfg, logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	blunaTokenInfoMonitor := NewBlunaTokenInfoMonitor(cfg, logger, apiClient)
 
 	err := blunaTokenInfoMonitor.Handler(context.Background())
 	suite.Require().Error(err)
 	suite.Contains(err.Error(), expectedErr)
 }
 
+func (suite *MonitorTestSuite) TestHistogramMetricValue() {
+	h := NewHistogramMetricValue(10, 1, 5)
+	for _, v := range []float64{0.5, 1, 3, 7, 20} {
+		h.Add(v)
+	}
+
+	suite.Equal(HistogramKind, h.Kind())
+	suite.Equal(31.5, h.Get())
+	suite.Equal(HistogramData{
+		Count:   5,
+		Sum:     31.5,
+		Buckets: map[float64]uint64{1: 2, 5: 3, 10: 4},
+	}, h.Histogram())
+
+	h.Set(0)
+	suite.Equal(uint64(0), h.Histogram().Count)
+}
+
 func TestLocales(t *testing.T) {
 	suite.Run(t, new(MonitorTestSuite))
 	suite.Run(t, new(UpdateGlobalIndexMonitorTestSuite))
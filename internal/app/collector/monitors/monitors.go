@@ -2,10 +2,13 @@ package monitors
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
-	"time"
 
-	"github.com/sirupsen/logrus"
+	cosmostypes "github.com/cosmos/cosmos-sdk/types"
 )
 
 const UUSDDenom = "uusd"
@@ -18,46 +21,209 @@ type Monitor interface {
 	GetMetrics() map[MetricName]MetricValue
 	// GetMetricVectors - provides set of a labeled values fetched by Handler method
 	GetMetricVectors() map[MetricName]*MetricVector
+	// MetricDescs - describes every metric and metric vector provided by the monitor
+	MetricDescs() []MetricDesc
 }
 
 type MetricName string
 
+// MetricKind defines how the metric value changes over time.
+type MetricKind int
+
+const (
+	// GaugeKind is a value which can arbitrarily go up and down.
+	GaugeKind MetricKind = iota
+	// CounterKind is a monotonically increasing value, e.g. the number of processed transactions.
+	// Reading a counter doesn't change it, the consumers compute the deltas themselves.
+	CounterKind
+	// HistogramKind is a distribution of the observed values, see HistogramMetricValue.
+	HistogramKind
+)
+
+func (k MetricKind) String() string {
+	switch k {
+	case GaugeKind:
+		return "gauge"
+	case CounterKind:
+		return "counter"
+	case HistogramKind:
+		return "histogram"
+	default:
+		return fmt.Sprintf("MetricKind(%d)", int(k))
+	}
+}
+
+// MetricDesc describes a metric or a metric vector provided by a monitor.
+type MetricDesc struct {
+	Name MetricName
+	// Help is a human-readable description of the metric
+	Help string
+	// Unit of the metric value, e.g. "uluna" or "seconds", empty for the dimensionless values
+	Unit string
+	Kind MetricKind
+	// LabelNames are the label names of the metric vector, empty for the single value metrics
+	LabelNames []string
+	// DeprecatedAlias is the previous name of the renamed metric. The metric is exported under both names
+	// until the dashboards and alerts are migrated.
+	DeprecatedAlias MetricName
+}
+
+// IsVector reports whether the described metric is a metric vector.
+func (d MetricDesc) IsVector() bool {
+	return len(d.LabelNames) > 0
+}
+
+// Labels maps label names to label values of a single MetricVector value.
+type Labels map[string]string
+
+const (
+	ValidatorAddressLabel = "validator_address"
+	MonikerLabel          = "moniker"
+	ConfigLabel           = "config"
+	ContractAddressLabel  = "contract_address"
+)
+
+// validatorLabelNames is the label set of the metric vectors having a value per validator.
+var validatorLabelNames = []string{ValidatorAddressLabel, MonikerLabel}
+
+func validatorLabels(address, moniker string) Labels {
+	return Labels{
+		ValidatorAddressLabel: address,
+		MonikerLabel:          moniker,
+	}
+}
+
+// MetricVector is a set of values with the same label names, but different label values.
 type MetricVector struct {
-	values map[string]float64
+	kind       MetricKind
+	labelNames []string
+	// values are keyed by the label values joined in the labelNames order
+	values map[string]labeledValue
 	lock   sync.RWMutex
 }
 
-func (mv *MetricVector) Get(label string) float64 {
+type labeledValue struct {
+	labels Labels
+	value  float64
+	// decimal is the exact value set by SetDecimal, it's reset by Set and Add
+	decimal string
+}
+
+// key builds the values key. Labels not declared in labelNames are ignored, missing labels are treated as empty.
+func (mv *MetricVector) key(labels Labels) string {
+	values := make([]string, len(mv.labelNames))
+	for i, name := range mv.labelNames {
+		values[i] = labels[name]
+	}
+	return strings.Join(values, "\xff")
+}
+
+func (mv *MetricVector) normalize(labels Labels) Labels {
+	normalized := make(Labels, len(mv.labelNames))
+	for _, name := range mv.labelNames {
+		normalized[name] = labels[name]
+	}
+	return normalized
+}
+
+func (mv *MetricVector) Get(labels Labels) float64 {
 	mv.lock.RLock()
 	defer mv.lock.RUnlock()
-	return mv.values[label]
+	return mv.values[mv.key(labels)].value
 }
 
-func (mv *MetricVector) Set(label string, value float64) {
+func (mv *MetricVector) Set(labels Labels, value float64) {
 	mv.lock.Lock()
 	defer mv.lock.Unlock()
-	mv.values[label] = value
+	mv.values[mv.key(labels)] = labeledValue{labels: mv.normalize(labels), value: value}
 }
 
-func (mv *MetricVector) Add(label string, delta float64) {
+// Add changes the labeled value by delta, negative deltas are ignored for the counter vectors.
+func (mv *MetricVector) Add(labels Labels, delta float64) {
+	if mv.kind == CounterKind && delta < 0 {
+		return
+	}
 	mv.lock.Lock()
 	defer mv.lock.Unlock()
-	mv.values[label] += delta
+	key := mv.key(labels)
+	v, found := mv.values[key]
+	if !found {
+		v.labels = mv.normalize(labels)
+	}
+	v.value, v.decimal = v.value+delta, ""
+	mv.values[key] = v
 }
 
-func (mv *MetricVector) Labels() []string {
-	labels := make([]string, len(mv.values))
-	c := 0
-	for label := range mv.values {
-		labels[c] = label
-		c++
+// SetDecimal sets the labeled value keeping its exact decimal representation, f is the float64 approximation of d.
+func (mv *MetricVector) SetDecimal(labels Labels, d cosmostypes.Dec, f float64) {
+	v := labeledValue{labels: mv.normalize(labels), value: f}
+	if !d.IsNil() {
+		v.decimal = d.String()
+	}
+	mv.lock.Lock()
+	defer mv.lock.Unlock()
+	mv.values[mv.key(labels)] = v
+}
+
+// Decimal returns the exact labeled value set by SetDecimal or the shortest decimal representation
+// of the float64 value.
+func (mv *MetricVector) Decimal(labels Labels) string {
+	mv.lock.RLock()
+	defer mv.lock.RUnlock()
+	v := mv.values[mv.key(labels)]
+	if v.decimal != "" {
+		return v.decimal
+	}
+	return FormatFloat(v.value)
+}
+
+// Labels returns the label sets of all the vector values.
+func (mv *MetricVector) Labels() []Labels {
+	mv.lock.RLock()
+	defer mv.lock.RUnlock()
+	labels := make([]Labels, 0, len(mv.values))
+	for _, v := range mv.values {
+		labels = append(labels, v.labels)
 	}
 	return labels
 }
 
-func NewMetricVector() *MetricVector {
+// LabelNames returns the label names declared for the vector.
+func (mv *MetricVector) LabelNames() []string {
+	return mv.labelNames
+}
+
+// Kind returns the kind of the vector values.
+func (mv *MetricVector) Kind() MetricKind {
+	return mv.kind
+}
+
+func NewMetricVector(labelNames ...string) *MetricVector {
+	return newMetricVector(GaugeKind, labelNames)
+}
+
+// NewCounterVector creates a vector of counters. Its values are expected to be changed by Add with non-negative
+// deltas only.
+func NewCounterVector(labelNames ...string) *MetricVector {
+	return newMetricVector(CounterKind, labelNames)
+}
+
+// copy returns a copy of the vector with the same values.
+func (mv *MetricVector) copy() *MetricVector {
+	mv.lock.RLock()
+	defer mv.lock.RUnlock()
+	out := newMetricVector(mv.kind, mv.labelNames)
+	for key, v := range mv.values {
+		out.values[key] = v
+	}
+	return out
+}
+
+func newMetricVector(kind MetricKind, labelNames []string) *MetricVector {
 	return &MetricVector{
-		values: make(map[string]float64),
+		kind:       kind,
+		labelNames: labelNames,
+		values:     make(map[string]labeledValue),
 	}
 }
 
@@ -65,11 +231,43 @@ type MetricValue interface {
 	Get() float64
 	Set(float64)
 	Add(float64)
+	Kind() MetricKind
+}
+
+// DecimalMetricValue is implemented by the metric values keeping the exact decimal representation of the value,
+// which may be lost by the float64 conversion.
+type DecimalMetricValue interface {
+	Decimal() string
+}
+
+// FormatDecimal returns the exact decimal value of the metric, if it's kept, or the shortest decimal
+// representation of its float64 value.
+func FormatDecimal(v MetricValue) string {
+	if d, ok := v.(DecimalMetricValue); ok {
+		return d.Decimal()
+	}
+	return FormatFloat(v.Get())
+}
+
+// FormatFloat returns the shortest decimal representation of the value without an exponent.
+func FormatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// setDecimal sets the value of the metric keeping the exact decimal d if the metric supports it.
+func setDecimal(m MetricValue, d cosmostypes.Dec, value float64) {
+	if sm, ok := m.(*SimpleMetricValue); ok {
+		sm.SetDecimal(d, value)
+		return
+	}
+	m.Set(value)
 }
 
 type SimpleMetricValue struct {
 	value float64
-	lock  sync.Mutex
+	// decimal is the exact value set by SetDecimal, it's reset by Set and Add
+	decimal string
+	lock    sync.Mutex
 }
 
 func (b *SimpleMetricValue) Get() float64 {
@@ -81,69 +279,156 @@ func (b *SimpleMetricValue) Get() float64 {
 func (b *SimpleMetricValue) Set(f float64) {
 	b.lock.Lock()
 	defer b.lock.Unlock()
-	b.value = f
+	b.value, b.decimal = f, ""
 }
 
 func (b *SimpleMetricValue) Add(f float64) {
 	b.lock.Lock()
 	defer b.lock.Unlock()
-	b.value += f
+	b.value, b.decimal = b.value+f, ""
 }
 
-// ReadOnceMetric once value is read its sets to zero value
-// implemented specially for update global index bot monitor. We need to accumulate data in interval (last_check,current_check)
-// once value are read we set the value to zero value
-type ReadOnceMetric struct {
-	value float64
-	lock  sync.Mutex
+// SetDecimal sets the value keeping its exact decimal representation, f is the float64 approximation of d.
+func (b *SimpleMetricValue) SetDecimal(d cosmostypes.Dec, f float64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.value, b.decimal = f, ""
+	if !d.IsNil() {
+		b.decimal = d.String()
+	}
+}
+
+// Decimal returns the exact value set by SetDecimal or the shortest decimal representation of the float64 value.
+func (b *SimpleMetricValue) Decimal() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.decimal != "" {
+		return b.decimal
+	}
+	return FormatFloat(b.value)
 }
 
-func (a *ReadOnceMetric) Get() float64 {
-	a.lock.Lock()
-	defer a.lock.Unlock()
-	v := a.value
-	a.value = 0
-	return v
+func (b *SimpleMetricValue) Kind() MetricKind {
+	return GaugeKind
 }
 
-func (a *ReadOnceMetric) Set(f float64) {
-	a.lock.Lock()
-	defer a.lock.Unlock()
-	a.value = f
+// HistogramMetricValue counts the observed values in the buckets with the given upper bounds. Add observes a value.
+type HistogramMetricValue struct {
+	buckets []float64
+	// counts are the per bucket (non-cumulative) observations, the last one is the +Inf bucket
+	counts []uint64
+	count  uint64
+	sum    float64
+	lock   sync.Mutex
 }
 
-func (a *ReadOnceMetric) Add(f float64) {
-	a.lock.Lock()
-	defer a.lock.Unlock()
-	a.value += f
+// HistogramData is a copy of the HistogramMetricValue observations.
+type HistogramData struct {
+	Count uint64
+	Sum   float64
+	// Buckets maps the bucket upper bounds to the cumulative count of the observations
+	Buckets map[float64]uint64
 }
 
-func MustRunMonitor(ctx context.Context, m Monitor, tk *time.Ticker, logger *logrus.Logger) {
-	if tk == nil {
-		panic("you must to initialize ticker first")
+func NewHistogramMetricValue(buckets ...float64) *HistogramMetricValue {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &HistogramMetricValue{
+		buckets: sorted,
+		counts:  make([]uint64, len(sorted)+1),
 	}
-	for {
-		select {
-		case <-tk.C:
-			err := m.Handler(context.Background())
-			if err != nil {
-				logger.Errorf("failed to update %s data: %+v\n", m.Name(), err)
-			}
-		case <-ctx.Done():
-			return
-		}
+}
+
+// Get returns the sum of the observed values.
+func (h *HistogramMetricValue) Get() float64 {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.sum
+}
+
+// Set drops the observations, the value is ignored. It's used to initialize the histogram.
+func (h *HistogramMetricValue) Set(float64) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.counts = make([]uint64, len(h.buckets)+1)
+	h.count = 0
+	h.sum = 0
+}
+
+// Add observes the value.
+func (h *HistogramMetricValue) Add(f float64) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.counts[sort.SearchFloat64s(h.buckets, f)]++
+	h.count++
+	h.sum += f
+}
+
+func (h *HistogramMetricValue) Kind() MetricKind {
+	return HistogramKind
+}
+
+func (h *HistogramMetricValue) Histogram() HistogramData {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	data := HistogramData{
+		Count:   h.count,
+		Sum:     h.sum,
+		Buckets: make(map[float64]uint64, len(h.buckets)),
+	}
+	var cumulative uint64
+	for i, bound := range h.buckets {
+		cumulative += h.counts[i]
+		data.Buckets[bound] = cumulative
+	}
+	return data
+}
+
+// CounterMetricValue is a monotonically increasing value. Unlike the "since last check" values it's never reset
+// on read, so any number of readers observe the same value and compute the deltas with increase() or rate().
+type CounterMetricValue struct {
+	value float64
+	lock  sync.Mutex
+}
+
+func (c *CounterMetricValue) Get() float64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.value
+}
+
+// Set is used to initialize the counter, the regular updates should use Add.
+func (c *CounterMetricValue) Set(f float64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.value = f
+}
+
+// Add increases the counter, negative deltas are ignored.
+func (c *CounterMetricValue) Add(f float64) {
+	if f < 0 {
+		return
 	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.value += f
 }
 
-func initMetrics(providedMetrics []MetricName, providedMetricVectors []MetricName, metrics map[MetricName]MetricValue, vectors map[MetricName]*MetricVector) {
+func (c *CounterMetricValue) Kind() MetricKind {
+	return CounterKind
+}
+
+// initMetrics sets the metrics to zero values and creates empty metric vectors.
+// providedMetricVectors maps the metric vectors to their label names.
+func initMetrics(providedMetrics []MetricName, providedMetricVectors map[MetricName][]string, metrics map[MetricName]MetricValue, vectors map[MetricName]*MetricVector) {
 	for _, metric := range providedMetrics {
 		if metrics[metric] == nil {
 			metrics[metric] = &SimpleMetricValue{}
 		}
 		metrics[metric].Set(0)
 	}
-	for _, metric := range providedMetricVectors {
-		vectors[metric] = NewMetricVector()
+	for metric, labelNames := range providedMetricVectors {
+		vectors[metric] = NewMetricVector(labelNames...)
 	}
 }
 
@@ -155,9 +440,6 @@ func copyMetrics(src, dst map[MetricName]MetricValue) {
 
 func copyVectors(src, dst map[MetricName]*MetricVector) {
 	for metricVector, vector := range src {
-		dst[metricVector] = NewMetricVector()
-		for _, label := range vector.Labels() {
-			dst[metricVector].Set(label, vector.Get(label))
-		}
+		dst[metricVector] = vector.copy()
 	}
 }
@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/query"
@@ -27,11 +27,12 @@ type OracleParamsMonitor struct {
 func NewOracleParamsMonitor(
 	cfg config.CollectorConfig,
 	logger *logrus.Logger,
+	apiClient *client.TerraRESTApis,
 ) *OracleParamsMonitor {
 	m := &OracleParamsMonitor{
 		metrics:   make(map[MetricName]MetricValue),
 		logger:    logger,
-		apiClient: utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient: apiClient,
 	}
 
 	m.InitMetrics()
@@ -52,6 +53,12 @@ func (s *OracleParamsMonitor) Name() string {
 	return "OracleParamsMonitor"
 }
 
+func (s *OracleParamsMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{Name: OracleMissedVotesWindow, Help: "Slash window of the oracle module.", Unit: "blocks"},
+	}
+}
+
 func (s *OracleParamsMonitor) Handler(ctx context.Context) error {
 	resp, err := s.apiClient.Query.OracleParams(
 		&query.OracleParamsParams{
@@ -62,7 +69,7 @@ func (s *OracleParamsMonitor) Handler(ctx context.Context) error {
 		return fmt.Errorf("failed to get oracle params: %w", err)
 	}
 
-	err = resp.GetPayload().Validate(nil)
+	err = source.ValidatePayload(ctx, resp.GetPayload())
 	if err != nil {
 		return fmt.Errorf("failed to validate oracle params: %w", err)
 	}
@@ -7,7 +7,8 @@ import (
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
+	"github.com/lidofinance/terra-monitors/internal/pkg/workerpool"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/oracle"
@@ -17,7 +18,8 @@ import (
 )
 
 const (
-	OracleMissedVoteRate MetricName = "oracle_missed_votes_rate"
+	OracleMissedVoteRate      MetricName = "oracle_missed_votes_rate"
+	OracleNumFailedValidators MetricName = "oracle_num_failed_validators"
 )
 
 type OracleVotesMonitor struct {
@@ -25,6 +27,7 @@ type OracleVotesMonitor struct {
 	metricVectors        map[MetricName]*MetricVector
 	apiClient            *client.TerraRESTApis
 	validatorsRepository repositories.ValidatorsRepository
+	fetchConcurrency     int
 	logger               *logrus.Logger
 	lock                 sync.RWMutex
 }
@@ -32,13 +35,15 @@ type OracleVotesMonitor struct {
 func NewOracleVotesMonitor(
 	cfg config.CollectorConfig,
 	logger *logrus.Logger,
+	apiClient *client.TerraRESTApis,
 	repository repositories.ValidatorsRepository,
 ) *OracleVotesMonitor {
 	m := OracleVotesMonitor{
 		metrics:              make(map[MetricName]MetricValue),
 		metricVectors:        make(map[MetricName]*MetricVector),
-		apiClient:            utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient:            apiClient,
 		validatorsRepository: repository,
+		fetchConcurrency:     cfg.FetchConcurrency,
 		logger:               logger,
 	}
 	m.InitMetrics()
@@ -49,14 +54,38 @@ func (m *OracleVotesMonitor) Name() string {
 	return "OracleVotesMonitor"
 }
 
+func (m *OracleVotesMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{
+			Name:       OracleMissedVoteRate,
+			Help:       "Rate of the oracle vote periods missed by the validator in the current slash window.",
+			LabelNames: validatorLabelNames,
+		},
+		{Name: OracleNumFailedValidators, Help: "Number of the whitelisted validators failed to fetch in the last run."},
+	}
+}
+
+func (m *OracleVotesMonitor) providedMetricVectors() map[MetricName][]string {
+	return map[MetricName][]string{
+		OracleMissedVoteRate: validatorLabelNames,
+	}
+}
+
+func (m *OracleVotesMonitor) providedMetrics() []MetricName {
+	return []MetricName{
+		OracleNumFailedValidators,
+	}
+}
+
 func (m *OracleVotesMonitor) InitMetrics() {
-	initMetrics([]MetricName{}, []MetricName{OracleMissedVoteRate}, m.metrics, m.metricVectors)
+	initMetrics(m.providedMetrics(), m.providedMetricVectors(), m.metrics, m.metricVectors)
 }
 
 func (m *OracleVotesMonitor) Handler(ctx context.Context) error {
 	// tmp* for 2stage nonblocking update data
+	tmpMetrics := make(map[MetricName]MetricValue)
 	tmpMetricVectors := make(map[MetricName]*MetricVector)
-	initMetrics(nil, []MetricName{OracleMissedVoteRate}, nil, tmpMetricVectors)
+	initMetrics(m.providedMetrics(), m.providedMetricVectors(), tmpMetrics, tmpMetricVectors)
 
 	validatorsAddresses, err := m.validatorsRepository.GetValidatorsAddresses(ctx)
 	if err != nil {
@@ -68,13 +97,49 @@ func (m *OracleVotesMonitor) Handler(ctx context.Context) error {
 		return fmt.Errorf("failed to get oracle parameters: %w", err)
 	}
 
-	if err := oracleParamsResponse.GetPayload().Validate(nil); err != nil {
+	if err := source.ValidatePayload(ctx, oracleParamsResponse.GetPayload()); err != nil {
 		return fmt.Errorf("failed to validate OracleParamsResponse: %w", err)
 	}
 
 	oracleParams := oracleParamsResponse.GetPayload().Result
 
-	for _, validatorAddress := range validatorsAddresses {
+	// Every validator must vote during every params.VotePeriod
+	// If during every SlashWindow a validator sends fewer votes than params.VoteThreshold votes he will be slashed.
+
+	// We know params.SlashWindow, params.VotePeriod and the number of vote periods a validator missed
+	// in this oracle slash window, so:
+	// votePeriodsPerWindow = params.SlashWindow / params.VotePeriod
+	// missedVotesRate = (missedPeriods / votePeriodsPerWindow) * 100%
+	// If missedVotesRate greater than (100% - params.VoteThreshold) validator will be slashed
+	// More info: https://docs.terra.money/dev/spec-oracle.html#slashing
+	slashWindow, err := cosmostypes.NewDecFromStr(oracleParams.SlashWindow)
+	if err != nil {
+		return fmt.Errorf("failed to parse SlashWindow: %w", err)
+	}
+
+	slashWindowValue, err := slashWindow.Float64()
+	if err != nil {
+		return fmt.Errorf("failed to parse slashWindow: %w", err)
+	}
+
+	votePeriod, err := cosmostypes.NewDecFromStr(oracleParams.VotePeriod)
+	if err != nil {
+		return fmt.Errorf("failed to parse VotePeriod: %w", err)
+	}
+
+	votePeriodValue, err := votePeriod.Float64()
+	if err != nil {
+		return fmt.Errorf("failed to parse votePeriod: %w", err)
+	}
+
+	votePeriodsPerSlashWindow := slashWindowValue / votePeriodValue
+
+	monikers := make([]string, len(validatorsAddresses))
+	missedVotesRates := make([]float64, len(validatorsAddresses))
+	// missedVotesDecimals are the exact rates, they're left nil if the slash window is zero
+	missedVotesDecimals := make([]cosmostypes.Dec, len(validatorsAddresses))
+	result := workerpool.Run(ctx, m.fetchConcurrency, len(validatorsAddresses), func(ctx context.Context, i int) error {
+		validatorAddress := validatorsAddresses[i]
 		validatorInfo, err := m.validatorsRepository.GetValidatorInfo(ctx, validatorAddress)
 		if err != nil {
 			return fmt.Errorf("failed to GetValidatorInfo: %w", err)
@@ -87,7 +152,7 @@ func (m *OracleVotesMonitor) Handler(ctx context.Context) error {
 			return fmt.Errorf("failed to get missed vote periods: %w", err)
 		}
 
-		if err := missedVotePeriodsResponse.GetPayload().Validate(nil); err != nil {
+		if err := source.ValidatePayload(ctx, missedVotePeriodsResponse.GetPayload()); err != nil {
 			return fmt.Errorf("failed to validate missedVotePeriodsResponse: %w", err)
 		}
 
@@ -101,44 +166,34 @@ func (m *OracleVotesMonitor) Handler(ctx context.Context) error {
 			return fmt.Errorf("failed to parse oracleMissedVotePeriods: %w", err)
 		}
 
-		// Every validator must vote during every params.VotePeriod
-		// If during every SlashWindow a validator sends fewer votes than params.VoteThreshold votes he will be slashed.
-
-		// We know params.SlashWindow, params.VotePeriod and the number of vote periods a validator missed
-		// in this oracle slash window, so:
-		// votePeriodsPerWindow = params.SlashWindow / params.VotePeriod
-		// missedVotesRate = (missedPeriods / votePeriodsPerWindow) * 100%
-		// If missedVotesRate greater than (100% - params.VoteThreshold) validator will be slashed
-		// More info: https://docs.terra.money/dev/spec-oracle.html#slashing
-		slashWindow, err := cosmostypes.NewDecFromStr(oracleParams.SlashWindow)
-		if err != nil {
-			return fmt.Errorf("failed to parse SlashWindow: %w", err)
-		}
-
-		slashWindowValue, err := slashWindow.Float64()
-		if err != nil {
-			return fmt.Errorf("failed to parse slashWindow: %w", err)
+		monikers[i] = validatorInfo.Moniker
+		missedVotesRates[i] = oracleMissedVotePeriodsValue / votePeriodsPerSlashWindow
+		if !slashWindow.IsZero() {
+			missedVotesDecimals[i] = oracleMissedVotePeriods.Mul(votePeriod).Quo(slashWindow)
 		}
+		return nil
+	})
+	if result.AllFailed() {
+		return fmt.Errorf("failed to get validators missed votes: %w", result.Err())
+	}
 
-		votePeriod, err := cosmostypes.NewDecFromStr(oracleParams.VotePeriod)
-		if err != nil {
-			return fmt.Errorf("failed to parse VotePeriod: %w", err)
+	for i, validatorAddress := range validatorsAddresses {
+		if err, failed := result.Errors[i]; failed {
+			m.logger.Errorf("failed to get validator %s missed votes: %v", validatorAddress, err)
+			continue
 		}
-
-		votePeriodValue, err := votePeriod.Float64()
-		if err != nil {
-			return fmt.Errorf("failed to parse votePeriod: %w", err)
-		}
-
-		votePeriodsPerSlashWindow := slashWindowValue / votePeriodValue
-		missedVotesRate := oracleMissedVotePeriodsValue / votePeriodsPerSlashWindow
-
-		tmpMetricVectors[OracleMissedVoteRate].Set(validatorInfo.Moniker, missedVotesRate)
+		tmpMetricVectors[OracleMissedVoteRate].SetDecimal(
+			validatorLabels(validatorAddress, monikers[i]),
+			missedVotesDecimals[i],
+			missedVotesRates[i],
+		)
 	}
+	tmpMetrics[OracleNumFailedValidators].Set(float64(result.Failed()))
 	m.logger.Infoln("Oracle missed votes updated", m.Name())
 
 	m.lock.Lock()
 	defer m.lock.Unlock()
+	copyMetrics(tmpMetrics, m.metrics)
 	copyVectors(tmpMetricVectors, m.metricVectors)
 
 	return nil
@@ -8,6 +8,7 @@ import (
 	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
 	"github.com/lidofinance/terra-monitors/internal/app/collector/types"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
 	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
 	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
@@ -48,21 +49,22 @@ func (suite *OracleVotesMonitorTestSuite) testSuccessfulRequest(networkGeneratio
 	cfg.BassetContractsVersion = config.V1Contracts
 	cfg.NetworkGeneration = networkGenerations
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
 
-	m := NewOracleVotesMonitor(cfg, logger, valRepository)
+	m := NewOracleVotesMonitor(cfg, logger, apiClient, valRepository)
 	err = m.Handler(context.Background())
 	suite.NoError(err)
 
 	metricVectors := m.GetMetricVectors()
 
 	expectedValidatorsCommission := 0.1
-	actualValidatorsCommission := metricVectors[OracleMissedVoteRate].Get(types.TestMoniker)
+	actualValidatorsCommission := metricVectors[OracleMissedVoteRate].Get(validatorLabels(types.TestValAddress, types.TestMoniker))
 
 	suite.Equal(expectedValidatorsCommission, actualValidatorsCommission)
+	suite.Equal("0.100000000000000000", metricVectors[OracleMissedVoteRate].Decimal(validatorLabels(types.TestValAddress, types.TestMoniker)))
 }
 
 func (suite *OracleVotesMonitorTestSuite) TestFailedValidatorsFeeRequest() {
@@ -87,12 +89,12 @@ func (suite *OracleVotesMonitorTestSuite) TestFailedValidatorsFeeRequest() {
 	cfg.BassetContractsVersion = config.V1Contracts
 	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
 
-	m := NewOracleVotesMonitor(cfg, logger, valRepository)
+	m := NewOracleVotesMonitor(cfg, logger, apiClient, valRepository)
 	err = m.Handler(context.Background())
 	suite.Error(err)
 }
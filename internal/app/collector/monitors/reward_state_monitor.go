@@ -7,7 +7,6 @@ import (
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/types"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/wasm"
@@ -20,12 +19,12 @@ var (
 	GlobalIndex MetricName = "global_index"
 )
 
-func NewRewardStateMonitor(cfg config.CollectorConfig, logger *logrus.Logger) RewardStateMonitor {
+func NewRewardStateMonitor(cfg config.CollectorConfig, logger *logrus.Logger, apiClient *client.TerraRESTApis) RewardStateMonitor {
 	m := RewardStateMonitor{
 		State:           &types.RewardStateResponse{},
 		ContractAddress: cfg.Addresses.RewardContract,
 		metrics:         make(map[MetricName]MetricValue),
-		apiClient:       utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient:       apiClient,
 		logger:          logger,
 	}
 	m.InitMetrics()
@@ -45,6 +44,12 @@ func (h RewardStateMonitor) Name() string {
 	return "RewardState"
 }
 
+func (h *RewardStateMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{Name: GlobalIndex, Help: "Global index of the reward contract."},
+	}
+}
+
 func (h *RewardStateMonitor) InitMetrics() {
 	h.setStringMetric(GlobalIndex, "0")
 }
@@ -96,7 +101,7 @@ func (h *RewardStateMonitor) setStringMetric(m MetricName, rawValue string) {
 		h.logger.Errorf("failed to get float64 value from string \"%s\" for metric \"%s\": %+v\n", rawValue, m, err)
 	}
 
-	h.metrics[m].Set(value)
+	setDecimal(h.metrics[m], v, value)
 }
 
 func (h RewardStateMonitor) GetMetrics() map[MetricName]MetricValue {
@@ -7,7 +7,9 @@ import (
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/workerpool"
 
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-repositories/signinfo"
 	"github.com/lidofinance/terra-repositories/validators"
 
@@ -18,13 +20,15 @@ const (
 	SlashingNumJailedValidators     MetricName = "slashing_num_jailed_validators"
 	SlashingNumTombstonedValidators MetricName = "slashing_num_tombstoned_validators"
 	SlashingNumMissedBlocks         MetricName = "slashing_num_missed_blocks"
+	SlashingNumFailedValidators     MetricName = "slashing_num_failed_validators"
 )
 
 type SlashingMonitor struct {
 	metrics              map[MetricName]MetricValue
 	metricVectors        map[MetricName]*MetricVector
+	apiClient            *client.TerraRESTApis
 	validatorsRepository repositories.ValidatorsRepository
-	signInfoRepository   *signinfo.Repository
+	fetchConcurrency     int
 	logger               *logrus.Logger
 	lock                 sync.RWMutex
 }
@@ -32,14 +36,15 @@ type SlashingMonitor struct {
 func NewSlashingMonitor(
 	cfg config.CollectorConfig,
 	logger *logrus.Logger,
+	apiClient *client.TerraRESTApis,
 	repository repositories.ValidatorsRepository,
-	signInfoRepository *signinfo.Repository,
 ) *SlashingMonitor {
 	m := &SlashingMonitor{
 		metrics:              make(map[MetricName]MetricValue),
 		metricVectors:        make(map[MetricName]*MetricVector),
+		apiClient:            apiClient,
 		validatorsRepository: repository,
-		signInfoRepository:   signInfoRepository,
+		fetchConcurrency:     cfg.FetchConcurrency,
 		logger:               logger,
 		lock:                 sync.RWMutex{},
 	}
@@ -53,16 +58,31 @@ func (m *SlashingMonitor) Name() string {
 	return "Slashing"
 }
 
+func (m *SlashingMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{Name: SlashingNumJailedValidators, Help: "Number of the jailed whitelisted validators."},
+		{Name: SlashingNumTombstonedValidators, Help: "Number of the tombstoned whitelisted validators."},
+		{Name: SlashingNumFailedValidators, Help: "Number of the whitelisted validators failed to fetch in the last run."},
+		{
+			Name:       SlashingNumMissedBlocks,
+			Help:       "Number of the blocks missed by the validator in the current signed blocks window.",
+			Unit:       "blocks",
+			LabelNames: validatorLabelNames,
+		},
+	}
+}
+
 func (m *SlashingMonitor) providedMetrics() []MetricName {
 	return []MetricName{
 		SlashingNumJailedValidators,
 		SlashingNumTombstonedValidators,
+		SlashingNumFailedValidators,
 	}
 }
 
-func (m *SlashingMonitor) providedMetricVectors() []MetricName {
-	return []MetricName{
-		SlashingNumMissedBlocks,
+func (m *SlashingMonitor) providedMetricVectors() map[MetricName][]string {
+	return map[MetricName][]string{
+		SlashingNumMissedBlocks: validatorLabelNames,
 	}
 }
 
@@ -76,27 +96,42 @@ func (m *SlashingMonitor) Handler(ctx context.Context) error {
 	tmpMetricVectors := make(map[MetricName]*MetricVector)
 	initMetrics(m.providedMetrics(), m.providedMetricVectors(), tmpMetrics, tmpMetricVectors)
 
-	validatorsInfo, err := getValidatorsInfo(ctx, m.validatorsRepository)
+	validatorsInfo, fetched, err := getValidatorsInfo(ctx, m.logger, m.validatorsRepository, m.fetchConcurrency)
 	if err != nil {
 		return fmt.Errorf("failed to getValidatorsInfo: %w", err)
 	}
 
-	for _, validatorInfo := range validatorsInfo {
-		if err := m.signInfoRepository.Init(ctx, validatorInfo.PubKey); err != nil {
-			m.logger.Errorf("failed to init signInfo repository for validator %s: %s", validatorInfo.Address, err)
+	// every task uses its own signInfo repository, since the repository keeps the last fetched signing info
+	signInfos := make([]*signinfo.Repository, len(validatorsInfo))
+	signInfoFetched := workerpool.Run(ctx, m.fetchConcurrency, len(validatorsInfo), func(ctx context.Context, i int) error {
+		signInfo := signinfo.New(m.apiClient)
+		if err := signInfo.Init(ctx, validatorsInfo[i].PubKey); err != nil {
+			return err
+		}
+		signInfos[i] = signInfo
+		return nil
+	})
+	for i, err := range signInfoFetched.Errors {
+		m.logger.Errorf("failed to init signInfo repository for validator %s: %s", validatorsInfo[i].Address, err)
+	}
+	tmpMetrics[SlashingNumFailedValidators].Set(float64(fetched.Failed() + signInfoFetched.Failed()))
+
+	for i, validatorInfo := range validatorsInfo {
+		signInfo := signInfos[i]
+		if signInfo == nil {
 			continue
 		}
 
-		missedBlocks, err := m.signInfoRepository.GetMissedBlockCounter()
+		missedBlocks, err := signInfo.GetMissedBlockCounter()
 		if err != nil {
 			m.logger.Errorf("failed to Parse `missed_blocks_counter:`: %v", err)
 		} else {
-			tmpMetricVectors[SlashingNumMissedBlocks].Add(validatorInfo.Moniker, missedBlocks)
+			tmpMetricVectors[SlashingNumMissedBlocks].Add(validatorLabels(validatorInfo.Address, validatorInfo.Moniker), missedBlocks)
 		}
 		if validatorInfo.Jailed {
 			tmpMetrics[SlashingNumJailedValidators].Add(1)
 		}
-		if m.signInfoRepository.GetTombstoned() {
+		if signInfo.GetTombstoned() {
 			tmpMetrics[SlashingNumTombstonedValidators].Add(1)
 		}
 	}
@@ -122,23 +157,43 @@ func (m *SlashingMonitor) GetMetricVectors() map[MetricName]*MetricVector {
 	return m.metricVectors
 }
 
-func getValidatorsInfo(ctx context.Context, validatorsRepository repositories.ValidatorsRepository) ([]validators.ValidatorInfo, error) {
+// getValidatorsInfo fetches the info of the whitelisted validators with at most concurrency requests at once.
+// The validators failed to fetch are logged, skipped and counted by the returned result. The error is returned
+// if the whitelist can't be fetched or none of the validators info is fetched.
+func getValidatorsInfo(
+	ctx context.Context,
+	logger *logrus.Logger,
+	validatorsRepository repositories.ValidatorsRepository,
+	concurrency int,
+) ([]validators.ValidatorInfo, workerpool.Result, error) {
 	validatorsAddresses, err := validatorsRepository.GetValidatorsAddresses(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to getWhitelistedValidatorsAddresses: %w", err)
+		return nil, workerpool.Result{}, fmt.Errorf("failed to getWhitelistedValidatorsAddresses: %w", err)
 	}
 
 	// For each validator address, get the consensus public key (which is required to
 	// later get the signing info).
-	var validatorsInfo []validators.ValidatorInfo
-	for _, validatorAddress := range validatorsAddresses {
-		validatorInfo, err := validatorsRepository.GetValidatorInfo(ctx, validatorAddress)
+	fetchedInfo := make([]validators.ValidatorInfo, len(validatorsAddresses))
+	result := workerpool.Run(ctx, concurrency, len(validatorsAddresses), func(ctx context.Context, i int) error {
+		validatorInfo, err := validatorsRepository.GetValidatorInfo(ctx, validatorsAddresses[i])
 		if err != nil {
-			return nil, fmt.Errorf("failed to get validator info: %w", err)
+			return err
 		}
-
-		validatorsInfo = append(validatorsInfo, validatorInfo)
+		fetchedInfo[i] = validatorInfo
+		return nil
+	})
+	if result.AllFailed() {
+		return nil, result, fmt.Errorf("failed to get validators info: %w", result.Err())
+	}
+	for i, err := range result.Errors {
+		logger.Errorf("failed to get validator %s info: %v", validatorsAddresses[i], err)
 	}
 
-	return validatorsInfo, nil
+	validatorsInfo := make([]validators.ValidatorInfo, 0, len(validatorsAddresses)-result.Failed())
+	for i := range validatorsAddresses {
+		if _, failed := result.Errors[i]; !failed {
+			validatorsInfo = append(validatorsInfo, fetchedInfo[i])
+		}
+	}
+	return validatorsInfo, result, nil
 }
@@ -8,11 +8,10 @@ import (
 	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
 	"github.com/lidofinance/terra-monitors/internal/app/collector/types"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
 	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
 	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
-	"github.com/lidofinance/terra-repositories/signinfo"
-
 	"github.com/stretchr/testify/suite"
 )
 
@@ -59,13 +58,12 @@ func (suite *SlashingMonitorTestSuite) testSuccessfulRequestWithSlashing(network
 	cfg.BassetContractsVersion = config.V1Contracts
 	cfg.NetworkGeneration = networkGeneration
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
-	signInfoRepository := signinfo.New(apiClient)
 
-	m := NewSlashingMonitor(cfg, logger, valRepository, signInfoRepository)
+	m := NewSlashingMonitor(cfg, logger, apiClient, valRepository)
 	err = m.Handler(context.Background())
 	suite.NoError(err)
 
@@ -120,13 +118,12 @@ func (suite *SlashingMonitorTestSuite) testSuccessfulRequestNoSlashing(networkGe
 	cfg.BassetContractsVersion = config.V1Contracts
 	cfg.NetworkGeneration = networkGeneration
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
-	signInfoRepository := signinfo.New(apiClient)
 
-	m := NewSlashingMonitor(cfg, logger, valRepository, signInfoRepository)
+	m := NewSlashingMonitor(cfg, logger, apiClient, valRepository)
 	err = m.Handler(context.Background())
 	suite.NoError(err)
 
@@ -182,13 +179,12 @@ func (suite *UpdateGlobalIndexMonitorTestSuite) testFailedSlashingRequest(networ
 	cfg.BassetContractsVersion = config.V1Contracts
 	cfg.NetworkGeneration = networkGeneration
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
-	signInfoRepository := signinfo.New(apiClient)
 
-	m := NewSlashingMonitor(cfg, logger, valRepository, signInfoRepository)
+	m := NewSlashingMonitor(cfg, logger, apiClient, valRepository)
 	err = m.Handler(context.Background())
 	suite.Error(err)
 
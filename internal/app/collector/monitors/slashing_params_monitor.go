@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/query"
@@ -27,11 +27,12 @@ type SlashingParamsMonitor struct {
 func NewSlashingParamsMonitor(
 	cfg config.CollectorConfig,
 	logger *logrus.Logger,
+	apiClient *client.TerraRESTApis,
 ) *SlashingParamsMonitor {
 	m := &SlashingParamsMonitor{
 		metrics:   make(map[MetricName]MetricValue),
 		logger:    logger,
-		apiClient: utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient: apiClient,
 	}
 
 	m.InitMetrics()
@@ -52,6 +53,12 @@ func (s *SlashingParamsMonitor) Name() string {
 	return "SlashingParamsMonitor"
 }
 
+func (s *SlashingParamsMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{Name: SlashingSignedBlocksWindow, Help: "Signed blocks window of the slashing module.", Unit: "blocks"},
+	}
+}
+
 func (s *SlashingParamsMonitor) Handler(ctx context.Context) error {
 	resp, err := s.apiClient.Query.SlashingParams(
 		&query.SlashingParamsParams{
@@ -62,7 +69,7 @@ func (s *SlashingParamsMonitor) Handler(ctx context.Context) error {
 		return fmt.Errorf("failed to get slashing params: %w", err)
 	}
 
-	err = resp.GetPayload().Validate(nil)
+	err = source.ValidatePayload(ctx, resp.GetPayload())
 	if err != nil {
 		return fmt.Errorf("failed to validate slashing params: %w", err)
 	}
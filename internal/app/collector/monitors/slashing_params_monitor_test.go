@@ -2,6 +2,7 @@ package monitors
 
 import (
 	"context"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
 	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
 	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 	"github.com/stretchr/testify/suite"
@@ -27,7 +28,8 @@ func (suite *SlashingParamsMonitorTestSuite) TestSlashingParamsSuccessful() {
 	cfg := stubs.NewTestCollectorConfig(ts.URL)
 
 	logger := stubs.NewTestLogger()
-	slashingParamsMonitor := NewSlashingParamsMonitor(cfg, logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	slashingParamsMonitor := NewSlashingParamsMonitor(cfg, logger, apiClient)
 
 	err = slashingParamsMonitor.Handler(context.Background())
 	suite.NoError(err)
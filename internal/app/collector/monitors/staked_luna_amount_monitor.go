@@ -8,7 +8,6 @@ import (
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/staking"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
 	cosmostypes "github.com/cosmos/cosmos-sdk/types"
 	"github.com/sirupsen/logrus"
@@ -25,10 +24,10 @@ type StakedLunaAmountMonitor struct {
 	lock      sync.RWMutex
 }
 
-func NewStakedLunaAmountMonitor(cfg config.CollectorConfig, logger *logrus.Logger) *StakedLunaAmountMonitor {
+func NewStakedLunaAmountMonitor(cfg config.CollectorConfig, logger *logrus.Logger, apiClient *client.TerraRESTApis) *StakedLunaAmountMonitor {
 	m := StakedLunaAmountMonitor{
 		metrics:   make(map[MetricName]MetricValue),
-		apiClient: utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient: apiClient,
 		logger:    logger,
 		lock:      sync.RWMutex{},
 	}
@@ -40,6 +39,12 @@ func (m *StakedLunaAmountMonitor) Name() string {
 	return "StakedLunaAmount"
 }
 
+func (m *StakedLunaAmountMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{Name: stakedLunaAmount, Help: "Amount of Luna bonded in the network staking pool.", Unit: "uluna"},
+	}
+}
+
 func (m *StakedLunaAmountMonitor) providedMetrics() []MetricName {
 	return []MetricName{
 		stakedLunaAmount,
@@ -83,7 +88,7 @@ func (m *StakedLunaAmountMonitor) setStringMetric(metric MetricName, rawValue st
 		m.metrics[metric] = &SimpleMetricValue{}
 	}
 
-	m.metrics[metric].Set(value)
+	setDecimal(m.metrics[metric], v, value)
 }
 
 func (m *StakedLunaAmountMonitor) GetMetrics() map[MetricName]MetricValue {
@@ -0,0 +1,66 @@
+package monitors
+
+import (
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/state"
+
+	"github.com/sirupsen/logrus"
+)
+
+// monitorState loads and saves the state of a monitor kept across the restarts, e.g. the last checked block
+// and the accumulated counters. The state is not kept if the store is nil.
+type monitorState struct {
+	store  *state.Store
+	key    string
+	logger *logrus.Logger
+}
+
+// newMonitorState creates the state of the monitor, the monitors of every deployment have their own states.
+func newMonitorState(store *state.Store, cfg config.CollectorConfig, monitor string, logger *logrus.Logger) monitorState {
+	return monitorState{store: store, key: cfg.Deployment + "/" + monitor, logger: logger}
+}
+
+// load decodes the saved state to v and reports whether it's restored. The state failed to decode is logged
+// and ignored, so the monitor starts from scratch.
+func (s monitorState) load(v interface{}) bool {
+	if s.store == nil {
+		return false
+	}
+	found, err := s.store.Load(s.key, v)
+	if err != nil {
+		s.logger.Errorf("failed to restore %s state: %v", s.key, err)
+		return false
+	}
+	return found
+}
+
+// save saves the state, the errors are logged, since the monitor data is updated anyway.
+func (s monitorState) save(v interface{}) {
+	if s.store == nil {
+		return
+	}
+	if err := s.store.Save(s.key, v); err != nil {
+		s.logger.Errorf("failed to save %s state: %v", s.key, err)
+	}
+}
+
+// vectorValue is a saved value of a metric vector.
+type vectorValue struct {
+	Labels Labels  `json:"labels"`
+	Value  float64 `json:"value"`
+}
+
+func vectorValues(mv *MetricVector) []vectorValue {
+	labels := mv.Labels()
+	values := make([]vectorValue, 0, len(labels))
+	for _, l := range labels {
+		values = append(values, vectorValue{Labels: l, Value: mv.Get(l)})
+	}
+	return values
+}
+
+func restoreVector(mv *MetricVector, values []vectorValue) {
+	for _, v := range values {
+		mv.Set(v.Labels, v.Value)
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"sync"
 
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
+	"github.com/lidofinance/terra-monitors/internal/pkg/state"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/transactions"
@@ -28,14 +28,18 @@ const UpdateGlobalIndexMsg = "update_global_index"
 const UpdateGlobalIndexBase64Encoded = "eyJ1cGRhdGVfZ2xvYmFsX2luZGV4Ijp7fX0="
 
 const (
-	UpdateGlobalIndexSuccessfulTxSinceLastCheck MetricName = "update_global_index_successful_tx_since_last_check"
-	UpdateGlobalIndexFailedTxSinceLastCheck     MetricName = "update_global_index_failed_tx_since_last_check"
-	UpdateGlobalIndexGasWanted                  MetricName = "update_global_index_gas_wanted"
-	UpdateGlobalIndexGasUsed                    MetricName = "update_global_index_gas_used"
-	UpdateGlobalIndexUUSDFee                    MetricName = "update_global_index_uusd_fee"
+	UpdateGlobalIndexSuccessfulTxTotal MetricName = "update_global_index_successful_tx_total"
+	UpdateGlobalIndexFailedTxTotal     MetricName = "update_global_index_failed_tx_total"
+	UpdateGlobalIndexGasWantedTotal    MetricName = "update_global_index_gas_wanted_total"
+	UpdateGlobalIndexGasUsedTotal      MetricName = "update_global_index_gas_used_total"
+	UpdateGlobalIndexUUSDFeeTotal      MetricName = "update_global_index_uusd_fee_total"
 )
 
-const threshold int = 10
+// updateGlobalIndexState is the UpdateGlobalIndexMonitor state kept across the restarts.
+type updateGlobalIndexState struct {
+	LastMaxCheckedID int64                  `json:"last_max_checked_id"`
+	Counters         map[MetricName]float64 `json:"counters"`
+}
 
 type UpdateGlobalIndexMonitor struct {
 	ContractAddress   string
@@ -45,18 +49,30 @@ type UpdateGlobalIndexMonitor struct {
 	lastMaxCheckedID  int64
 	lock              sync.RWMutex
 	networkGeneration string
+
+	// threshold limits the transactions pages fetched by a run
+	threshold int
+	state     monitorState
 }
 
-func NewUpdateGlobalIndexMonitor(cfg config.CollectorConfig, logger *logrus.Logger) *UpdateGlobalIndexMonitor {
+func NewUpdateGlobalIndexMonitor(
+	cfg config.CollectorConfig,
+	logger *logrus.Logger,
+	apiClient *client.TerraRESTApis,
+	store *state.Store,
+) *UpdateGlobalIndexMonitor {
 	m := UpdateGlobalIndexMonitor{
 		ContractAddress:   cfg.Addresses.UpdateGlobalIndexBotAddress,
 		metrics:           make(map[MetricName]MetricValue),
-		apiClient:         utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient:         apiClient,
 		logger:            logger,
+		threshold:         cfg.State.MaxBackfillTxPages,
 		lock:              sync.RWMutex{},
 		networkGeneration: cfg.NetworkGeneration,
 	}
+	m.state = newMonitorState(store, cfg, m.Name(), logger)
 	m.InitMetrics()
+	m.restoreState()
 
 	return &m
 }
@@ -65,25 +81,85 @@ func (m *UpdateGlobalIndexMonitor) Name() string {
 	return "UpdateGlobalIndexMonitor"
 }
 
+func (m *UpdateGlobalIndexMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{
+			Name: UpdateGlobalIndexSuccessfulTxTotal,
+			Help: "Number of the successful update_global_index transactions sent by the bot.",
+			Kind: CounterKind,
+		},
+		{
+			Name: UpdateGlobalIndexFailedTxTotal,
+			Help: "Number of the failed update_global_index transactions sent by the bot.",
+			Kind: CounterKind,
+		},
+		{
+			Name: UpdateGlobalIndexGasWantedTotal,
+			Help: "Gas wanted by the transactions sent by the bot.",
+			Unit: "gas",
+			Kind: CounterKind,
+		},
+		{
+			Name: UpdateGlobalIndexGasUsedTotal,
+			Help: "Gas used by the transactions sent by the bot.",
+			Unit: "gas",
+			Kind: CounterKind,
+		},
+		{
+			Name: UpdateGlobalIndexUUSDFeeTotal,
+			Help: "Fees paid by the bot for the sent transactions.",
+			Unit: "uusd",
+			Kind: CounterKind,
+		},
+	}
+}
+
 func (m *UpdateGlobalIndexMonitor) providedMetrics() []MetricName {
 	return []MetricName{
-		UpdateGlobalIndexSuccessfulTxSinceLastCheck,
-		UpdateGlobalIndexGasWanted,
-		UpdateGlobalIndexGasUsed,
-		UpdateGlobalIndexUUSDFee,
-		UpdateGlobalIndexFailedTxSinceLastCheck,
+		UpdateGlobalIndexSuccessfulTxTotal,
+		UpdateGlobalIndexGasWantedTotal,
+		UpdateGlobalIndexGasUsedTotal,
+		UpdateGlobalIndexUUSDFeeTotal,
+		UpdateGlobalIndexFailedTxTotal,
 	}
 }
 
+// InitMetrics creates the counters of the processed transactions. The counters are only increased by Handler,
+// the rate of the bot transactions is computed on the consumer side.
 func (m *UpdateGlobalIndexMonitor) InitMetrics() {
 	for _, metric := range m.providedMetrics() {
 		if m.metrics[metric] == nil {
-			m.metrics[metric] = &ReadOnceMetric{}
+			m.metrics[metric] = &CounterMetricValue{}
 		}
 		m.metrics[metric].Set(0)
 	}
 }
 
+// restoreState resumes processing the transactions from the saved cursor and restores the counters.
+func (m *UpdateGlobalIndexMonitor) restoreState() {
+	var saved updateGlobalIndexState
+	if !m.state.load(&saved) {
+		return
+	}
+	m.lastMaxCheckedID = saved.LastMaxCheckedID
+	for _, metric := range m.providedMetrics() {
+		m.metrics[metric].Set(saved.Counters[metric])
+	}
+	m.logger.Infof("%s resumed from transaction %d", m.Name(), m.lastMaxCheckedID)
+}
+
+// saveState saves the cursor with the counters, it's called with the lock held.
+func (m *UpdateGlobalIndexMonitor) saveState() {
+	saved := updateGlobalIndexState{
+		LastMaxCheckedID: m.lastMaxCheckedID,
+		Counters:         make(map[MetricName]float64),
+	}
+	for _, metric := range m.providedMetrics() {
+		saved.Counters[metric] = m.metrics[metric].Get()
+	}
+	m.state.save(saved)
+}
+
 func (m *UpdateGlobalIndexMonitor) Handler(ctx context.Context) error {
 	var offset *int64
 	var fetchedTxs int
@@ -92,11 +168,19 @@ func (m *UpdateGlobalIndexMonitor) Handler(ctx context.Context) error {
 		firstCheck = true
 	}
 
+	// the counters are accumulated separately and applied with the cursor once all the pages are processed,
+	// so the transactions of a failed run are counted once by the next run
+	tmpMetrics := make(map[MetricName]MetricValue)
+	for _, metric := range m.providedMetrics() {
+		tmpMetrics[metric] = &CounterMetricValue{}
+	}
+
 	iterations := 0
-	var maxProcessedID int64
+	// the cursor is kept if no transactions are processed, e.g. the first page is empty
+	maxProcessedID := m.lastMaxCheckedID
 	var maxProcessedIDPerRequest int64
 	var alreadyProcessedFound bool
-	for iterations < threshold {
+	for iterations < m.threshold {
 		p := transactions.GetV1TxsParams{}
 		p.SetAccount(&m.ContractAddress)
 		p.SetContext(ctx)
@@ -107,18 +191,25 @@ func (m *UpdateGlobalIndexMonitor) Handler(ctx context.Context) error {
 			return fmt.Errorf("failed to fetch transaction history for UpdateGlobalIndexBotContract account: %w", err)
 		}
 
-		maxProcessedIDPerRequest, alreadyProcessedFound = m.processTransactions(resp.Payload.Txs, m.lastMaxCheckedID)
+		maxProcessedIDPerRequest, alreadyProcessedFound = m.processTransactions(resp.Payload.Txs, m.lastMaxCheckedID, tmpMetrics)
 		fetchedTxs += len(resp.Payload.Txs)
 		maxProcessedID = maxInt(maxProcessedID, maxProcessedIDPerRequest)
-		if alreadyProcessedFound || firstCheck {
+		// the empty page is the end of the history
+		if alreadyProcessedFound || firstCheck || len(resp.Payload.Txs) == 0 {
 			break
 		}
 		offset = &resp.Payload.Next
 		iterations++
 	}
+	m.lock.Lock()
+	for metric, value := range tmpMetrics {
+		m.metrics[metric].Add(value.Get())
+	}
 	m.lastMaxCheckedID = maxProcessedID
-	if threshold == iterations {
-		m.logger.Warning("update global index processing stopped due to requests threshold - ", threshold)
+	m.saveState()
+	m.lock.Unlock()
+	if m.threshold == iterations {
+		m.logger.Warning("update global index processing stopped due to requests threshold - ", m.threshold)
 	}
 	m.logger.Infoln("update global index txs fetched:", fetchedTxs)
 	m.logger.Infoln("update global index state:", m.metrics)
@@ -135,6 +226,7 @@ func maxInt(a, b int64) int64 {
 func (m *UpdateGlobalIndexMonitor) processTransactions(
 	txs []*models.GetTxListResultTxs,
 	previousMaxCheckedID int64,
+	metrics map[MetricName]MetricValue,
 ) (newMaxCheckedID int64, alreadyProcessedFound bool) {
 	// transactions are reverse ordered by ID field
 	for i, tx := range txs {
@@ -149,15 +241,15 @@ func (m *UpdateGlobalIndexMonitor) processTransactions(
 		}
 		switch isTxUpdateGlobalIndex(tx, m.networkGeneration) {
 		case SuccessfulUpdateGlobalIndexTX:
-			m.metrics[UpdateGlobalIndexSuccessfulTxSinceLastCheck].Add(1)
+			metrics[UpdateGlobalIndexSuccessfulTxTotal].Add(1)
 		case FailedUpdateGlobalIndexTx:
-			m.metrics[UpdateGlobalIndexFailedTxSinceLastCheck].Add(1)
+			metrics[UpdateGlobalIndexFailedTxTotal].Add(1)
 			m.logger.Warning("failed tx detected: ", getTxRawLog(tx))
 		case NonUpdateGlobalIndexTX:
 		}
-		m.metrics[UpdateGlobalIndexGasUsed].Add(gasUsed(m.logger, tx))
-		m.metrics[UpdateGlobalIndexGasWanted].Add(gasWanted(m.logger, tx))
-		m.metrics[UpdateGlobalIndexUUSDFee].Add(uusdFee(m.logger, tx))
+		metrics[UpdateGlobalIndexGasUsedTotal].Add(gasUsed(m.logger, tx))
+		metrics[UpdateGlobalIndexGasWantedTotal].Add(gasWanted(m.logger, tx))
+		metrics[UpdateGlobalIndexUUSDFeeTotal].Add(uusdFee(m.logger, tx))
 	}
 	return newMaxCheckedID, alreadyProcessedFound
 }
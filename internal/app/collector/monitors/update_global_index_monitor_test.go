@@ -4,8 +4,13 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 
 	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
+	"github.com/lidofinance/terra-monitors/internal/pkg/state"
 	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
 	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
@@ -20,14 +25,14 @@ func (suite *UpdateGlobalIndexMonitorTestSuite) SetupTest() {
 }
 
 func (suite *UpdateGlobalIndexMonitorTestSuite) TestSuccessfulRequest() {
-	expectedFailedTx := &ReadOnceMetric{value: 0.0}
-	expectedSuccessTxs := &ReadOnceMetric{value: 10.0}
+	expectedFailedTx := &CounterMetricValue{value: 0.0}
+	expectedSuccessTxs := &CounterMetricValue{value: 10.0}
 	// 1878948+1879023+1971021+1968141+1969755+1968301+1865889+1966868+1332487+1966896
-	expectedGasUsed := &ReadOnceMetric{value: 18767329.0}
+	expectedGasUsed := &CounterMetricValue{value: 18767329.0}
 	// 2609420+2608384+2737567+2734520+2734575+2733759+2590159+2731335+2731705+2732807
-	expectedGasWanted := &ReadOnceMetric{value: 26944231.0}
+	expectedGasWanted := &CounterMetricValue{value: 26944231.0}
 	// 391413+391258+410636+410178+410187+410064+388524+409701+409756+409922
-	expectedUUSDUsed := &ReadOnceMetric{value: 4041639.0}
+	expectedUUSDUsed := &CounterMetricValue{value: 4041639.0}
 
 	dir, err := utils.GetTerraMonitorsPath()
 	suite.NoError(err)
@@ -39,26 +44,31 @@ func (suite *UpdateGlobalIndexMonitorTestSuite) TestSuccessfulRequest() {
 	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
 
 	logger := stubs.NewTestLogger()
-	m := NewUpdateGlobalIndexMonitor(cfg, logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m := NewUpdateGlobalIndexMonitor(cfg, logger, apiClient, nil)
 
 	err = m.Handler(context.Background())
 	suite.NoError(err)
 
 	metrics := m.GetMetrics()
 
-	suite.Equal(expectedFailedTx, metrics[UpdateGlobalIndexFailedTxSinceLastCheck])
-	suite.Equal(expectedSuccessTxs, metrics[UpdateGlobalIndexSuccessfulTxSinceLastCheck])
-	suite.Equal(expectedGasUsed, metrics[UpdateGlobalIndexGasUsed])
-	suite.Equal(expectedGasWanted, metrics[UpdateGlobalIndexGasWanted])
-	suite.Equal(expectedUUSDUsed, metrics[UpdateGlobalIndexUUSDFee])
+	suite.Equal(expectedFailedTx, metrics[UpdateGlobalIndexFailedTxTotal])
+	suite.Equal(expectedSuccessTxs, metrics[UpdateGlobalIndexSuccessfulTxTotal])
+	suite.Equal(expectedGasUsed, metrics[UpdateGlobalIndexGasUsedTotal])
+	suite.Equal(expectedGasWanted, metrics[UpdateGlobalIndexGasWantedTotal])
+	suite.Equal(expectedUUSDUsed, metrics[UpdateGlobalIndexUUSDFeeTotal])
+
+	// the counters are not reset on read
+	suite.Equal(expectedSuccessTxs.Get(), m.GetMetrics()[UpdateGlobalIndexSuccessfulTxTotal].Get())
+	suite.Equal(CounterKind, metrics[UpdateGlobalIndexSuccessfulTxTotal].Kind())
 }
 
 func (suite *UpdateGlobalIndexMonitorTestSuite) TestFailedTxRequest() {
-	expectedFailedTx := &ReadOnceMetric{value: 1.0}
-	expectedSuccessTxs := &ReadOnceMetric{value: 0.0}
-	expectedGasUsed := &ReadOnceMetric{value: 1854478.0}
-	expectedGasWanted := &ReadOnceMetric{value: 1842713.0}
-	expectedUUSDUsed := &ReadOnceMetric{value: 276407.0}
+	expectedFailedTx := &CounterMetricValue{value: 1.0}
+	expectedSuccessTxs := &CounterMetricValue{value: 0.0}
+	expectedGasUsed := &CounterMetricValue{value: 1854478.0}
+	expectedGasWanted := &CounterMetricValue{value: 1842713.0}
+	expectedUUSDUsed := &CounterMetricValue{value: 276407.0}
 	expectedErrorMessagePattern := "failed tx detected: out of gas: out of gas in location"
 
 	dir, err := utils.GetTerraMonitorsPath()
@@ -71,29 +81,30 @@ func (suite *UpdateGlobalIndexMonitorTestSuite) TestFailedTxRequest() {
 	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
 
 	logger := stubs.NewTestLogger()
-	m := NewUpdateGlobalIndexMonitor(cfg, logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m := NewUpdateGlobalIndexMonitor(cfg, logger, apiClient, nil)
 
 	err = m.Handler(context.Background())
 	suite.NoError(err)
 
 	metrics := m.GetMetrics()
 
-	suite.Equal(expectedFailedTx, metrics[UpdateGlobalIndexFailedTxSinceLastCheck])
-	suite.Equal(expectedSuccessTxs, metrics[UpdateGlobalIndexSuccessfulTxSinceLastCheck])
-	suite.Equal(expectedGasUsed, metrics[UpdateGlobalIndexGasUsed])
-	suite.Equal(expectedGasWanted, metrics[UpdateGlobalIndexGasWanted])
-	suite.Equal(expectedUUSDUsed, metrics[UpdateGlobalIndexUUSDFee])
+	suite.Equal(expectedFailedTx, metrics[UpdateGlobalIndexFailedTxTotal])
+	suite.Equal(expectedSuccessTxs, metrics[UpdateGlobalIndexSuccessfulTxTotal])
+	suite.Equal(expectedGasUsed, metrics[UpdateGlobalIndexGasUsedTotal])
+	suite.Equal(expectedGasWanted, metrics[UpdateGlobalIndexGasWantedTotal])
+	suite.Equal(expectedUUSDUsed, metrics[UpdateGlobalIndexUUSDFeeTotal])
 	actualMessages := fmt.Sprintln(logger.Out)
 	suite.Contains(actualMessages, expectedErrorMessagePattern)
 }
 
 func (suite *UpdateGlobalIndexMonitorTestSuite) testThresholdTxRequest(networkGeneration string) {
-	expectedFailedTx := &ReadOnceMetric{value: 0.0}
+	expectedFailedTx := &CounterMetricValue{value: 0.0}
 	// tx counter is limited by threshold, 10 iteration, 10 tx each
-	expectedSuccessTxs := &ReadOnceMetric{value: 100.0}
-	expectedGasUsedPerTX := &ReadOnceMetric{value: 1000.0}
-	expectedGasWantedPerTX := &ReadOnceMetric{value: 10000.0}
-	expectedUUSDUsedPerTX := &ReadOnceMetric{value: 100000.0}
+	expectedSuccessTxs := &CounterMetricValue{value: 100.0}
+	expectedGasUsedPerTX := &CounterMetricValue{value: 1000.0}
+	expectedGasWantedPerTX := &CounterMetricValue{value: 10000.0}
+	expectedUUSDUsedPerTX := &CounterMetricValue{value: 100000.0}
 	expectedErrorMessagePattern := "update global index processing stopped due to requests threshold"
 
 	testServer := stubs.NewServerForUpdateGlobalIndex(networkGeneration)
@@ -101,7 +112,8 @@ func (suite *UpdateGlobalIndexMonitorTestSuite) testThresholdTxRequest(networkGe
 	cfg.NetworkGeneration = networkGeneration
 
 	logger := stubs.NewTestLogger()
-	m := NewUpdateGlobalIndexMonitor(cfg, logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m := NewUpdateGlobalIndexMonitor(cfg, logger, apiClient, nil)
 	// by setting lastMaxCheckedID to some value, we are pretending its not a first run
 	m.lastMaxCheckedID = 1
 
@@ -111,11 +123,11 @@ func (suite *UpdateGlobalIndexMonitorTestSuite) testThresholdTxRequest(networkGe
 	metrics := m.GetMetrics()
 
 	expectedSuccessTxsValue := expectedSuccessTxs.Get()
-	suite.Equal(expectedFailedTx, metrics[UpdateGlobalIndexFailedTxSinceLastCheck])
-	suite.Equal(expectedSuccessTxsValue, metrics[UpdateGlobalIndexSuccessfulTxSinceLastCheck].Get())
-	suite.Equal(expectedSuccessTxsValue*expectedGasUsedPerTX.Get(), metrics[UpdateGlobalIndexGasUsed].Get())
-	suite.Equal(expectedSuccessTxsValue*expectedGasWantedPerTX.Get(), metrics[UpdateGlobalIndexGasWanted].Get())
-	suite.Equal(expectedSuccessTxsValue*expectedUUSDUsedPerTX.Get(), metrics[UpdateGlobalIndexUUSDFee].Get())
+	suite.Equal(expectedFailedTx, metrics[UpdateGlobalIndexFailedTxTotal])
+	suite.Equal(expectedSuccessTxsValue, metrics[UpdateGlobalIndexSuccessfulTxTotal].Get())
+	suite.Equal(expectedSuccessTxsValue*expectedGasUsedPerTX.Get(), metrics[UpdateGlobalIndexGasUsedTotal].Get())
+	suite.Equal(expectedSuccessTxsValue*expectedGasWantedPerTX.Get(), metrics[UpdateGlobalIndexGasWantedTotal].Get())
+	suite.Equal(expectedSuccessTxsValue*expectedUUSDUsedPerTX.Get(), metrics[UpdateGlobalIndexUUSDFeeTotal].Get())
 	actualMessages := fmt.Sprintln(logger.Out)
 	suite.Contains(actualMessages, expectedErrorMessagePattern)
 	suite.Equal(int64(200), m.lastMaxCheckedID)
@@ -126,11 +138,11 @@ func (suite *UpdateGlobalIndexMonitorTestSuite) TestThresholdTxRequest() {
 }
 
 func (suite *UpdateGlobalIndexMonitorTestSuite) TestAlreadyCheckedTxRequest() {
-	expectedFailedTx := &ReadOnceMetric{value: 0.0}
-	expectedSuccessTxs := &ReadOnceMetric{value: 19.0}
-	expectedGasUsedPerTX := &ReadOnceMetric{value: 1000.0}
-	expectedGasWantedPerTX := &ReadOnceMetric{value: 10000.0}
-	expectedUUSDUsedPerTX := &ReadOnceMetric{value: 100000.0}
+	expectedFailedTx := &CounterMetricValue{value: 0.0}
+	expectedSuccessTxs := &CounterMetricValue{value: 19.0}
+	expectedGasUsedPerTX := &CounterMetricValue{value: 1000.0}
+	expectedGasWantedPerTX := &CounterMetricValue{value: 10000.0}
+	expectedUUSDUsedPerTX := &CounterMetricValue{value: 100000.0}
 	expectedErrorMessagePattern := "stopping processing, last checked transaction is found"
 
 	testServer := stubs.NewServerForUpdateGlobalIndex(config.NetworkGenerationColumbus5)
@@ -138,7 +150,8 @@ func (suite *UpdateGlobalIndexMonitorTestSuite) TestAlreadyCheckedTxRequest() {
 	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
 
 	logger := stubs.NewTestLogger()
-	m := NewUpdateGlobalIndexMonitor(cfg, logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m := NewUpdateGlobalIndexMonitor(cfg, logger, apiClient, nil)
 	// by setting lastMaxCheckedID to some value, we are pretending its not a first run
 	m.lastMaxCheckedID = 181
 
@@ -148,12 +161,102 @@ func (suite *UpdateGlobalIndexMonitorTestSuite) TestAlreadyCheckedTxRequest() {
 	metrics := m.GetMetrics()
 
 	expectedSuccessTxsValue := expectedSuccessTxs.Get()
-	suite.Equal(expectedFailedTx, metrics[UpdateGlobalIndexFailedTxSinceLastCheck])
-	suite.Equal(expectedSuccessTxsValue, metrics[UpdateGlobalIndexSuccessfulTxSinceLastCheck].Get())
-	suite.Equal(expectedSuccessTxsValue*expectedGasUsedPerTX.Get(), metrics[UpdateGlobalIndexGasUsed].Get())
-	suite.Equal(expectedSuccessTxsValue*expectedGasWantedPerTX.Get(), metrics[UpdateGlobalIndexGasWanted].Get())
-	suite.Equal(expectedSuccessTxsValue*expectedUUSDUsedPerTX.Get(), metrics[UpdateGlobalIndexUUSDFee].Get())
+	suite.Equal(expectedFailedTx, metrics[UpdateGlobalIndexFailedTxTotal])
+	suite.Equal(expectedSuccessTxsValue, metrics[UpdateGlobalIndexSuccessfulTxTotal].Get())
+	suite.Equal(expectedSuccessTxsValue*expectedGasUsedPerTX.Get(), metrics[UpdateGlobalIndexGasUsedTotal].Get())
+	suite.Equal(expectedSuccessTxsValue*expectedGasWantedPerTX.Get(), metrics[UpdateGlobalIndexGasWantedTotal].Get())
+	suite.Equal(expectedSuccessTxsValue*expectedUUSDUsedPerTX.Get(), metrics[UpdateGlobalIndexUUSDFeeTotal].Get())
 	actualMessages := fmt.Sprintln(logger.Out)
 	suite.Contains(actualMessages, expectedErrorMessagePattern)
 	suite.Equal(int64(200), m.lastMaxCheckedID)
 }
+
+func (suite *UpdateGlobalIndexMonitorTestSuite) TestResumedTxRequest() {
+	store, err := state.Open("")
+	suite.NoError(err)
+	suite.NoError(store.Save("mainnet/UpdateGlobalIndexMonitor", updateGlobalIndexState{
+		LastMaxCheckedID: 181,
+		Counters:         map[MetricName]float64{UpdateGlobalIndexSuccessfulTxTotal: 5, UpdateGlobalIndexFailedTxTotal: 1},
+	}))
+
+	testServer := stubs.NewServerForUpdateGlobalIndex(config.NetworkGenerationColumbus5)
+	defer testServer.Close()
+	cfg := stubs.NewTestCollectorConfig(testServer.URL)
+	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
+	cfg.Deployment = "mainnet"
+
+	logger := stubs.NewTestLogger()
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m := NewUpdateGlobalIndexMonitor(cfg, logger, apiClient, store)
+	suite.Equal(int64(181), m.lastMaxCheckedID)
+	suite.Equal(5.0, m.GetMetrics()[UpdateGlobalIndexSuccessfulTxTotal].Get())
+
+	// the transactions 182-200 are processed
+	suite.NoError(m.Handler(context.Background()))
+	metrics := m.GetMetrics()
+	suite.Equal(24.0, metrics[UpdateGlobalIndexSuccessfulTxTotal].Get())
+	suite.Equal(1.0, metrics[UpdateGlobalIndexFailedTxTotal].Get())
+
+	var saved updateGlobalIndexState
+	found, err := store.Load("mainnet/UpdateGlobalIndexMonitor", &saved)
+	suite.NoError(err)
+	suite.True(found)
+	suite.Equal(int64(200), saved.LastMaxCheckedID)
+	suite.Equal(24.0, saved.Counters[UpdateGlobalIndexSuccessfulTxTotal])
+	suite.Equal(19000.0, saved.Counters[UpdateGlobalIndexGasUsedTotal])
+}
+
+func (suite *UpdateGlobalIndexMonitorTestSuite) TestFailedPageTxRequest() {
+	backend := stubs.NewServerForUpdateGlobalIndex(config.NetworkGenerationColumbus5)
+	defer backend.Close()
+	// the pages after the first one fail until the flag is set
+	var pagesAvailable int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") != "" && atomic.LoadInt32(&pagesAvailable) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		resp, err := http.Get(backend.URL + r.URL.RequestURI())
+		suite.NoError(err)
+		defer resp.Body.Close()
+		data, err := ioutil.ReadAll(resp.Body)
+		suite.NoError(err)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	defer testServer.Close()
+	cfg := stubs.NewTestCollectorConfig(testServer.URL)
+	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
+
+	logger := stubs.NewTestLogger()
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m := NewUpdateGlobalIndexMonitor(cfg, logger, apiClient, nil)
+	m.lastMaxCheckedID = 181
+
+	// the first page isn't counted until the rest of the gap is processed
+	suite.Error(m.Handler(context.Background()))
+	suite.Equal(0.0, m.GetMetrics()[UpdateGlobalIndexSuccessfulTxTotal].Get())
+	suite.Equal(int64(181), m.lastMaxCheckedID)
+
+	atomic.StoreInt32(&pagesAvailable, 1)
+	suite.NoError(m.Handler(context.Background()))
+	suite.Equal(19.0, m.GetMetrics()[UpdateGlobalIndexSuccessfulTxTotal].Get())
+	suite.Equal(19000.0, m.GetMetrics()[UpdateGlobalIndexGasUsedTotal].Get())
+	suite.Equal(int64(200), m.lastMaxCheckedID)
+}
+
+func (suite *UpdateGlobalIndexMonitorTestSuite) TestEmptyTxRequest() {
+	testServer := stubs.NewServerWithResponse(`{"next": 0, "limit": 10, "txs": []}`)
+	defer testServer.Close()
+	cfg := stubs.NewTestCollectorConfig(testServer.URL)
+	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
+
+	logger := stubs.NewTestLogger()
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
+	m := NewUpdateGlobalIndexMonitor(cfg, logger, apiClient, nil)
+	m.lastMaxCheckedID = 181
+
+	// the cursor is kept, so the next run checks the whole gap
+	suite.NoError(m.Handler(context.Background()))
+	suite.Equal(int64(181), m.lastMaxCheckedID)
+}
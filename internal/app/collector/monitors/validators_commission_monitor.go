@@ -7,7 +7,6 @@ import (
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 
@@ -30,12 +29,13 @@ type ValidatorsCommissionMonitor struct {
 func NewValidatorsFeeMonitor(
 	cfg config.CollectorConfig,
 	logger *logrus.Logger,
+	apiClient *client.TerraRESTApis,
 	repository repositories.ValidatorsRepository,
 ) *ValidatorsCommissionMonitor {
 	m := ValidatorsCommissionMonitor{
 		metrics:              make(map[MetricName]MetricValue),
 		metricVectors:        make(map[MetricName]*MetricVector),
-		apiClient:            utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient:            apiClient,
 		validatorsRepository: repository,
 		logger:               logger,
 	}
@@ -47,14 +47,30 @@ func (m *ValidatorsCommissionMonitor) Name() string {
 	return "ValidatorsCommission"
 }
 
+func (m *ValidatorsCommissionMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{
+			Name:       ValidatorsCommission,
+			Help:       "Commission rate of the validator.",
+			LabelNames: validatorLabelNames,
+		},
+	}
+}
+
+func (m *ValidatorsCommissionMonitor) providedMetricVectors() map[MetricName][]string {
+	return map[MetricName][]string{
+		ValidatorsCommission: validatorLabelNames,
+	}
+}
+
 func (m *ValidatorsCommissionMonitor) InitMetrics() {
-	initMetrics([]MetricName{}, []MetricName{ValidatorsCommission}, m.metrics, m.metricVectors)
+	initMetrics([]MetricName{}, m.providedMetricVectors(), m.metrics, m.metricVectors)
 }
 
 func (m *ValidatorsCommissionMonitor) Handler(ctx context.Context) error {
 	// tmp* for 2stage nonblocking update data
 	tmpMetricVectors := make(map[MetricName]*MetricVector)
-	initMetrics(nil, []MetricName{ValidatorsCommission}, nil, tmpMetricVectors)
+	initMetrics(nil, m.providedMetricVectors(), nil, tmpMetricVectors)
 
 	validatorsAddress, err := m.validatorsRepository.GetValidatorsAddresses(ctx)
 	if err != nil {
@@ -67,7 +83,7 @@ func (m *ValidatorsCommissionMonitor) Handler(ctx context.Context) error {
 			return fmt.Errorf("failed to GetValidatorInfo: %w", err)
 		}
 
-		tmpMetricVectors[ValidatorsCommission].Set(validatorInfo.Moniker, validatorInfo.CommissionRate)
+		tmpMetricVectors[ValidatorsCommission].Set(validatorLabels(validatorAddress, validatorInfo.Moniker), validatorInfo.CommissionRate)
 	}
 	m.logger.Infoln("validators commission updated", m.Name())
 
@@ -8,6 +8,7 @@ import (
 	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
 	"github.com/lidofinance/terra-monitors/internal/app/collector/types"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
 	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
 	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
@@ -40,19 +41,19 @@ func (suite *ValidatorsCommissionTestSuite) TestSuccessfulRequest() {
 	cfg.BassetContractsVersion = config.V1Contracts
 	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
 
-	m := NewValidatorsFeeMonitor(cfg, logger, valRepository)
+	m := NewValidatorsFeeMonitor(cfg, logger, apiClient, valRepository)
 	err = m.Handler(context.Background())
 	suite.NoError(err)
 
 	metricVectors := m.GetMetricVectors()
 
 	expectedValidatorsCommission := 0.08
-	actualValidatorsCommission := metricVectors[ValidatorsCommission].Get(types.TestMoniker)
+	actualValidatorsCommission := metricVectors[ValidatorsCommission].Get(validatorLabels(types.TestValAddress, types.TestMoniker))
 
 	suite.Equal(expectedValidatorsCommission, actualValidatorsCommission)
 }
@@ -75,12 +76,12 @@ func (suite *ValidatorsCommissionTestSuite) TestFailedValidatorsFeeRequest() {
 	cfg.BassetContractsVersion = config.V1Contracts
 	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
 
-	m := NewValidatorsFeeMonitor(cfg, logger, valRepository)
+	m := NewValidatorsFeeMonitor(cfg, logger, apiClient, valRepository)
 	err = m.Handler(context.Background())
 	suite.Error(err)
 }
@@ -102,7 +103,7 @@ func (suite *ValidatorsCommissionTestSuite) TestFailedV2ValidatorsRepository() {
 	cfg.BassetContractsVersion = config.V2Contracts
 	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
@@ -132,7 +133,7 @@ func (suite *ValidatorsCommissionTestSuite) TestFailedValidatorsRepository() {
 	cfg.BassetContractsVersion = config.V1Contracts
 	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
 	logger := stubs.NewTestLogger()
-	apiClient := utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger)
+	apiClient, _ := source.NewClient(cfg.Source, cfg.HTTPClient, nil, logger)
 
 	valRepository, err := repositories.NewValidatorsRepository(stubs.BuildValidatorsRepositoryConfig(cfg), apiClient)
 	suite.NoError(err)
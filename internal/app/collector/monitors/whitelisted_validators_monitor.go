@@ -9,7 +9,6 @@ import (
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
 	"github.com/lidofinance/terra-monitors/internal/app/config"
-	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
 
 	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
 
@@ -31,11 +30,12 @@ type WhitelistedValidatorsMonitor struct {
 func NewWhitelistedValidatorsMonitor(
 	cfg config.CollectorConfig,
 	logger *logrus.Logger,
+	apiClient *client.TerraRESTApis,
 	repository repositories.ValidatorsRepository,
 ) WhitelistedValidatorsMonitor {
 	m := WhitelistedValidatorsMonitor{
 		metrics:              make(map[MetricName]MetricValue),
-		apiClient:            utils.BuildClient(utils.SourceToEndpoints(cfg.Source), logger),
+		apiClient:            apiClient,
 		logger:               logger,
 		validatorsRepository: repository,
 	}
@@ -48,6 +48,13 @@ func (m WhitelistedValidatorsMonitor) Name() string {
 	return "WhitelistedValidatorsMonitor"
 }
 
+func (m *WhitelistedValidatorsMonitor) MetricDescs() []MetricDesc {
+	return []MetricDesc{
+		{Name: WhitelistedValidatorsCRC32, Help: "CRC32 checksum of the whitelisted validators addresses, it changes once the whitelist is changed."},
+		{Name: WhitelistedValidatorsNum, Help: "Number of the whitelisted validators."},
+	}
+}
+
 func (m *WhitelistedValidatorsMonitor) providedMetrics() []MetricName {
 	return []MetricName{
 		WhitelistedValidatorsCRC32,
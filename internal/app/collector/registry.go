@@ -0,0 +1,284 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/state"
+	"github.com/lidofinance/terra-repositories/delegations"
+
+	"github.com/sirupsen/logrus"
+)
+
+// monitorDeps are the dependencies shared by the monitors of a collector.
+type monitorDeps struct {
+	cfg                   config.CollectorConfig
+	logger                *logrus.Logger
+	apiClient             *client.TerraRESTApis
+	validatorsRepository  repositories.ValidatorsRepository
+	delegationsRepository *delegations.Repository
+	// store keeps the monitors state across the restarts and the rebuilds on reload
+	store *state.Store
+}
+
+type monitorFactory struct {
+	// requiredAddresses returns the addresses queried by the monitor, the monitor is skipped if any of them is not set
+	requiredAddresses func(cfg config.CollectorConfig) []config.AddressName
+	// inputs returns the config values read by the monitor besides the source and the required addresses, optional
+	inputs func(cfg config.CollectorConfig) interface{}
+	new    func(deps monitorDeps) monitors.Monitor
+}
+
+// registry maps the monitor names to the monitor factories. The keys must be equal to the monitors Name().
+var registry = map[string]monitorFactory{
+	"HubState": {
+		requiredAddresses: addresses(config.AddressHubContract),
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewHubStateMonitor(d.cfg, d.logger, d.apiClient)
+		},
+	},
+	"RewardState": {
+		requiredAddresses: addresses(config.AddressRewardContract),
+		new: func(d monitorDeps) monitors.Monitor {
+			m := monitors.NewRewardStateMonitor(d.cfg, d.logger, d.apiClient)
+			return &m
+		},
+	},
+	"BlunaTokenInfo": {
+		requiredAddresses: addresses(config.AddressBlunaTokenInfoContract),
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewBlunaTokenInfoMonitor(d.cfg, d.logger, d.apiClient)
+		},
+	},
+	"Slashing": {
+		requiredAddresses: validatorsAddresses(),
+		inputs:            fetchConcurrency,
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewSlashingMonitor(d.cfg, d.logger, d.apiClient, d.validatorsRepository)
+		},
+	},
+	"UpdateGlobalIndexMonitor": {
+		requiredAddresses: addresses(config.AddressUpdateGlobalIndexBot),
+		inputs: func(cfg config.CollectorConfig) interface{} {
+			return cfg.State.MaxBackfillTxPages
+		},
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewUpdateGlobalIndexMonitor(d.cfg, d.logger, d.apiClient, d.store)
+		},
+	},
+	"HubParameters": {
+		requiredAddresses: addresses(config.AddressHubContract),
+		new: func(d monitorDeps) monitors.Monitor {
+			m := monitors.NewHubParametersMonitor(d.cfg, d.logger, d.apiClient)
+			return &m
+		},
+	},
+	"DelegationsDistribution": {
+		requiredAddresses: validatorsAddresses(config.AddressHubContract),
+		inputs: func(cfg config.CollectorConfig) interface{} {
+			return cfg.DelegationsDistributionConfig
+		},
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewDelegationsDistributionMonitor(d.cfg, d.logger, d.validatorsRepository, d.delegationsRepository)
+		},
+	},
+	"ConfigsCRC32Monitor": {
+		// the rest of the contracts are optional, the monitor skips the ones not set
+		requiredAddresses: addresses(config.AddressHubContract),
+		inputs: func(cfg config.CollectorConfig) interface{} {
+			return cfg.Addresses
+		},
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewConfigsCRC32Monitor(d.cfg, d.logger, d.apiClient)
+		},
+	},
+	"WhitelistedValidatorsMonitor": {
+		requiredAddresses: validatorsAddresses(),
+		new: func(d monitorDeps) monitors.Monitor {
+			m := monitors.NewWhitelistedValidatorsMonitor(d.cfg, d.logger, d.apiClient, d.validatorsRepository)
+			return &m
+		},
+	},
+	"ValidatorsCommission": {
+		requiredAddresses: validatorsAddresses(),
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewValidatorsFeeMonitor(d.cfg, d.logger, d.apiClient, d.validatorsRepository)
+		},
+	},
+	"OracleVotesMonitor": {
+		requiredAddresses: validatorsAddresses(),
+		inputs:            fetchConcurrency,
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewOracleVotesMonitor(d.cfg, d.logger, d.apiClient, d.validatorsRepository)
+		},
+	},
+	"OperatorBotBalanceMonitor": {
+		requiredAddresses: addresses(config.AddressUpdateGlobalIndexBot),
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewOperatorBotBalanceMonitor(d.cfg, d.logger, d.apiClient)
+		},
+	},
+	"FailedRedelegationsMonitor": {
+		requiredAddresses: validatorsAddresses(config.AddressHubContract),
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewFailedRedelegationsMonitor(d.cfg, d.logger, d.apiClient, d.validatorsRepository, d.delegationsRepository)
+		},
+	},
+	"MissedBlocks": {
+		requiredAddresses: validatorsAddresses(),
+		inputs: func(cfg config.CollectorConfig) interface{} {
+			return []int{cfg.FetchConcurrency, cfg.State.MaxBackfillBlocks}
+		},
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewMissedBlocksMonitor(d.cfg, d.logger, d.apiClient, d.validatorsRepository, d.store)
+		},
+	},
+	"SlashingParamsMonitor": {
+		requiredAddresses: addresses(),
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewSlashingParamsMonitor(d.cfg, d.logger, d.apiClient)
+		},
+	},
+	"OracleParamsMonitor": {
+		requiredAddresses: addresses(),
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewOracleParamsMonitor(d.cfg, d.logger, d.apiClient)
+		},
+	},
+	"StakedLunaAmount": {
+		requiredAddresses: addresses(),
+		new: func(d monitorDeps) monitors.Monitor {
+			return monitors.NewStakedLunaAmountMonitor(d.cfg, d.logger, d.apiClient)
+		},
+	},
+}
+
+func addresses(names ...config.AddressName) func(cfg config.CollectorConfig) []config.AddressName {
+	return func(config.CollectorConfig) []config.AddressName {
+		return names
+	}
+}
+
+// fetchConcurrency is the input of the monitors fetching the data per validator or per block.
+func fetchConcurrency(cfg config.CollectorConfig) interface{} {
+	return cfg.FetchConcurrency
+}
+
+// validatorsAddresses adds the address of the whitelisted validators source to the names.
+func validatorsAddresses(names ...config.AddressName) func(cfg config.CollectorConfig) []config.AddressName {
+	return func(cfg config.CollectorConfig) []config.AddressName {
+		source := validatorsSource(cfg)
+		for _, name := range names {
+			if name == source {
+				return names
+			}
+		}
+		return append(append([]config.AddressName{}, names...), source)
+	}
+}
+
+// validatorsSource returns the address of the whitelisted validators source read by the validators repository.
+// The source depends on the contracts version: the hub contract for v1 and the validators registry for v2.
+func validatorsSource(cfg config.CollectorConfig) config.AddressName {
+	if cfg.BassetContractsVersion == config.V2Contracts {
+		return config.AddressValidatorsRegistryContract
+	}
+	return config.AddressHubContract
+}
+
+// MonitorNames returns the sorted names of all the monitors available for EnabledMonitors and DisabledMonitors.
+func MonitorNames() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// enabledMonitors returns the sorted names of the monitors enabled by the config with the required addresses set.
+func enabledMonitors(cfg config.CollectorConfig, logger *logrus.Logger) ([]string, error) {
+	for _, names := range [][]string{cfg.EnabledMonitors, cfg.DisabledMonitors} {
+		for _, name := range names {
+			if _, found := registry[name]; !found {
+				return nil, fmt.Errorf("unknown monitor \"%s\", available monitors: %v", name, MonitorNames())
+			}
+		}
+	}
+
+	var result []string
+	for _, name := range MonitorNames() {
+		if !cfg.MonitorEnabled(name) {
+			logger.Infof("monitor %s is disabled by the config", name)
+			continue
+		}
+
+		factory := registry[name]
+		missing, err := missingAddresses(cfg, factory.requiredAddresses(cfg))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check monitor %s addresses: %w", name, err)
+		}
+		if len(missing) > 0 {
+			logger.Warningf("monitor %s is skipped, required addresses are not set: %v", name, missing)
+			continue
+		}
+
+		result = append(result, name)
+	}
+	return result, nil
+}
+
+// monitorFingerprint serializes the config values the monitor is built from. The monitor has to be rebuilt
+// to apply the config once its fingerprint is changed.
+func monitorFingerprint(cfg config.CollectorConfig, name string) (string, error) {
+	factory := registry[name]
+	inputs := struct {
+		Source                 config.Source
+		HTTPClient             config.HTTPClientConfig
+		NetworkGeneration      string
+		BassetContractsVersion string
+		Addresses              map[config.AddressName]string
+		Inputs                 interface{}
+	}{
+		Source:                 cfg.Source,
+		HTTPClient:             cfg.HTTPClient,
+		NetworkGeneration:      cfg.NetworkGeneration,
+		BassetContractsVersion: cfg.BassetContractsVersion,
+		Addresses:              make(map[config.AddressName]string),
+	}
+	for _, addressName := range factory.requiredAddresses(cfg) {
+		address, err := cfg.Addresses.Get(addressName)
+		if err != nil {
+			return "", err
+		}
+		inputs.Addresses[addressName] = address
+	}
+	if factory.inputs != nil {
+		inputs.Inputs = factory.inputs(cfg)
+	}
+
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal monitor %s inputs: %w", name, err)
+	}
+	return string(data), nil
+}
+
+func missingAddresses(cfg config.CollectorConfig, names []config.AddressName) ([]config.AddressName, error) {
+	var missing []config.AddressName
+	for _, name := range names {
+		address, err := cfg.Addresses.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		if !config.IsAddressSet(address) {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,102 @@
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+// plannedMonitors returns the monitors a new collector builds by the config sorted by name. The metric
+// descriptors of the monitors are validated by the reload plan.
+func plannedMonitors(t *testing.T, cfg config.CollectorConfig) ([]monitors.Monitor, error) {
+	c := newCollector(context.Background(), stubs.NewTestLogger(), nil)
+	t.Cleanup(c.Stop)
+	plan, err := c.prepareReload(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer plan.cancel()
+
+	var result []monitors.Monitor
+	for _, name := range MonitorNames() {
+		if m, found := plan.built[name]; found {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+func newTestRegistryConfig(contractsVersion string) config.CollectorConfig {
+	cfg := stubs.NewTestCollectorConfig("http://localhost")
+	cfg.BassetContractsVersion = contractsVersion
+	cfg.NetworkGeneration = config.NetworkGenerationColumbus5
+	// the test config has dummy addresses, which disable the monitors
+	cfg.Addresses.UpdateGlobalIndexBotAddress = "terra_update_global_index_bot"
+	cfg.Addresses.ValidatorsRegistryContract = "terra_validators_registry"
+	return cfg
+}
+
+// TestRegistry checks the registry keys and the descriptors of all the monitors.
+func TestRegistry(t *testing.T) {
+	for _, version := range []string{config.V1Contracts, config.V2Contracts} {
+		t.Run(version, func(t *testing.T) {
+			req := require.New(t)
+
+			monitorsList, err := plannedMonitors(t, newTestRegistryConfig(version))
+			req.NoError(err)
+			req.Len(monitorsList, len(registry))
+			for i, name := range MonitorNames() {
+				req.Equal(name, monitorsList[i].Name())
+			}
+		})
+	}
+}
+
+func TestEnabledMonitors(t *testing.T) {
+	req := require.New(t)
+
+	cfg := newTestRegistryConfig(config.V2Contracts)
+	cfg.EnabledMonitors = []string{"Slashing", "MissedBlocks", "HubState"}
+	cfg.DisabledMonitors = []string{"HubState"}
+
+	names, err := enabledMonitors(cfg, stubs.NewTestLogger())
+	req.NoError(err)
+	req.Equal([]string{"MissedBlocks", "Slashing"}, names)
+
+	cfg.DisabledMonitors = []string{"Unknown"}
+	_, err = enabledMonitors(cfg, stubs.NewTestLogger())
+	req.Error(err)
+	req.Contains(err.Error(), "unknown monitor \"Unknown\"")
+}
+
+func TestEnabledMonitorsSkipsDummyAddresses(t *testing.T) {
+	req := require.New(t)
+
+	cfg := newTestRegistryConfig(config.V2Contracts)
+	cfg.Addresses.ValidatorsRegistryContract = "terra_dummy_validators_registry"
+	cfg.Addresses.UpdateGlobalIndexBotAddress = ""
+
+	names, err := enabledMonitors(cfg, stubs.NewTestLogger())
+	req.NoError(err)
+	req.Equal([]string{
+		"BlunaTokenInfo",
+		"ConfigsCRC32Monitor",
+		"HubParameters",
+		"HubState",
+		"OracleParamsMonitor",
+		"RewardState",
+		"SlashingParamsMonitor",
+		"StakedLunaAmount",
+	}, names)
+
+	// v1 validators are fetched from the hub contract
+	cfg.BassetContractsVersion = config.V1Contracts
+	names, err = enabledMonitors(cfg, stubs.NewTestLogger())
+	req.NoError(err)
+	req.Len(names, len(registry)-2)
+}
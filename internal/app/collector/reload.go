@@ -0,0 +1,191 @@
+package collector
+
+import (
+	"fmt"
+
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/app/collector/repositories"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
+)
+
+// ReloadResult lists the monitors names by the reload outcome.
+type ReloadResult struct {
+	// Added are the newly enabled monitors
+	Added []string `json:"added"`
+	// Removed are the disabled monitors and the ones with the required addresses unset
+	Removed []string `json:"removed"`
+	// Rebuilt are the monitors with the changed inputs, they are created from scratch and lose their state
+	// except the one kept by the state store (e.g. the checked blocks and transactions cursors)
+	Rebuilt []string `json:"rebuilt"`
+	// Rescheduled are the monitors with the changed schedule only, they keep their state
+	Rescheduled []string `json:"rescheduled"`
+	// Unchanged monitors keep running as is
+	Unchanged []string `json:"unchanged"`
+}
+
+func (r ReloadResult) String() string {
+	return fmt.Sprintf("added %v, removed %v, rebuilt %v, rescheduled %v, unchanged %d monitors",
+		r.Added, r.Removed, r.Rebuilt, r.Rescheduled, len(r.Unchanged))
+}
+
+// Reload applies the config of the collector deployment. Only the monitors with the changed inputs are rebuilt,
+// the rest keep running with their state. The collector is not changed if the config fails to apply.
+func (c *Collector) Reload(cfg config.CollectorConfig) (ReloadResult, error) {
+	result, err := c.apply(cfg)
+	if err != nil {
+		return result, fmt.Errorf("failed to reload deployment %s: %w", cfg.Deployment, err)
+	}
+	c.logger.Infof("deployment %s reloaded: %s", cfg.Deployment, result)
+	return result, nil
+}
+
+// apply brings the registered monitors in line with the config.
+func (c *Collector) apply(cfg config.CollectorConfig) (ReloadResult, error) {
+	plan, err := c.prepareReload(cfg)
+	if err != nil {
+		return ReloadResult{}, err
+	}
+	return plan.commit(), nil
+}
+
+// reloadPlan is the reload of the collector validated and built by prepareReload, it's applied by commit
+// or dropped by cancel. The plan holds the collector reload lock until then.
+type reloadPlan struct {
+	c            *Collector
+	cfg          config.CollectorConfig
+	apiClient    *client.TerraRESTApis
+	failover     *source.FailoverTransport
+	deps         monitorDeps
+	registered   map[string]*registeredMonitor
+	fingerprints map[string]string
+	// built are the added and rebuilt monitors by name
+	built  map[string]monitors.Monitor
+	result ReloadResult
+}
+
+// prepareReload validates the config and builds the monitors to add and rebuild without changing the collector.
+func (c *Collector) prepareReload(cfg config.CollectorConfig) (*reloadPlan, error) {
+	c.reloadLock.Lock()
+	plan, err := c.newReloadPlan(cfg)
+	if err != nil {
+		c.reloadLock.Unlock()
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (c *Collector) newReloadPlan(cfg config.CollectorConfig) (*reloadPlan, error) {
+	names, err := enabledMonitors(cfg, c.logger)
+	if err != nil {
+		return nil, err
+	}
+	p := &reloadPlan{
+		c:            c,
+		cfg:          cfg,
+		fingerprints: make(map[string]string, len(names)),
+		built:        make(map[string]monitors.Monitor),
+	}
+	for _, name := range names {
+		if p.fingerprints[name], err = monitorFingerprint(cfg, name); err != nil {
+			return nil, err
+		}
+	}
+	p.apiClient, p.failover = c.sharedAPIClient(cfg)
+	if p.deps, err = c.newMonitorDeps(cfg, p.apiClient); err != nil {
+		return nil, err
+	}
+
+	c.lock.RLock()
+	p.registered = make(map[string]*registeredMonitor, len(c.instrumented))
+	for name, rm := range c.instrumented {
+		p.registered[name] = rm
+	}
+	c.lock.RUnlock()
+
+	for _, name := range MonitorNames() {
+		if _, found := p.registered[name]; found {
+			if _, enabled := p.fingerprints[name]; !enabled {
+				p.result.Removed = append(p.result.Removed, name)
+			}
+		}
+	}
+	for _, name := range names {
+		rm, found := p.registered[name]
+		switch {
+		case !found:
+			p.result.Added = append(p.result.Added, name)
+		case rm.fingerprint != p.fingerprints[name]:
+			p.result.Rebuilt = append(p.result.Rebuilt, name)
+		case rm.schedule != newSchedule(cfg, name):
+			p.result.Rescheduled = append(p.result.Rescheduled, name)
+			continue
+		default:
+			p.result.Unchanged = append(p.result.Unchanged, name)
+			continue
+		}
+		p.built[name] = registry[name].new(p.deps)
+	}
+
+	// the built monitors are validated against the metrics of the kept monitors and each other
+	metricNames := make(map[monitors.MetricName]string)
+	for _, name := range append(append([]string{}, p.result.Rescheduled...), p.result.Unchanged...) {
+		addMetricNames(metricNames, p.registered[name].Monitor)
+	}
+	for _, name := range names {
+		if m, found := p.built[name]; found {
+			if err := checkMetricDescs(m, metricNames); err != nil {
+				return nil, fmt.Errorf("failed to register monitor %s: %w", name, err)
+			}
+			addMetricNames(metricNames, m)
+		}
+	}
+	return p, nil
+}
+
+// commit applies the plan to the collector, it can't fail.
+func (p *reloadPlan) commit() ReloadResult {
+	c := p.c
+	defer c.reloadLock.Unlock()
+
+	c.limiter.Configure(p.cfg.RateLimit)
+	c.lock.Lock()
+	c.deployment, c.chainID = p.cfg.Deployment, p.cfg.ChainID
+	if p.failover != c.failover || p.cfg.SourceProbe != c.probeCfg {
+		c.restartProbe(p.cfg.SourceProbe, p.failover)
+	}
+	c.apiClient, c.source, c.httpClientCfg = p.apiClient, p.cfg.Source, p.cfg.HTTPClient
+	c.failover, c.probeCfg = p.failover, p.cfg.SourceProbe
+	c.validatorsRepository = p.deps.validatorsRepository.(*repositories.CachedValidatorsRepository)
+	c.validatorsRepositoryCfg = validatorsRepositoryConfig(p.cfg)
+	c.lock.Unlock()
+
+	for _, name := range p.result.Removed {
+		c.unregisterMonitor(name, false)
+	}
+	for _, name := range p.result.Rebuilt {
+		// the old values are exported until the rebuilt monitor replaces them
+		c.unregisterMonitor(name, true)
+	}
+	for _, name := range p.result.Rescheduled {
+		rm := p.registered[name]
+		c.stopMonitor(rm)
+		rm.schedule = newSchedule(p.cfg, name)
+		c.startMonitor(rm, false)
+	}
+	for _, name := range MonitorNames() {
+		if m, found := p.built[name]; found {
+			c.lock.Lock()
+			rm := c.addMonitor(p.cfg, m, p.fingerprints[name])
+			c.lock.Unlock()
+			c.startMonitor(rm, true)
+		}
+	}
+	return p.result
+}
+
+// cancel drops the plan leaving the collector as is.
+func (p *reloadPlan) cancel() {
+	p.c.reloadLock.Unlock()
+}
@@ -0,0 +1,278 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+// counterMonitor counts its runs, so the tests can check whether the monitor state is kept on reload.
+type counterMonitor struct {
+	name   string
+	metric monitors.MetricName
+	runs   *monitors.SimpleMetricValue
+}
+
+func newCounterMonitor(name string, metric monitors.MetricName) *counterMonitor {
+	return &counterMonitor{name: name, metric: metric, runs: &monitors.SimpleMetricValue{}}
+}
+
+func (m *counterMonitor) Name() string {
+	return m.name
+}
+
+func (m *counterMonitor) MetricDescs() []monitors.MetricDesc {
+	return []monitors.MetricDesc{{Name: m.metric, Help: "Test metric."}}
+}
+
+func (m *counterMonitor) Handler(ctx context.Context) error {
+	m.runs.Add(1)
+	return nil
+}
+
+func (m *counterMonitor) GetMetrics() map[monitors.MetricName]monitors.MetricValue {
+	return map[monitors.MetricName]monitors.MetricValue{m.metric: m.runs}
+}
+
+func (m *counterMonitor) GetMetricVectors() map[monitors.MetricName]*monitors.MetricVector {
+	return nil
+}
+
+// setTestRegistry replaces the monitors registry until the test is finished.
+func setTestRegistry(t *testing.T, r map[string]monitorFactory) {
+	original := registry
+	registry = r
+	t.Cleanup(func() {
+		registry = original
+	})
+}
+
+func TestCollectorReload(t *testing.T) {
+	req := require.New(t)
+
+	setTestRegistry(t, map[string]monitorFactory{
+		"Bot": {
+			requiredAddresses: addresses(config.AddressUpdateGlobalIndexBot),
+			new: func(monitorDeps) monitors.Monitor {
+				return newCounterMonitor("Bot", "bot_runs")
+			},
+		},
+		"Distribution": {
+			requiredAddresses: addresses(),
+			inputs: func(cfg config.CollectorConfig) interface{} {
+				return cfg.DelegationsDistributionConfig
+			},
+			new: func(monitorDeps) monitors.Monitor {
+				return newCounterMonitor("Distribution", "distribution_runs")
+			},
+		},
+		"Blocks": {
+			requiredAddresses: addresses(),
+			new: func(monitorDeps) monitors.Monitor {
+				return newCounterMonitor("Blocks", "blocks_runs")
+			},
+		},
+		"Duplicate": {
+			requiredAddresses: addresses(),
+			new: func(monitorDeps) monitors.Monitor {
+				return newCounterMonitor("Duplicate", "blocks_runs")
+			},
+		},
+	})
+
+	cfg := stubs.NewTestCollectorConfig("http://localhost")
+	cfg.BassetContractsVersion = config.V1Contracts
+	cfg.UpdateDataInterval = time.Hour
+	cfg.Deployment = "mainnet"
+	cfg.DisabledMonitors = []string{"Duplicate"}
+	cfg.Addresses.UpdateGlobalIndexBotAddress = "terra_update_global_index_bot"
+	cfg.DelegationsDistributionConfig.NumMedianAbsoluteDeviations = 3
+
+	c, err := New(context.Background(), cfg, stubs.NewTestLogger())
+	req.NoError(err)
+	defer c.Stop()
+	req.Len(c.MonitorsStats(), 3)
+	waitReady(t, c)
+
+	// the runs counters are incremented by the second run, the reload must keep them for the unchanged monitors
+	for _, name := range []string{"Bot", "Distribution", "Blocks"} {
+		req.NoError(c.instrumented[name].Handler(context.Background()))
+	}
+
+	cfg.Addresses.UpdateGlobalIndexBotAddress = "terra1eqpx4zr2vm9jwu2vas5rh6704f6zzglsayf2fy"
+	cfg.DelegationsDistributionConfig.NumMedianAbsoluteDeviations = 4
+	cfg.Scheduler.Intervals = config.MonitorIntervals{"Blocks": time.Minute}
+	result, err := c.Reload(cfg)
+	req.NoError(err)
+	req.Equal(ReloadResult{
+		Rebuilt:     []string{"Bot", "Distribution"},
+		Rescheduled: []string{"Blocks"},
+	}, result)
+	waitReady(t, c)
+
+	snapshot := c.Snapshot()
+	req.Equal(1.0, snapshot.Metrics["bot_runs"])
+	req.Equal(1.0, snapshot.Metrics["distribution_runs"])
+	req.Equal(2.0, snapshot.Metrics["blocks_runs"])
+	req.Equal(time.Minute, c.instrumented["Blocks"].schedule.interval)
+
+	cfg.DisabledMonitors = []string{"Bot", "Duplicate"}
+	result, err = c.Reload(cfg)
+	req.NoError(err)
+	req.Equal(ReloadResult{
+		Removed:   []string{"Bot"},
+		Unchanged: []string{"Blocks", "Distribution"},
+	}, result)
+	_, found := c.Snapshot().Metrics["bot_runs"]
+	req.False(found, "metrics of the removed monitor must not be exported")
+	req.Len(c.MonitorsStats(), 2)
+
+	// the metrics collision of the enabled monitor fails the reload before the other monitors are rebuilt
+	distribution := c.instrumented["Distribution"]
+	cfg.DisabledMonitors = []string{"Bot"}
+	cfg.DelegationsDistributionConfig.NumMedianAbsoluteDeviations = 5
+	_, err = c.Reload(cfg)
+	req.Error(err)
+	req.Len(c.MonitorsStats(), 2)
+	req.True(distribution == c.instrumented["Distribution"], "the monitors must be kept if the reload fails")
+
+	cfg.DisabledMonitors = []string{"Unknown"}
+	_, err = c.Reload(cfg)
+	req.Error(err)
+	req.Len(c.MonitorsStats(), 2, "the monitors must be kept if the config is invalid")
+}
+
+func TestCollectorReloadValidatorsRepository(t *testing.T) {
+	req := require.New(t)
+
+	setTestRegistry(t, map[string]monitorFactory{
+		"Validators": {
+			requiredAddresses: validatorsAddresses(),
+			new: func(d monitorDeps) monitors.Monitor {
+				req.True(d.validatorsRepository != nil)
+				return newCounterMonitor("Validators", "validators_runs")
+			},
+		},
+	})
+
+	cfg := stubs.NewTestCollectorConfig("http://localhost")
+	cfg.BassetContractsVersion = config.V2Contracts
+	cfg.UpdateDataInterval = time.Hour
+	cfg.Deployment = "mainnet"
+	cfg.Addresses.ValidatorsRegistryContract = "terra_validators_registry"
+
+	c, err := New(context.Background(), cfg, stubs.NewTestLogger())
+	req.NoError(err)
+	defer c.Stop()
+	waitReady(t, c)
+	validatorsRepository := c.validatorsRepository
+
+	// the hub contract is not read by the v2 validators repository, so neither it nor its monitors are rebuilt
+	cfg.Addresses.HubContract = "terra_hub"
+	result, err := c.Reload(cfg)
+	req.NoError(err)
+	req.Equal(ReloadResult{Unchanged: []string{"Validators"}}, result)
+	req.True(validatorsRepository == c.validatorsRepository, "the validators repository must be reused")
+
+	cfg.Addresses.ValidatorsRegistryContract = "terra_validators_registry_v2"
+	result, err = c.Reload(cfg)
+	req.NoError(err)
+	req.Equal(ReloadResult{Rebuilt: []string{"Validators"}}, result)
+	req.True(validatorsRepository != c.validatorsRepository, "the validators repository must be rebuilt")
+	waitReady(t, c)
+}
+
+func TestGroupReload(t *testing.T) {
+	req := require.New(t)
+
+	setTestRegistry(t, map[string]monitorFactory{
+		"Blocks": {
+			requiredAddresses: addresses(),
+			new: func(monitorDeps) monitors.Monitor {
+				return newCounterMonitor("Blocks", "blocks_runs")
+			},
+		},
+		"Duplicate": {
+			requiredAddresses: addresses(config.AddressUpdateGlobalIndexBot),
+			new: func(monitorDeps) monitors.Monitor {
+				return newCounterMonitor("Duplicate", "blocks_runs")
+			},
+		},
+	})
+
+	cfg := stubs.NewTestCollectorConfig("http://localhost")
+	cfg.BassetContractsVersion = config.V1Contracts
+	cfg.UpdateDataInterval = time.Hour
+	cfg.Deployments = []config.Deployment{{Name: "mainnet"}}
+
+	g, err := NewGroup(context.Background(), cfg, stubs.NewTestLogger())
+	req.NoError(err)
+	defer g.Stop()
+	mainnet := g.Collectors()[0]
+
+	cfg.Deployments = []config.Deployment{{Name: "mainnet"}, {Name: "testnet", ChainID: "bombay-12"}}
+	results, err := g.Reload(func() (config.CollectorConfig, error) {
+		return cfg, nil
+	})
+	req.NoError(err)
+	req.Equal(map[string]ReloadResult{"mainnet": {Unchanged: []string{"Blocks"}}}, results)
+
+	collectors := g.Collectors()
+	req.Len(collectors, 2)
+	req.True(mainnet == collectors[0], "the collector of the unchanged deployment must be kept")
+	req.Equal("testnet", collectors[1].Deployment())
+	req.Equal("bombay-12", collectors[1].ChainID())
+
+	_, err = g.Reload(func() (config.CollectorConfig, error) {
+		return cfg, errors.New("invalid config")
+	})
+	req.Error(err)
+	req.Len(g.Collectors(), 2)
+
+	stats := g.ReloadStats()
+	req.Equal(uint64(1), stats.Successes)
+	req.Equal(uint64(1), stats.Failures)
+	req.EqualError(stats.LastError, "failed to load config: invalid config")
+	req.False(stats.LastSuccess.IsZero())
+
+	var removed []string
+	g.OnRemove(func(deployment string) {
+		removed = append(removed, deployment)
+	})
+	cfg.Deployments = []config.Deployment{{Name: "mainnet"}}
+	_, err = g.Reload(func() (config.CollectorConfig, error) {
+		return cfg, nil
+	})
+	req.NoError(err)
+	req.Len(g.Collectors(), 1)
+	req.Equal([]string{"testnet"}, removed)
+
+	// the failed reload of mainnet must neither add testnet nor remove staging
+	cfg.Deployments = []config.Deployment{{Name: "mainnet"}, {Name: "staging"}}
+	_, err = g.Reload(func() (config.CollectorConfig, error) {
+		return cfg, nil
+	})
+	req.NoError(err)
+	collectors = g.Collectors()
+
+	// the metrics collision of the monitor enabled by the mainnet address fails the reload
+	cfg.Deployments = []config.Deployment{
+		{Name: "testnet"},
+		{Name: "mainnet", Addresses: config.Addresses{UpdateGlobalIndexBotAddress: "terra_update_global_index_bot"}},
+	}
+	_, err = g.Reload(func() (config.CollectorConfig, error) {
+		return cfg, nil
+	})
+	req.Error(err)
+	req.Contains(err.Error(), "failed to reload deployment mainnet")
+	req.Equal(collectors, g.Collectors())
+	req.Equal([]string{"testnet"}, removed)
+	req.NoError(collectors[1].ctx.Err(), "the collector of staging must keep running")
+}
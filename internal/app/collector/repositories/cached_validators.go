@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lidofinance/terra-repositories/validators"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const validatorsAddressesKey = "addresses"
+
+// CacheStats describes the requests to a cache by the result.
+type CacheStats struct {
+	// Hits are the requests served by the fresh cached data
+	Hits uint64
+	// Misses are the requests served by the upstream repository
+	Misses uint64
+	// StaleHits are the requests served by the stale cached data, since the upstream repository has failed
+	StaleHits uint64
+	// Errors are the failed requests, neither the upstream repository nor the cache could serve them
+	Errors uint64
+}
+
+type cacheEntry struct {
+	value     interface{}
+	fetchedAt time.Time
+}
+
+// CachedValidatorsRepository is a ValidatorsRepository caching the data of the wrapped repository for the TTL.
+// The concurrent requests of the same data are deduplicated, so the validators monitors running at the same time
+// share a single upstream request. The expired data is returned if the upstream request fails and the data
+// is not older than TTL + maxStale. The shared upstream request doesn't depend on any caller, it's limited
+// by the fetch timeout only, 0 means no limit.
+type CachedValidatorsRepository struct {
+	repository   ValidatorsRepository
+	fetchTimeout time.Duration
+	// now returns the current time, it's replaced by the tests
+	now func() time.Time
+
+	group singleflight.Group
+	// lock guards the fields below
+	lock     sync.Mutex
+	ttl      time.Duration
+	maxStale time.Duration
+	entries  map[string]cacheEntry
+	stats    CacheStats
+}
+
+func NewCachedValidatorsRepository(
+	repository ValidatorsRepository,
+	ttl, maxStale, fetchTimeout time.Duration,
+) *CachedValidatorsRepository {
+	return &CachedValidatorsRepository{
+		repository:   repository,
+		fetchTimeout: fetchTimeout,
+		ttl:          ttl,
+		maxStale:     maxStale,
+		now:          time.Now,
+		entries:      make(map[string]cacheEntry),
+	}
+}
+
+func (r *CachedValidatorsRepository) GetValidatorsAddresses(ctx context.Context) ([]string, error) {
+	value, err := r.get(ctx, validatorsAddressesKey, func(ctx context.Context) (interface{}, error) {
+		return r.repository.GetValidatorsAddresses(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the callers get their own copies, so they can't modify the cached data
+	return append([]string{}, value.([]string)...), nil
+}
+
+func (r *CachedValidatorsRepository) GetValidatorInfo(ctx context.Context, address string) (validators.ValidatorInfo, error) {
+	value, err := r.get(ctx, "info:"+address, func(ctx context.Context) (interface{}, error) {
+		return r.repository.GetValidatorInfo(ctx, address)
+	})
+	if err != nil {
+		return validators.ValidatorInfo{}, err
+	}
+	return value.(validators.ValidatorInfo), nil
+}
+
+// SetTTL changes the cache TTL and the max staleness of the data returned on the upstream failures.
+func (r *CachedValidatorsRepository) SetTTL(ttl, maxStale time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.ttl, r.maxStale = ttl, maxStale
+}
+
+// Stats returns the cache requests stats.
+func (r *CachedValidatorsRepository) Stats() CacheStats {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.stats
+}
+
+// get returns the fresh cached value by the key or fetches it from the upstream.
+func (r *CachedValidatorsRepository) get(
+	ctx context.Context,
+	key string,
+	fetch func(ctx context.Context) (interface{}, error),
+) (interface{}, error) {
+	r.lock.Lock()
+	entry, found := r.entries[key]
+	if found && r.now().Sub(entry.fetchedAt) < r.ttl {
+		r.stats.Hits++
+		r.lock.Unlock()
+		return entry.value, nil
+	}
+	r.lock.Unlock()
+
+	// the upstream request is shared by the concurrent callers, so it's detached from the context of the first one:
+	// the cancelled caller stops waiting for it, but the rest of the callers still get the result
+	ch := r.group.DoChan(key, func() (interface{}, error) {
+		fetchCtx, cancel := r.fetchContext()
+		defer cancel()
+		value, err := fetch(fetchCtx)
+		if err != nil {
+			return nil, err
+		}
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.entries[key] = cacheEntry{value: value, fetchedAt: r.now()}
+		return value, nil
+	})
+
+	var result singleflight.Result
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result = <-ch:
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if result.Err == nil {
+		r.stats.Misses++
+		return result.Val, nil
+	}
+	entry, found = r.entries[key]
+	if found && r.now().Sub(entry.fetchedAt) < r.ttl+r.maxStale {
+		r.stats.StaleHits++
+		return entry.value, nil
+	}
+	r.stats.Errors++
+	return nil, result.Err
+}
+
+// fetchContext returns the context of an upstream request limited by the fetch timeout.
+func (r *CachedValidatorsRepository) fetchContext() (context.Context, context.CancelFunc) {
+	if r.fetchTimeout > 0 {
+		return context.WithTimeout(context.Background(), r.fetchTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-repositories/validators"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errUpstream = errors.New("upstream is down")
+
+// fakeValidatorsRepository counts the requests and blocks them until release is closed if it's set.
+type fakeValidatorsRepository struct {
+	requests int32
+	fail     int32
+	release  chan struct{}
+}
+
+func (r *fakeValidatorsRepository) GetValidatorsAddresses(context.Context) ([]string, error) {
+	atomic.AddInt32(&r.requests, 1)
+	if r.release != nil {
+		<-r.release
+	}
+	if atomic.LoadInt32(&r.fail) == 1 {
+		return nil, errUpstream
+	}
+	return []string{"terravaloper1", "terravaloper2"}, nil
+}
+
+func (r *fakeValidatorsRepository) GetValidatorInfo(_ context.Context, address string) (validators.ValidatorInfo, error) {
+	atomic.AddInt32(&r.requests, 1)
+	if atomic.LoadInt32(&r.fail) == 1 {
+		return validators.ValidatorInfo{}, errUpstream
+	}
+	return validators.ValidatorInfo{Address: address, Moniker: "moniker " + address}, nil
+}
+
+// newTestCache returns the cache with the clock controlled by the returned func.
+func newTestCache(upstream ValidatorsRepository) (*CachedValidatorsRepository, func(d time.Duration)) {
+	now := time.Unix(1600000000, 0)
+	cache := NewCachedValidatorsRepository(upstream, time.Minute, 10*time.Minute, time.Second)
+	cache.now = func() time.Time { return now }
+	return cache, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestCachedValidatorsRepositoryTTL(t *testing.T) {
+	upstream := &fakeValidatorsRepository{}
+	cache, advance := newTestCache(upstream)
+	ctx := context.Background()
+
+	addresses, err := cache.GetValidatorsAddresses(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"terravaloper1", "terravaloper2"}, addresses)
+	// the callers' modifications don't affect the cached data
+	addresses[0] = "modified"
+
+	advance(30 * time.Second)
+	addresses, err = cache.GetValidatorsAddresses(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"terravaloper1", "terravaloper2"}, addresses)
+	require.EqualValues(t, 1, upstream.requests)
+
+	info, err := cache.GetValidatorInfo(ctx, "terravaloper1")
+	require.NoError(t, err)
+	require.Equal(t, "moniker terravaloper1", info.Moniker)
+	require.EqualValues(t, 2, upstream.requests)
+
+	advance(time.Minute)
+	_, err = cache.GetValidatorsAddresses(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, upstream.requests)
+	require.Equal(t, CacheStats{Hits: 1, Misses: 3}, cache.Stats())
+}
+
+func TestCachedValidatorsRepositorySingleFlight(t *testing.T) {
+	upstream := &fakeValidatorsRepository{release: make(chan struct{})}
+	cache, _ := newTestCache(upstream)
+
+	const callers = 5
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			addresses, err := cache.GetValidatorsAddresses(context.Background())
+			require.NoError(t, err)
+			require.Len(t, addresses, 2)
+		}()
+	}
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&upstream.requests) == 1 }, time.Second, time.Millisecond)
+	// the rest of the callers either wait for the request in flight or hit the cache
+	time.Sleep(10 * time.Millisecond)
+	close(upstream.release)
+	wg.Wait()
+
+	require.EqualValues(t, 1, upstream.requests)
+	stats := cache.Stats()
+	require.EqualValues(t, callers, stats.Hits+stats.Misses)
+}
+
+func TestCachedValidatorsRepositoryCallerCancelled(t *testing.T) {
+	upstream := &fakeValidatorsRepository{release: make(chan struct{})}
+	cache, _ := newTestCache(upstream)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		_, err := cache.GetValidatorsAddresses(ctx)
+		firstErr <- err
+	}()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&upstream.requests) == 1 }, time.Second, time.Millisecond)
+
+	secondErr := make(chan error)
+	go func() {
+		addresses, err := cache.GetValidatorsAddresses(context.Background())
+		if err == nil && len(addresses) != 2 {
+			err = errors.New("unexpected addresses")
+		}
+		secondErr <- err
+	}()
+	cancel()
+	require.ErrorIs(t, <-firstErr, context.Canceled)
+
+	// the request of the cancelled caller goes on for the rest of the callers
+	close(upstream.release)
+	require.NoError(t, <-secondErr)
+	require.EqualValues(t, 1, upstream.requests)
+	stats := cache.Stats()
+	require.EqualValues(t, 1, stats.Hits+stats.Misses)
+}
+
+func TestCachedValidatorsRepositoryFetchTimeout(t *testing.T) {
+	upstream := &fakeValidatorsRepository{}
+	cache := NewCachedValidatorsRepository(upstream, time.Minute, 10*time.Minute, 10*time.Millisecond)
+	block := make(chan struct{})
+	defer close(block)
+
+	_, err := cache.get(context.Background(), "blocked", func(ctx context.Context) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-block:
+			return nil, nil
+		}
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded, "the upstream request must be limited by the fetch timeout")
+	require.Equal(t, CacheStats{Errors: 1}, cache.Stats())
+}
+
+func TestCachedValidatorsRepositoryStale(t *testing.T) {
+	upstream := &fakeValidatorsRepository{}
+	cache, advance := newTestCache(upstream)
+	ctx := context.Background()
+
+	_, err := cache.GetValidatorInfo(ctx, "terravaloper1")
+	require.NoError(t, err)
+
+	atomic.StoreInt32(&upstream.fail, 1)
+	advance(5 * time.Minute)
+	info, err := cache.GetValidatorInfo(ctx, "terravaloper1")
+	require.NoError(t, err, "the stale data must be returned while the upstream is down")
+	require.Equal(t, "moniker terravaloper1", info.Moniker)
+
+	_, err = cache.GetValidatorInfo(ctx, "terravaloper2")
+	require.ErrorIs(t, err, errUpstream, "there is no cached data to fall back to")
+
+	advance(10 * time.Minute)
+	_, err = cache.GetValidatorInfo(ctx, "terravaloper1")
+	require.ErrorIs(t, err, errUpstream, "the data older than TTL + max stale must not be returned")
+
+	atomic.StoreInt32(&upstream.fail, 0)
+	_, err = cache.GetValidatorInfo(ctx, "terravaloper1")
+	require.NoError(t, err)
+	require.Equal(t, CacheStats{Misses: 2, StaleHits: 1, Errors: 2}, cache.Stats())
+}
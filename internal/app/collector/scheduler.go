@@ -0,0 +1,75 @@
+package collector
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// schedule describes how often a monitor is run.
+type schedule struct {
+	interval time.Duration
+	// jitterFactor is the max fraction of a delay randomly added to it to spread the load on the source
+	jitterFactor float64
+	maxBackoff   time.Duration
+}
+
+func newSchedule(cfg config.CollectorConfig, monitorName string) schedule {
+	return schedule{
+		interval:     cfg.Interval(monitorName),
+		jitterFactor: cfg.Scheduler.JitterFactor,
+		maxBackoff:   cfg.Scheduler.MaxBackoff,
+	}
+}
+
+// delay returns the time to wait before the next monitor run. The delay grows exponentially
+// with the number of consecutive failures, but never exceeds maxBackoff (or interval, if it's greater).
+// random is a value in [0, 1) used to calculate the jitter.
+func (s schedule) delay(failures int, random float64) time.Duration {
+	limit := s.maxBackoff
+	if limit < s.interval {
+		limit = s.interval
+	}
+
+	d := s.interval
+	for i := 0; i < failures && d < limit; i++ {
+		d *= 2
+	}
+	if d > limit {
+		d = limit
+	}
+
+	return d + time.Duration(float64(d)*s.jitterFactor*random)
+}
+
+// runMonitor runs the monitor Handler until the ctx is cancelled. The next run is never started
+// before the previous one is finished.
+// failures is the number of consecutive failures happened before the loop is started.
+func runMonitor(ctx context.Context, m monitors.Monitor, s schedule, failures int, logger *logrus.Logger) {
+	for {
+		timer := time.NewTimer(s.delay(failures, rand.Float64()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		err := m.Handler(ctx)
+		if err == nil {
+			failures = 0
+			continue
+		}
+		if ctx.Err() != nil {
+			// the collector is shutting down, the error is caused by the cancellation
+			return
+		}
+		failures++
+		logger.Errorf("failed to update %s data (%d consecutive failures): %+v\n", m.Name(), failures, err)
+	}
+}
@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleDelay(t *testing.T) {
+	req := require.New(t)
+
+	s := schedule{
+		interval:     10 * time.Second,
+		jitterFactor: 0.1,
+		maxBackoff:   time.Minute,
+	}
+
+	req.Equal(10*time.Second, s.delay(0, 0))
+	req.Equal(11*time.Second, s.delay(0, 1))
+	req.Equal(20*time.Second, s.delay(1, 0))
+	req.Equal(40*time.Second, s.delay(2, 0))
+	// capped by maxBackoff
+	req.Equal(time.Minute, s.delay(3, 0))
+	req.Equal(time.Minute, s.delay(100, 0))
+
+	// maxBackoff less than interval doesn't shorten the interval
+	s.maxBackoff = time.Second
+	req.Equal(10*time.Second, s.delay(5, 0))
+}
+
+type slowMonitor struct {
+	calls      int32
+	running    int32
+	maxRunning int32
+	fail       bool
+}
+
+func (m *slowMonitor) Name() string {
+	return "SlowMonitor"
+}
+
+func (m *slowMonitor) Handler(ctx context.Context) error {
+	atomic.AddInt32(&m.calls, 1)
+	running := atomic.AddInt32(&m.running, 1)
+	defer atomic.AddInt32(&m.running, -1)
+	if running > atomic.LoadInt32(&m.maxRunning) {
+		atomic.StoreInt32(&m.maxRunning, running)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if m.fail {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func (m *slowMonitor) GetMetrics() map[monitors.MetricName]monitors.MetricValue {
+	return nil
+}
+
+func (m *slowMonitor) GetMetricVectors() map[monitors.MetricName]*monitors.MetricVector {
+	return nil
+}
+
+func (m *slowMonitor) MetricDescs() []monitors.MetricDesc {
+	return nil
+}
+
+func TestRunMonitorNoOverlap(t *testing.T) {
+	req := require.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	m := &slowMonitor{}
+	// interval is much shorter than the handler run time
+	runMonitor(ctx, m, schedule{interval: time.Microsecond}, 0, stubs.NewTestLogger())
+
+	req.Greater(atomic.LoadInt32(&m.calls), int32(1))
+	req.Equal(int32(1), atomic.LoadInt32(&m.maxRunning))
+}
+
+func TestRunMonitorBackoff(t *testing.T) {
+	req := require.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	healthy, failing := &slowMonitor{}, &slowMonitor{fail: true}
+	s := schedule{interval: 5 * time.Millisecond, maxBackoff: time.Second}
+
+	done := make(chan struct{})
+	go func() {
+		runMonitor(ctx, failing, s, 0, stubs.NewTestLogger())
+		close(done)
+	}()
+	runMonitor(ctx, healthy, s, 0, stubs.NewTestLogger())
+	<-done
+
+	// delays of the failing monitor: 5ms, 10ms, 20ms, 40ms...
+	req.LessOrEqual(atomic.LoadInt32(&failing.calls), int32(5))
+	req.Greater(atomic.LoadInt32(&healthy.calls), atomic.LoadInt32(&failing.calls))
+}
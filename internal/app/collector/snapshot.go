@@ -0,0 +1,107 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+)
+
+// Snapshot is an immutable copy of the metrics provided by the collector monitors.
+type Snapshot struct {
+	Metrics map[monitors.MetricName]float64
+	// Decimals keeps the exact decimal values of Metrics, which may be lost by the float64 conversion
+	Decimals map[monitors.MetricName]string
+	Vectors  map[monitors.MetricName]VectorSnapshot
+	// Histograms keeps the values of the histogram metrics, they aren't present in Metrics
+	Histograms map[monitors.MetricName]monitors.HistogramData
+	// UpdatedAt is the time of the last monitor run, keyed by the monitor name
+	UpdatedAt map[string]time.Time
+}
+
+type VectorSnapshot struct {
+	LabelNames []string
+	Values     []LabeledValue
+}
+
+type LabeledValue struct {
+	Labels monitors.Labels
+	Value  float64
+	// Decimal keeps the exact decimal Value, which may be lost by the float64 conversion
+	Decimal string
+}
+
+func newSnapshot() *Snapshot {
+	return &Snapshot{
+		Metrics:    make(map[monitors.MetricName]float64),
+		Decimals:   make(map[monitors.MetricName]string),
+		Vectors:    make(map[monitors.MetricName]VectorSnapshot),
+		Histograms: make(map[monitors.MetricName]monitors.HistogramData),
+		UpdatedAt:  make(map[string]time.Time),
+	}
+}
+
+// with returns a copy of the snapshot with the m monitor data replaced by the current one.
+func (s *Snapshot) with(m monitors.Monitor, updatedAt time.Time) *Snapshot {
+	out := s.copy()
+	for name, metric := range m.GetMetrics() {
+		if histogram, ok := metric.(*monitors.HistogramMetricValue); ok {
+			out.Histograms[name] = histogram.Histogram()
+			continue
+		}
+		out.Metrics[name] = metric.Get()
+		out.Decimals[name] = monitors.FormatDecimal(metric)
+	}
+	for name, vector := range m.GetMetricVectors() {
+		vs := VectorSnapshot{LabelNames: vector.LabelNames()}
+		for _, labels := range vector.Labels() {
+			vs.Values = append(vs.Values, LabeledValue{
+				Labels:  labels,
+				Value:   vector.Get(labels),
+				Decimal: vector.Decimal(labels),
+			})
+		}
+		out.Vectors[name] = vs
+	}
+	out.UpdatedAt[m.Name()] = updatedAt
+
+	return out
+}
+
+// without returns a copy of the snapshot with the metrics declared by the m monitor removed.
+func (s *Snapshot) without(m monitors.Monitor) *Snapshot {
+	out := s.copy()
+	for _, desc := range m.MetricDescs() {
+		delete(out.Metrics, desc.Name)
+		delete(out.Decimals, desc.Name)
+		delete(out.Vectors, desc.Name)
+		delete(out.Histograms, desc.Name)
+	}
+	delete(out.UpdatedAt, m.Name())
+	return out
+}
+
+func (s *Snapshot) copy() *Snapshot {
+	out := &Snapshot{
+		Metrics:    make(map[monitors.MetricName]float64, len(s.Metrics)),
+		Decimals:   make(map[monitors.MetricName]string, len(s.Decimals)),
+		Vectors:    make(map[monitors.MetricName]VectorSnapshot, len(s.Vectors)),
+		Histograms: make(map[monitors.MetricName]monitors.HistogramData, len(s.Histograms)),
+		UpdatedAt:  make(map[string]time.Time, len(s.UpdatedAt)+1),
+	}
+	for name, value := range s.Metrics {
+		out.Metrics[name] = value
+	}
+	for name, value := range s.Decimals {
+		out.Decimals[name] = value
+	}
+	for name, vector := range s.Vectors {
+		out.Vectors[name] = vector
+	}
+	for name, histogram := range s.Histograms {
+		out.Histograms[name] = histogram
+	}
+	for name, t := range s.UpdatedAt {
+		out.UpdatedAt[name] = t
+	}
+	return out
+}
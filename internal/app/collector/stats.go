@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
+)
+
+// MonitorStats describes the history of a monitor runs.
+type MonitorStats struct {
+	LastRun      time.Time
+	LastSuccess  time.Time
+	LastDuration time.Duration
+	LastError    error
+	Successes    uint64
+	Failures     uint64
+}
+
+// Up reports whether the last monitor run was successful.
+func (s MonitorStats) Up() bool {
+	return !s.LastRun.IsZero() && s.LastError == nil
+}
+
+// instrumentedMonitor records the stats of every Handler call of the wrapped monitor.
+type instrumentedMonitor struct {
+	monitors.Monitor
+	// onRun is called after every Handler call
+	onRun func(m monitors.Monitor)
+	// requestStats counts the API request errors of the monitor, optional
+	requestStats *source.RequestStats
+
+	stats MonitorStats
+	lock  sync.RWMutex
+}
+
+func (m *instrumentedMonitor) Handler(ctx context.Context) error {
+	start := time.Now()
+	err := m.Monitor.Handler(source.WithCaller(ctx, source.Caller{Monitor: m.Name(), Stats: m.requestStats}))
+	finish := time.Now()
+	if m.onRun != nil {
+		m.onRun(m.Monitor)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.stats.LastRun = finish
+	m.stats.LastDuration = finish.Sub(start)
+	m.stats.LastError = err
+	if err != nil {
+		m.stats.Failures++
+	} else {
+		m.stats.LastSuccess = finish
+		m.stats.Successes++
+	}
+
+	return err
+}
+
+func (m *instrumentedMonitor) Stats() MonitorStats {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.stats
+}
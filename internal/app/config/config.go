@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/vrischmann/envconfig"
@@ -15,12 +18,98 @@ const (
 )
 
 type CollectorConfig struct {
-	BassetContractsVersion        string `envconfig:"default=2"` // available values: 1 and 2
-	Source                        Source
-	Addresses                     Addresses
-	UpdateDataInterval            time.Duration `envconfig:"default=30s"`
-	DelegationsDistributionConfig DelegationsDistributionConfig
-	NetworkGeneration             string `envconfig:"default=columbus-5"` // available values: columbus-5
+	BassetContractsVersion        string                        `envconfig:"default=2" yaml:"basset_contracts_version"` // available values: 1 and 2
+	Source                        Source                        `yaml:"source"`
+	HTTPClient                    HTTPClientConfig              `yaml:"http_client"`
+	SourceProbe                   SourceProbeConfig             `yaml:"source_probe"`
+	RateLimit                     RateLimitConfig               `yaml:"rate_limit"`
+	ValidatorsCache               ValidatorsCacheConfig         `yaml:"validators_cache"`
+	Addresses                     Addresses                     `yaml:"addresses"`
+	UpdateDataInterval            time.Duration                 `envconfig:"default=30s" yaml:"update_data_interval"`
+	Scheduler                     SchedulerConfig               `yaml:"scheduler"`
+	DelegationsDistributionConfig DelegationsDistributionConfig `yaml:"delegations_distribution_config"`
+	NetworkGeneration             string                        `envconfig:"default=columbus-5" yaml:"network_generation"` // available values: columbus-5
+	Alerting                      AlertingConfig                `yaml:"alerting"`
+	Notifications                 NotificationsConfig           `yaml:"notifications"`
+	Telegram                      TelegramConfig                `yaml:"telegram"`
+	Slack                         SlackConfig                   `yaml:"slack"`
+	Webhook                       WebhookConfig                 `yaml:"webhook"`
+	State                         StateConfig                   `yaml:"state"`
+	Admin                         AdminConfig                   `yaml:"admin"`
+	// FetchConcurrency limits the concurrent requests of a monitor fetching the data per validator or per block.
+	FetchConcurrency int `envconfig:"default=8" yaml:"fetch_concurrency"`
+	// ShutdownTimeout limits the time for the HTTP server draining and running monitors completion on exit.
+	ShutdownTimeout time.Duration `envconfig:"default=5s" yaml:"shutdown_timeout"`
+	// EnabledMonitors limits the running monitors to the listed ones, all the monitors are enabled if it's empty.
+	EnabledMonitors []string `envconfig:"optional" yaml:"enabled_monitors"`
+	// DisabledMonitors are never run, even if they are listed in EnabledMonitors.
+	DisabledMonitors []string `envconfig:"optional" yaml:"disabled_monitors"`
+	// Deployment and ChainID are added as labels to every exported series of the monitored deployment.
+	Deployment string `envconfig:"default=default" yaml:"deployment"`
+	ChainID    string `envconfig:"default=columbus-5" yaml:"chain_id"`
+	// Deployments lists the hubs or networks monitored by a single process, each of them replaces
+	// the Deployment, ChainID, Source and Addresses values above. It can be set by the config file only.
+	Deployments []Deployment `envconfig:"-" yaml:"deployments"`
+}
+
+// Deployment is a monitored hub or network. The empty BassetContractsVersion, NetworkGeneration and Source values
+// are inherited from the CollectorConfig, the addresses are never inherited.
+type Deployment struct {
+	Name                   string    `yaml:"name"`
+	ChainID                string    `yaml:"chain_id"`
+	BassetContractsVersion string    `yaml:"basset_contracts_version"`
+	NetworkGeneration      string    `yaml:"network_generation"`
+	Source                 Source    `yaml:"source"`
+	Addresses              Addresses `yaml:"addresses"`
+}
+
+// DeploymentConfigs returns a config per monitored deployment. The config itself is the only deployment
+// if Deployments is empty.
+func (c CollectorConfig) DeploymentConfigs() []CollectorConfig {
+	if len(c.Deployments) == 0 {
+		return []CollectorConfig{c}
+	}
+
+	configs := make([]CollectorConfig, 0, len(c.Deployments))
+	for _, d := range c.Deployments {
+		cfg := c
+		cfg.Deployments = nil
+		cfg.Deployment = d.Name
+		cfg.ChainID = d.ChainID
+		cfg.Addresses = d.Addresses
+		if d.BassetContractsVersion != "" {
+			cfg.BassetContractsVersion = d.BassetContractsVersion
+		}
+		if d.NetworkGeneration != "" {
+			cfg.NetworkGeneration = d.NetworkGeneration
+		}
+		if len(d.Source.Endpoints) > 0 {
+			cfg.Source.Endpoints = d.Source.Endpoints
+		}
+		if len(d.Source.Schemes) > 0 {
+			cfg.Source.Schemes = d.Source.Schemes
+		}
+		configs = append(configs, cfg)
+	}
+	return configs
+}
+
+// MonitorEnabled reports whether the monitor with the given name is enabled by EnabledMonitors and DisabledMonitors.
+func (c CollectorConfig) MonitorEnabled(monitorName string) bool {
+	for _, name := range c.DisabledMonitors {
+		if name == monitorName {
+			return false
+		}
+	}
+	if len(c.EnabledMonitors) == 0 {
+		return true
+	}
+	for _, name := range c.EnabledMonitors {
+		if name == monitorName {
+			return true
+		}
+	}
+	return false
 }
 
 func NewCollectorConfig() (CollectorConfig, error) {
@@ -33,20 +122,271 @@ func NewCollectorConfig() (CollectorConfig, error) {
 }
 
 type Source struct {
-	Endpoints []string `envconfig:"default=fcd.terra.dev"`
-	Schemes   []string `envconfig:"default=https"`
+	Endpoints []string `envconfig:"default=fcd.terra.dev" yaml:"endpoints"`
+	Schemes   []string `envconfig:"default=https" yaml:"schemes"`
+}
+
+// HTTPClientConfig tunes the HTTP transport shared by all the monitors of a deployment.
+type HTTPClientConfig struct {
+	// MaxIdleConnsPerHost limits the keep-alive connections kept open to every endpoint.
+	MaxIdleConnsPerHost int `envconfig:"default=16" yaml:"max_idle_conns_per_host"`
+	// MaxConnsPerHost limits the number of the connections to every endpoint, 0 means no limit.
+	MaxConnsPerHost int `envconfig:"default=64" yaml:"max_conns_per_host"`
+	// IdleConnTimeout is the time an idle keep-alive connection is kept open.
+	IdleConnTimeout time.Duration `envconfig:"default=90s" yaml:"idle_conn_timeout"`
+	// FailbackInterval is the time after which the requests are sent to the most prioritized endpoint again
+	// once the client has failed over to a backup one.
+	FailbackInterval time.Duration `envconfig:"default=5m" yaml:"failback_interval"`
+	// RequestTimeout limits the request to every endpoint, the timed out request fails over to the next endpoint,
+	// 0 means no limit.
+	RequestTimeout time.Duration `envconfig:"default=15s" yaml:"request_timeout"`
+	// MaxRetries is the number of the retries of a request failed with a network error or a 5xx or 429 status.
+	MaxRetries int `envconfig:"default=2" yaml:"max_retries"`
+	// RetryBackoff is the delay before the first retry, it doubles on every next retry up to MaxRetryBackoff.
+	RetryBackoff    time.Duration        `envconfig:"default=500ms" yaml:"retry_backoff"`
+	MaxRetryBackoff time.Duration        `envconfig:"default=5s" yaml:"max_retry_backoff"`
+	CircuitBreaker  CircuitBreakerConfig `yaml:"circuit_breaker"`
+}
+
+// CircuitBreakerConfig configures the circuit breaker of every source endpoint.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of the consecutive failed requests opening the circuit, 0 disables the breaker.
+	FailureThreshold int `envconfig:"default=5" yaml:"failure_threshold"`
+	// OpenTimeout is the time the open circuit endpoint is skipped before the trial requests are let through.
+	OpenTimeout time.Duration `envconfig:"default=30s" yaml:"open_timeout"`
+	// SuccessThreshold is the number of the successful trial requests closing the circuit.
+	SuccessThreshold int `envconfig:"default=1" yaml:"success_threshold"`
+}
+
+// SourceProbeConfig configures the background probe of the latest block of every source endpoint.
+type SourceProbeConfig struct {
+	// Interval is the time between the probes, 0 disables the probe.
+	Interval time.Duration `envconfig:"default=0s" yaml:"interval"`
+	// Timeout limits every endpoint probe.
+	Timeout time.Duration `envconfig:"default=5s" yaml:"timeout"`
+}
+
+// AlertingConfig configures the built-in alerting.
+type AlertingConfig struct {
+	// RulesFile is the path to the YAML or JSON file with the alert rules, the alerting is disabled if it's empty.
+	RulesFile string `envconfig:"optional" yaml:"rules_file"`
+	// ResolvedRetention is the time the resolved alerts are kept and reported by the alerts API.
+	ResolvedRetention time.Duration `envconfig:"default=15m" yaml:"resolved_retention"`
+}
+
+// NotificationsConfig configures the delivery of the alert notifications to the Telegram, Slack and webhook channels.
+type NotificationsConfig struct {
+	// Timeout limits every delivery attempt, 0 means no limit.
+	Timeout time.Duration `envconfig:"default=10s" yaml:"timeout"`
+	// MaxRetries is the number of the retries of a delivery failed with a network error or a 5xx/429 status.
+	MaxRetries int `envconfig:"default=3" yaml:"max_retries"`
+	// RetryBackoff is the delay before the first retry, it doubles on every next retry up to MaxRetryBackoff.
+	RetryBackoff    time.Duration `envconfig:"default=1s" yaml:"retry_backoff"`
+	MaxRetryBackoff time.Duration `envconfig:"default=1m" yaml:"max_retry_backoff"`
+	// QueueSize is the number of the notifications waiting for the delivery to a channel, the rest are dropped.
+	QueueSize int `envconfig:"default=100" yaml:"queue_size"`
+	// TemplateFile is the path to the Go text/template file of the Telegram and Slack messages, optional.
+	TemplateFile string `envconfig:"optional" yaml:"template_file"`
+	// GroupWait is the time the first notification of a group of alerts (by the rule and the deployment) is delayed
+	// for, so the alerts firing at once are sent in a single message.
+	GroupWait time.Duration `envconfig:"default=30s" yaml:"group_wait"`
+	// GroupInterval is the minimal time between the notifications of the changes of a group.
+	GroupInterval time.Duration `envconfig:"default=5m" yaml:"group_interval"`
+	// RepeatInterval is the time the notification of an unchanged group with the firing alerts is repeated after.
+	RepeatInterval time.Duration `envconfig:"default=4h" yaml:"repeat_interval"`
+	// StateFile is the path to the JSON file keeping the notified groups, the silences and the maintenance windows
+	// across the restarts, the state is kept in memory only if it's empty.
+	StateFile string `envconfig:"optional" yaml:"state_file"`
+}
+
+// StateConfig configures the state of the monitors kept across the restarts, e.g. the last checked block
+// and transaction and the accumulated counters.
+type StateConfig struct {
+	// File is the path to the JSON file of the monitors state, the state is kept in memory only if it's empty.
+	// The file is not reloaded with the config.
+	File string `envconfig:"optional" yaml:"file"`
+	// MaxBackfillBlocks limits the blocks checked for the missed signatures at once, e.g. after a restart,
+	// the older blocks are skipped. 0 means no limit.
+	MaxBackfillBlocks int `envconfig:"default=1000" yaml:"max_backfill_blocks"`
+	// MaxBackfillTxPages limits the pages of the bot transactions fetched at once, the older transactions
+	// are skipped.
+	MaxBackfillTxPages int `envconfig:"default=10" yaml:"max_backfill_tx_pages"`
+}
+
+// AdminConfig configures the access to the admin API: the config reload, the silences and the maintenance windows.
+type AdminConfig struct {
+	// Token is the bearer token of the admin API requests, the admin API is disabled if it's empty.
+	// The token is not reloaded with the config.
+	Token string `envconfig:"optional" yaml:"token"`
+}
+
+// TelegramConfig configures the Telegram Bot API channel, it's disabled if BotToken is empty.
+// The variables are shared with the Grafana notifiers: TELEGRAM_BOTTOKEN and TELEGRAM_CHAT_ID.
+type TelegramConfig struct {
+	BotToken string `envconfig:"optional" yaml:"bot_token"`
+	ChatID   string `envconfig:"optional" yaml:"chat_id"`
+	APIURL   string `envconfig:"default=https://api.telegram.org" yaml:"api_url"`
+}
+
+// SlackConfig configures the Slack incoming webhook channel, it's disabled if WebhookURL is empty.
+type SlackConfig struct {
+	WebhookURL string `envconfig:"optional" yaml:"webhook_url"`
+}
+
+// WebhookConfig configures the generic JSON webhook channel, it's disabled if URL is empty.
+type WebhookConfig struct {
+	URL string `envconfig:"optional" yaml:"url"`
+}
+
+// RateLimitConfig limits the API requests of all the monitors of the process with the token buckets.
+type RateLimitConfig struct {
+	// RequestsPerSecond limits the requests to all the endpoints, 0 means no limit.
+	RequestsPerSecond float64 `envconfig:"default=0" yaml:"requests_per_second"`
+	// EndpointRequestsPerSecond limits the requests to every endpoint, 0 means no limit.
+	EndpointRequestsPerSecond float64 `envconfig:"default=10" yaml:"endpoint_requests_per_second"`
+	// EndpointQuotas overrides EndpointRequestsPerSecond for particular endpoints, keyed by the endpoint host.
+	// Format: RATE_LIMIT_ENDPOINT_QUOTAS=fcd.terra.dev:5,bombay-fcd.terra.dev:20
+	EndpointQuotas EndpointQuotas `envconfig:"optional" yaml:"endpoint_quotas"`
+	// Burst is the number of the requests allowed at once above the limits.
+	Burst int `envconfig:"default=20" yaml:"burst"`
+}
+
+// EndpointLimit returns the requests per second limit of the endpoint host, 0 means no limit.
+func (c RateLimitConfig) EndpointLimit(host string) float64 {
+	if quota, found := c.EndpointQuotas[host]; found {
+		return quota
+	}
+	return c.EndpointRequestsPerSecond
+}
+
+func (c RateLimitConfig) Equal(other RateLimitConfig) bool {
+	return reflect.DeepEqual(c, other)
+}
+
+// EndpointQuotas maps the endpoint hosts to their requests per second limits.
+type EndpointQuotas map[string]float64
+
+func (q *EndpointQuotas) Unmarshal(s string) error {
+	quotas := make(EndpointQuotas)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		// the host may contain a port, so the quota is after the last colon
+		sep := strings.LastIndex(pair, ":")
+		if sep < 0 {
+			return fmt.Errorf("invalid endpoint quota \"%s\", expected format is <host>:<requests per second>", pair)
+		}
+		quota, err := strconv.ParseFloat(strings.TrimSpace(pair[sep+1:]), 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse quota of endpoint %s: %w", pair[:sep], err)
+		}
+		quotas[strings.TrimSpace(pair[:sep])] = quota
+	}
+	*q = quotas
+	return nil
+}
+
+// ValidatorsCacheConfig configures the whitelisted validators data cache shared by the validators monitors.
+type ValidatorsCacheConfig struct {
+	// TTL is the time the validators data is considered fresh, it should be less than the monitors update intervals.
+	TTL time.Duration `envconfig:"default=20s" yaml:"ttl"`
+	// MaxStale is the time the expired validators data is used after TTL if the source is unavailable.
+	MaxStale time.Duration `envconfig:"default=10m" yaml:"max_stale"`
 }
 
 type Addresses struct {
-	HubContract                 string `envconfig:"default=terra1mtwph2juhj0rvjz7dy92gvl6xvukaxu8rfv8ts"`
-	RewardContract              string `envconfig:"default=terra17yap3mhph35pcwvhza38c2lkj7gzywzy05h7l0"`
-	BlunaTokenInfoContract      string `envconfig:"default=terra1kc87mu460fwkqte29rquh4hc20m54fxwtsx7gp"`
-	ValidatorsRegistryContract  string `envconfig:"default=terra_dummy_validators_registry"` // TODO: actualize.
-	RewardsDispatcherContract   string `envconfig:"default=terra_dummy_rewards_dispatcher"`  // TODO: actualize.
-	AirDropRegistryContract     string `envconfig:"default=terra_dummy_airdrop"`             // TODO: actualize.
-	UpdateGlobalIndexBotAddress string `envconfig:"default=terra1eqpx4zr2vm9jwu2vas5rh6704f6zzglsayf2fy"`
+	HubContract                 string `envconfig:"default=terra1mtwph2juhj0rvjz7dy92gvl6xvukaxu8rfv8ts" yaml:"hub_contract"`
+	RewardContract              string `envconfig:"default=terra17yap3mhph35pcwvhza38c2lkj7gzywzy05h7l0" yaml:"reward_contract"`
+	BlunaTokenInfoContract      string `envconfig:"default=terra1kc87mu460fwkqte29rquh4hc20m54fxwtsx7gp" yaml:"bluna_token_info_contract"`
+	ValidatorsRegistryContract  string `envconfig:"default=terra_dummy_validators_registry" yaml:"validators_registry_contract"` // TODO: actualize.
+	RewardsDispatcherContract   string `envconfig:"default=terra_dummy_rewards_dispatcher" yaml:"rewards_dispatcher_contract"`   // TODO: actualize.
+	AirDropRegistryContract     string `envconfig:"default=terra_dummy_airdrop" yaml:"air_drop_registry_contract"`               // TODO: actualize.
+	UpdateGlobalIndexBotAddress string `envconfig:"default=terra1eqpx4zr2vm9jwu2vas5rh6704f6zzglsayf2fy" yaml:"update_global_index_bot_address"`
+}
+
+// AddressName is the name of an Addresses field, used by the monitors to declare the addresses they require.
+type AddressName string
+
+const (
+	AddressHubContract                AddressName = "HubContract"
+	AddressRewardContract             AddressName = "RewardContract"
+	AddressBlunaTokenInfoContract     AddressName = "BlunaTokenInfoContract"
+	AddressValidatorsRegistryContract AddressName = "ValidatorsRegistryContract"
+	AddressRewardsDispatcherContract  AddressName = "RewardsDispatcherContract"
+	AddressAirDropRegistryContract    AddressName = "AirDropRegistryContract"
+	AddressUpdateGlobalIndexBot       AddressName = "UpdateGlobalIndexBotAddress"
+)
+
+// Get returns the address by the Addresses field name.
+func (a Addresses) Get(name AddressName) (string, error) {
+	switch name {
+	case AddressHubContract:
+		return a.HubContract, nil
+	case AddressRewardContract:
+		return a.RewardContract, nil
+	case AddressBlunaTokenInfoContract:
+		return a.BlunaTokenInfoContract, nil
+	case AddressValidatorsRegistryContract:
+		return a.ValidatorsRegistryContract, nil
+	case AddressRewardsDispatcherContract:
+		return a.RewardsDispatcherContract, nil
+	case AddressAirDropRegistryContract:
+		return a.AirDropRegistryContract, nil
+	case AddressUpdateGlobalIndexBot:
+		return a.UpdateGlobalIndexBotAddress, nil
+	default:
+		return "", fmt.Errorf("unknown address \"%s\"", name)
+	}
+}
+
+// IsAddressSet reports whether the address is configured, i.e. it's neither empty nor a dummy placeholder
+// like the default "terra_dummy_airdrop".
+func IsAddressSet(address string) bool {
+	return address != "" && !strings.Contains(address, "dummy")
 }
 
 type DelegationsDistributionConfig struct {
-	NumMedianAbsoluteDeviations int64 `envconfig:"default=3"`
+	NumMedianAbsoluteDeviations int64 `envconfig:"default=3" yaml:"num_median_absolute_deviations"`
+}
+
+type SchedulerConfig struct {
+	// Intervals overrides UpdateDataInterval for particular monitors, keyed by the monitor name.
+	// Format: SCHEDULER_INTERVALS=Slashing:2m,OracleVotesMonitor:2m
+	Intervals MonitorIntervals `envconfig:"optional" yaml:"intervals"`
+	// JitterFactor is the max fraction of the interval randomly added to every delay between monitor runs.
+	JitterFactor float64 `envconfig:"default=0.1" yaml:"jitter_factor"`
+	// MaxBackoff limits the exponential growth of the delay between runs of a failing monitor.
+	MaxBackoff time.Duration `envconfig:"default=10m" yaml:"max_backoff"`
+}
+
+// Interval returns the update interval of the monitor with the given name.
+func (c CollectorConfig) Interval(monitorName string) time.Duration {
+	if interval, found := c.Scheduler.Intervals[monitorName]; found {
+		return interval
+	}
+	return c.UpdateDataInterval
+}
+
+// MonitorIntervals maps monitor names to their update intervals.
+type MonitorIntervals map[string]time.Duration
+
+func (i *MonitorIntervals) Unmarshal(s string) error {
+	intervals := make(MonitorIntervals)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid monitor interval \"%s\", expected format is <monitor>:<duration>", pair)
+		}
+		interval, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return fmt.Errorf("failed to parse interval of monitor %s: %w", parts[0], err)
+		}
+		intervals[strings.TrimSpace(parts[0])] = interval
+	}
+	*i = intervals
+	return nil
 }
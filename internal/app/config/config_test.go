@@ -0,0 +1,253 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testHubContract = "terra1mtwph2juhj0rvjz7dy92gvl6xvukaxu8rfv8ts"
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func setEnv(t *testing.T, key, value string) {
+	require.NoError(t, os.Setenv(key, value))
+	t.Cleanup(func() {
+		os.Unsetenv(key)
+	})
+}
+
+func TestLoadCollectorConfigYAML(t *testing.T) {
+	req := require.New(t)
+
+	path := writeConfigFile(t, "config.yaml", `
+basset_contracts_version: "1"
+source:
+  endpoints: [fcd.terra.dev, scp.terra.dev]
+addresses:
+  hub_contract: terra1kc87mu460fwkqte29rquh4hc20m54fxwtsx7gp
+update_data_interval: 1m
+scheduler:
+  intervals:
+    Slashing: 2m
+disabled_monitors: [OracleVotesMonitor]
+`)
+	cfg, err := LoadCollectorConfig(path)
+	req.NoError(err)
+	req.Equal(V1Contracts, cfg.BassetContractsVersion)
+	req.Equal([]string{"fcd.terra.dev", "scp.terra.dev"}, cfg.Source.Endpoints)
+	req.Equal([]string{"https"}, cfg.Source.Schemes, "defaults are kept for the missing values")
+	req.Equal("terra1kc87mu460fwkqte29rquh4hc20m54fxwtsx7gp", cfg.Addresses.HubContract)
+	req.Equal(time.Minute, cfg.UpdateDataInterval)
+	req.Equal(2*time.Minute, cfg.Interval("Slashing"))
+	req.Equal([]string{"OracleVotesMonitor"}, cfg.DisabledMonitors)
+}
+
+func TestLoadCollectorConfigJSON(t *testing.T) {
+	req := require.New(t)
+
+	path := writeConfigFile(t, "config.json", `{"update_data_interval": "45s", "enabled_monitors": ["HubState"]}`)
+	cfg, err := LoadCollectorConfig(path)
+	req.NoError(err)
+	req.Equal(45*time.Second, cfg.UpdateDataInterval)
+	req.Equal([]string{"HubState"}, cfg.EnabledMonitors)
+}
+
+func TestLoadCollectorConfigEnvOverridesFile(t *testing.T) {
+	req := require.New(t)
+
+	path := writeConfigFile(t, "config.yaml", `
+update_data_interval: 1m
+addresses:
+  hub_contract: terra1kc87mu460fwkqte29rquh4hc20m54fxwtsx7gp
+`)
+	setEnv(t, "UPDATE_DATA_INTERVAL", "10s")
+	setEnv(t, "ADDRESSES_HUB_CONTRACT", testHubContract)
+
+	cfg, err := LoadCollectorConfig(path)
+	req.NoError(err)
+	req.Equal(10*time.Second, cfg.UpdateDataInterval)
+	req.Equal(testHubContract, cfg.Addresses.HubContract)
+}
+
+func TestLoadCollectorConfigUnknownKey(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "update_interval: 1m\n")
+	_, err := LoadCollectorConfig(path)
+	require.Error(t, err)
+}
+
+func TestLoadCollectorConfigMissingFile(t *testing.T) {
+	_, err := LoadCollectorConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestValidate(t *testing.T) {
+	req := require.New(t)
+
+	cfg, err := NewCollectorConfig()
+	req.NoError(err)
+	req.NoError(cfg.Validate())
+
+	cfg.BassetContractsVersion = "3"
+	cfg.NetworkGeneration = "columbus-4"
+	cfg.Addresses.HubContract = "terra1invalid"
+	cfg.Addresses.RewardContract = "cosmos1qypqxpq9qcrsszg2pvxq6rs0zqg3yyc5lzv7xu"
+	cfg.Addresses.AirDropRegistryContract = ""
+	cfg.UpdateDataInterval = 0
+	cfg.Scheduler.Intervals = MonitorIntervals{"Slashing": -time.Second}
+	cfg.Scheduler.JitterFactor = 2
+
+	err = cfg.Validate()
+	var errs ValidationErrors
+	req.True(errors.As(err, &errs))
+	req.Len(errs, 7, "all the errors must be reported: %v", err)
+	req.Contains(err.Error(), "unsupported basset contracts version \"3\"")
+	req.Contains(err.Error(), "unsupported network generation \"columbus-4\"")
+	req.Contains(err.Error(), "invalid HubContract address")
+	req.Contains(err.Error(), "expected \"terra\" prefix, got \"cosmos\"")
+	req.Contains(err.Error(), "update data interval must be positive")
+	req.Contains(err.Error(), "Slashing monitor interval must be positive")
+	req.Contains(err.Error(), "jitter factor")
+}
+
+func TestEnvKeys(t *testing.T) {
+	require.Equal(t, []string{
+		"ADDRESSES_HUBCONTRACT",
+		"ADDRESSES_HUB_CONTRACT",
+		"addresses_hub_contract",
+		"addresses_hubcontract",
+	}, envKeys("Addresses.HubContract"))
+}
+
+func TestDeploymentConfigs(t *testing.T) {
+	req := require.New(t)
+
+	path := writeConfigFile(t, "config.yaml", `
+basset_contracts_version: "2"
+source:
+  endpoints: [fcd.terra.dev]
+deployments:
+  - name: mainnet
+    chain_id: columbus-5
+    addresses:
+      hub_contract: terra1mtwph2juhj0rvjz7dy92gvl6xvukaxu8rfv8ts
+  - name: testnet
+    chain_id: bombay-12
+    basset_contracts_version: "1"
+    source:
+      endpoints: [bombay-fcd.terra.dev]
+    addresses:
+      hub_contract: terra1kc87mu460fwkqte29rquh4hc20m54fxwtsx7gp
+`)
+	cfg, err := LoadCollectorConfig(path)
+	req.NoError(err)
+
+	deployments := cfg.DeploymentConfigs()
+	req.Len(deployments, 2)
+
+	mainnet, testnet := deployments[0], deployments[1]
+	req.Equal("mainnet", mainnet.Deployment)
+	req.Equal("columbus-5", mainnet.ChainID)
+	req.Equal(V2Contracts, mainnet.BassetContractsVersion)
+	req.Equal([]string{"fcd.terra.dev"}, mainnet.Source.Endpoints)
+	req.Equal(testHubContract, mainnet.Addresses.HubContract)
+	req.Empty(mainnet.Addresses.RewardContract, "addresses must not be inherited")
+	req.Empty(mainnet.Deployments)
+
+	req.Equal("testnet", testnet.Deployment)
+	req.Equal("bombay-12", testnet.ChainID)
+	req.Equal(V1Contracts, testnet.BassetContractsVersion)
+	req.Equal([]string{"bombay-fcd.terra.dev"}, testnet.Source.Endpoints)
+	req.Equal([]string{"https"}, testnet.Source.Schemes)
+}
+
+func TestDeploymentConfigsSingle(t *testing.T) {
+	cfg, err := NewCollectorConfig()
+	require.NoError(t, err)
+
+	deployments := cfg.DeploymentConfigs()
+	require.Equal(t, []CollectorConfig{cfg}, deployments)
+	require.Equal(t, "default", deployments[0].Deployment)
+}
+
+func TestValidateDeployments(t *testing.T) {
+	req := require.New(t)
+
+	cfg, err := NewCollectorConfig()
+	req.NoError(err)
+	cfg.Deployments = []Deployment{
+		{Name: "mainnet", Addresses: Addresses{HubContract: testHubContract}},
+		{Name: "mainnet", BassetContractsVersion: "3"},
+		{Addresses: Addresses{HubContract: "terra1invalid"}},
+	}
+
+	err = cfg.Validate()
+	var errs ValidationErrors
+	req.True(errors.As(err, &errs))
+	req.Len(errs, 4, "all the errors must be reported: %v", err)
+	req.Contains(err.Error(), "duplicated deployment \"mainnet\"")
+	req.Contains(err.Error(), "deployment \"mainnet\": unsupported basset contracts version \"3\"")
+	req.Contains(err.Error(), "deployment name is empty")
+	req.Contains(err.Error(), "deployment \"\": invalid HubContract address")
+}
+
+func TestRateLimitEndpointQuotas(t *testing.T) {
+	req := require.New(t)
+
+	setEnv(t, "RATE_LIMIT_ENDPOINT_QUOTAS", "fcd.terra.dev:5, localhost:1317:0.5")
+	cfg, err := LoadCollectorConfig("")
+	req.NoError(err)
+	req.Equal(EndpointQuotas{"fcd.terra.dev": 5, "localhost:1317": 0.5}, cfg.RateLimit.EndpointQuotas)
+	req.Equal(5.0, cfg.RateLimit.EndpointLimit("fcd.terra.dev"))
+	req.Equal(cfg.RateLimit.EndpointRequestsPerSecond, cfg.RateLimit.EndpointLimit("scp.terra.dev"))
+
+	var quotas EndpointQuotas
+	req.Error(quotas.Unmarshal("fcd.terra.dev"))
+	req.Error(quotas.Unmarshal("fcd.terra.dev:fast"))
+}
+
+func TestNotifiersConfigEnv(t *testing.T) {
+	req := require.New(t)
+
+	// the variables of the Grafana notifiers are read as is
+	setEnv(t, "TELEGRAM_BOTTOKEN", "123:token")
+	setEnv(t, "TELEGRAM_CHAT_ID", "-100")
+	setEnv(t, "WEBHOOK_URL", "http://bot:8080/alerts")
+	cfg, err := LoadCollectorConfig("")
+	req.NoError(err)
+	req.Equal(TelegramConfig{BotToken: "123:token", ChatID: "-100", APIURL: "https://api.telegram.org"}, cfg.Telegram)
+	req.Equal("http://bot:8080/alerts", cfg.Webhook.URL)
+	req.Empty(cfg.Slack.WebhookURL)
+
+	setEnv(t, "TELEGRAM_CHAT_ID", "")
+	_, err = LoadCollectorConfig("")
+	req.EqualError(err, "invalid config: telegram chat id is required with the bot token")
+}
+
+func TestStateConfigEnv(t *testing.T) {
+	req := require.New(t)
+
+	cfg, err := LoadCollectorConfig("")
+	req.NoError(err)
+	req.Equal(StateConfig{MaxBackfillBlocks: 1000, MaxBackfillTxPages: 10}, cfg.State)
+
+	setEnv(t, "STATE_FILE", "/var/lib/terra-monitors/state.json")
+	setEnv(t, "STATE_MAX_BACKFILL_BLOCKS", "0")
+	cfg, err = LoadCollectorConfig("")
+	req.NoError(err)
+	req.Equal("/var/lib/terra-monitors/state.json", cfg.State.File)
+	req.Equal(0, cfg.State.MaxBackfillBlocks)
+
+	setEnv(t, "STATE_MAX_BACKFILL_TX_PAGES", "0")
+	_, err = LoadCollectorConfig("")
+	req.EqualError(err, "invalid config: state max backfill tx pages must be positive, got 0")
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LoadCollectorConfig builds the config from the defaults, the YAML/JSON file at path (optional) and the environment
+// variables, the latter take precedence over the file values. The resulting config is validated.
+func LoadCollectorConfig(path string) (CollectorConfig, error) {
+	envConfig, err := NewCollectorConfig()
+	if err != nil {
+		return envConfig, err
+	}
+
+	// the file is decoded onto a separately loaded config, so the maps of envConfig are not modified
+	config, err := NewCollectorConfig()
+	if err != nil {
+		return config, err
+	}
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return config, fmt.Errorf("failed to read config file: %w", err)
+		}
+		// JSON is a subset of YAML, so the same decoder is used for both formats
+		if err := yaml.UnmarshalStrict(data, &config); err != nil {
+			return config, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		overrideFromEnv(reflect.ValueOf(&config).Elem(), reflect.ValueOf(envConfig), "")
+	}
+
+	if err := config.Validate(); err != nil {
+		return config, fmt.Errorf("invalid config: %w", err)
+	}
+	return config, nil
+}
+
+// overrideFromEnv copies the dst fields set in the environment from src, which is loaded by envconfig.
+func overrideFromEnv(dst, src reflect.Value, parentName string) {
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+		name := field.Name
+		if parentName != "" {
+			name = parentName + "." + name
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			overrideFromEnv(dst.Field(i), src.Field(i), name)
+			continue
+		}
+		if isEnvSet(name) {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+}
+
+// isEnvSet reports whether the environment variable of the field is set. The variable names are built
+// the same way envconfig does, e.g. "Addresses.HubContract" is read from ADDRESSES_HUB_CONTRACT or ADDRESSES_HUBCONTRACT.
+func isEnvSet(fieldName string) bool {
+	for _, key := range envKeys(fieldName) {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func envKeys(fieldName string) []string {
+	var withUnderscores, plain bytes.Buffer
+	name := []rune(fieldName)
+	wroteUnderscore := false
+	for i, r := range name {
+		if r == '.' {
+			withUnderscores.WriteRune('_')
+			plain.WriteRune('_')
+			wroteUnderscore = true
+			continue
+		}
+
+		prevOrNextLower := i+1 < len(name) && i-1 > 0 && (unicode.IsLower(name[i+1]) || unicode.IsLower(name[i-1]))
+		if i > 0 && unicode.IsUpper(r) && prevOrNextLower && !wroteUnderscore {
+			withUnderscores.WriteRune('_')
+		}
+		withUnderscores.WriteRune(r)
+		plain.WriteRune(r)
+		wroteUnderscore = false
+	}
+
+	keys := map[string]struct{}{
+		strings.ToUpper(withUnderscores.String()): {},
+		strings.ToLower(withUnderscores.String()): {},
+		strings.ToUpper(plain.String()):           {},
+		strings.ToLower(plain.String()):           {},
+	}
+	result := make([]string, 0, len(keys))
+	for key := range keys {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+)
+
+const terraAddressPrefix = "terra"
+
+// ValidationErrors collects all the config errors, so they can be fixed at once.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate checks the config values. It returns ValidationErrors with every found error or nil.
+func (c CollectorConfig) Validate() error {
+	var errs ValidationErrors
+	addErr := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	names := make(map[string]struct{})
+	for _, d := range c.DeploymentConfigs() {
+		if d.Deployment == "" {
+			addErr("deployment name is empty")
+		} else if _, found := names[d.Deployment]; found {
+			addErr("duplicated deployment \"%s\"", d.Deployment)
+		}
+		names[d.Deployment] = struct{}{}
+
+		for _, err := range d.validateDeployment() {
+			if len(c.Deployments) > 0 {
+				err = fmt.Errorf("deployment \"%s\": %w", d.Deployment, err)
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	if c.HTTPClient.MaxIdleConnsPerHost <= 0 {
+		addErr("http client max idle connections per host must be positive, got %d", c.HTTPClient.MaxIdleConnsPerHost)
+	}
+	if c.HTTPClient.MaxConnsPerHost < 0 {
+		addErr("http client max connections per host must not be negative, got %d", c.HTTPClient.MaxConnsPerHost)
+	}
+	if c.HTTPClient.IdleConnTimeout <= 0 {
+		addErr("http client idle connection timeout must be positive, got %s", c.HTTPClient.IdleConnTimeout)
+	}
+	if c.HTTPClient.FailbackInterval <= 0 {
+		addErr("http client failback interval must be positive, got %s", c.HTTPClient.FailbackInterval)
+	}
+	if c.HTTPClient.RequestTimeout < 0 {
+		addErr("http client request timeout must not be negative, got %s", c.HTTPClient.RequestTimeout)
+	}
+	if c.HTTPClient.MaxRetries < 0 {
+		addErr("http client max retries must not be negative, got %d", c.HTTPClient.MaxRetries)
+	}
+	if c.HTTPClient.RetryBackoff <= 0 {
+		addErr("http client retry backoff must be positive, got %s", c.HTTPClient.RetryBackoff)
+	}
+	if c.HTTPClient.MaxRetryBackoff < c.HTTPClient.RetryBackoff {
+		addErr("http client max retry backoff must not be less than the retry backoff %s, got %s",
+			c.HTTPClient.RetryBackoff, c.HTTPClient.MaxRetryBackoff)
+	}
+	if c.HTTPClient.CircuitBreaker.FailureThreshold < 0 {
+		addErr("circuit breaker failure threshold must not be negative, got %d",
+			c.HTTPClient.CircuitBreaker.FailureThreshold)
+	}
+	if c.HTTPClient.CircuitBreaker.FailureThreshold > 0 {
+		if c.HTTPClient.CircuitBreaker.OpenTimeout <= 0 {
+			addErr("circuit breaker open timeout must be positive, got %s", c.HTTPClient.CircuitBreaker.OpenTimeout)
+		}
+		if c.HTTPClient.CircuitBreaker.SuccessThreshold <= 0 {
+			addErr("circuit breaker success threshold must be positive, got %d",
+				c.HTTPClient.CircuitBreaker.SuccessThreshold)
+		}
+	}
+	if c.SourceProbe.Interval < 0 {
+		addErr("source probe interval must not be negative, got %s", c.SourceProbe.Interval)
+	}
+	if c.SourceProbe.Interval > 0 && c.SourceProbe.Timeout <= 0 {
+		addErr("source probe timeout must be positive, got %s", c.SourceProbe.Timeout)
+	}
+	if c.RateLimit.RequestsPerSecond < 0 {
+		addErr("rate limit requests per second must not be negative, got %v", c.RateLimit.RequestsPerSecond)
+	}
+	if c.RateLimit.EndpointRequestsPerSecond < 0 {
+		addErr("rate limit endpoint requests per second must not be negative, got %v",
+			c.RateLimit.EndpointRequestsPerSecond)
+	}
+	for host, quota := range c.RateLimit.EndpointQuotas {
+		if quota < 0 {
+			addErr("rate limit quota of endpoint %s must not be negative, got %v", host, quota)
+		}
+	}
+	if c.RateLimit.Burst <= 0 {
+		addErr("rate limit burst must be positive, got %d", c.RateLimit.Burst)
+	}
+	if c.ValidatorsCache.TTL <= 0 {
+		addErr("validators cache ttl must be positive, got %s", c.ValidatorsCache.TTL)
+	}
+	if c.ValidatorsCache.MaxStale < 0 {
+		addErr("validators cache max stale must not be negative, got %s", c.ValidatorsCache.MaxStale)
+	}
+	if c.Alerting.ResolvedRetention < 0 {
+		addErr("alerting resolved retention must not be negative, got %s", c.Alerting.ResolvedRetention)
+	}
+	if c.Notifications.Timeout < 0 {
+		addErr("notifications timeout must not be negative, got %s", c.Notifications.Timeout)
+	}
+	if c.Notifications.MaxRetries < 0 {
+		addErr("notifications max retries must not be negative, got %d", c.Notifications.MaxRetries)
+	}
+	if c.Notifications.RetryBackoff <= 0 {
+		addErr("notifications retry backoff must be positive, got %s", c.Notifications.RetryBackoff)
+	}
+	if c.Notifications.MaxRetryBackoff < c.Notifications.RetryBackoff {
+		addErr("notifications max retry backoff must not be less than the retry backoff %s, got %s",
+			c.Notifications.RetryBackoff, c.Notifications.MaxRetryBackoff)
+	}
+	if c.Notifications.QueueSize <= 0 {
+		addErr("notifications queue size must be positive, got %d", c.Notifications.QueueSize)
+	}
+	if c.Notifications.GroupWait < 0 {
+		addErr("notifications group wait must not be negative, got %s", c.Notifications.GroupWait)
+	}
+	if c.Notifications.GroupInterval <= 0 {
+		addErr("notifications group interval must be positive, got %s", c.Notifications.GroupInterval)
+	}
+	if c.Notifications.RepeatInterval < c.Notifications.GroupInterval {
+		addErr("notifications repeat interval must not be less than the group interval %s, got %s",
+			c.Notifications.GroupInterval, c.Notifications.RepeatInterval)
+	}
+	if c.State.MaxBackfillBlocks < 0 {
+		addErr("state max backfill blocks must not be negative, got %d", c.State.MaxBackfillBlocks)
+	}
+	if c.State.MaxBackfillTxPages <= 0 {
+		addErr("state max backfill tx pages must be positive, got %d", c.State.MaxBackfillTxPages)
+	}
+	if c.Telegram.BotToken != "" && c.Telegram.ChatID == "" {
+		addErr("telegram chat id is required with the bot token")
+	}
+	if c.FetchConcurrency <= 0 {
+		addErr("fetch concurrency must be positive, got %d", c.FetchConcurrency)
+	}
+	if c.UpdateDataInterval <= 0 {
+		addErr("update data interval must be positive, got %s", c.UpdateDataInterval)
+	}
+	for monitor, interval := range c.Scheduler.Intervals {
+		if interval <= 0 {
+			addErr("%s monitor interval must be positive, got %s", monitor, interval)
+		}
+	}
+	if c.Scheduler.JitterFactor < 0 || c.Scheduler.JitterFactor > 1 {
+		addErr("scheduler jitter factor must be in [0, 1], got %v", c.Scheduler.JitterFactor)
+	}
+	if c.Scheduler.MaxBackoff <= 0 {
+		addErr("scheduler max backoff must be positive, got %s", c.Scheduler.MaxBackoff)
+	}
+	if c.ShutdownTimeout <= 0 {
+		addErr("shutdown timeout must be positive, got %s", c.ShutdownTimeout)
+	}
+	if c.DelegationsDistributionConfig.NumMedianAbsoluteDeviations <= 0 {
+		addErr("delegations distribution number of median absolute deviations must be positive, got %d",
+			c.DelegationsDistributionConfig.NumMedianAbsoluteDeviations)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateDeployment checks the config values which may differ between the deployments.
+func (c CollectorConfig) validateDeployment() []error {
+	var errs []error
+	addErr := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.BassetContractsVersion != V1Contracts && c.BassetContractsVersion != V2Contracts {
+		addErr("unsupported basset contracts version \"%s\", available versions: %s, %s",
+			c.BassetContractsVersion, V1Contracts, V2Contracts)
+	}
+	if c.NetworkGeneration != NetworkGenerationColumbus5 {
+		addErr("unsupported network generation \"%s\", available generations: %s",
+			c.NetworkGeneration, NetworkGenerationColumbus5)
+	}
+
+	if len(c.Source.Endpoints) == 0 {
+		addErr("source endpoints are empty")
+	}
+	endpoints := make(map[string]bool)
+	for _, endpoint := range c.Source.Endpoints {
+		if endpoints[endpoint] {
+			addErr("duplicate source endpoint \"%s\"", endpoint)
+		}
+		endpoints[endpoint] = true
+	}
+	for _, scheme := range c.Source.Schemes {
+		if scheme != "http" && scheme != "https" {
+			addErr("unsupported source scheme \"%s\"", scheme)
+		}
+	}
+
+	for _, name := range []AddressName{
+		AddressHubContract,
+		AddressRewardContract,
+		AddressBlunaTokenInfoContract,
+		AddressValidatorsRegistryContract,
+		AddressRewardsDispatcherContract,
+		AddressAirDropRegistryContract,
+		AddressUpdateGlobalIndexBot,
+	} {
+		address, err := c.Addresses.Get(name)
+		if err != nil {
+			addErr("%v", err)
+			continue
+		}
+		// the monitors requiring not set addresses are skipped
+		if !IsAddressSet(address) {
+			continue
+		}
+		if err := validateTerraAddress(address); err != nil {
+			addErr("invalid %s address \"%s\": %v", name, address, err)
+		}
+	}
+	return errs
+}
+
+func validateTerraAddress(address string) error {
+	prefix, _, err := bech32.DecodeAndConvert(address)
+	if err != nil {
+		return err
+	}
+	if prefix != terraAddressPrefix {
+		return fmt.Errorf("expected \"%s\" prefix, got \"%s\"", terraAddressPrefix, prefix)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package extractor
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/lidofinance/terra-monitors/internal/app/collector"
 	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
@@ -9,87 +10,144 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func NewPromExtractor(c *collector.Collector, logger *logrus.Logger) PromExtractor {
-	p := PromExtractor{}
-	p.collector = c
-	p.Gauges = make(map[monitors.MetricName]prometheus.Gauge)
-	p.GaugeVectors = make(map[monitors.MetricName]*prometheus.GaugeVec)
-	p.GaugeMetrics = []monitors.MetricName{}
-	p.GaugeMetricVectors = []monitors.MetricName{}
-	p.log = logger
-	for _, m := range p.collector.ProvidedMetrics() {
-		p.addGauge(m)
-	}
-	for _, m := range p.collector.ProvidedMetricVectors() {
-		p.addGaugeVector(m)
-	}
-	return p
+// PromExtractor exports the metrics of the collectors group to prometheus. It implements prometheus.Collector
+// and emits the metrics values from the collectors snapshots on every scrape, so concurrent scrapes are safe.
+// Every series is labeled with the deployment name and chain id of its collector.
+// The set of the exported metrics is changed by the config reload, so PromExtractor is an unchecked collector.
+type PromExtractor struct {
+	group *collector.Group
+	// metrics caches the descriptors of the metrics declared by the collectors ever, guarded by lock
+	metrics map[monitors.MetricName]promMetric
+	lock    sync.Mutex
+	stats   monitorStatsCollector
+	log     *logrus.Logger
 }
 
-type PromExtractor struct {
-	collector          *collector.Collector
-	Gauges             map[monitors.MetricName]prometheus.Gauge
-	GaugeVectors       map[monitors.MetricName]*prometheus.GaugeVec
-	GaugeMetrics       []monitors.MetricName
-	GaugeMetricVectors []monitors.MetricName
-	log                *logrus.Logger
+// promMetric keeps the prometheus descriptors of the metric, the alias descriptor is nil
+// if the metric has no deprecated alias.
+type promMetric struct {
+	desc      *prometheus.Desc
+	aliasDesc *prometheus.Desc
+	valueType prometheus.ValueType
 }
 
-func (p *PromExtractor) addGauge(name monitors.MetricName) {
-	p.Gauges[name] = prometheus.NewGauge(
-		prometheus.GaugeOpts{
-			Name: string(name),
-		})
+func NewPromExtractor(g *collector.Group, logger *logrus.Logger) *PromExtractor {
+	return &PromExtractor{
+		group:   g,
+		metrics: make(map[monitors.MetricName]promMetric),
+		stats:   monitorStatsCollector{group: g},
+		log:     logger,
+	}
+}
 
-	prometheus.MustRegister(p.Gauges[name])
-	p.GaugeMetrics = append(p.GaugeMetrics, name)
+// promMetrics returns the descriptors of the metrics declared by the collectors.
+func (p *PromExtractor) promMetrics(collectors []*collector.Collector) map[monitors.MetricName]promMetric {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	// the deployments run the same monitors code, so the descriptors of the same metrics are equal
+	for _, c := range collectors {
+		for _, desc := range c.MetricDescs() {
+			if _, found := p.metrics[desc.Name]; !found {
+				p.metrics[desc.Name] = newPromMetric(desc)
+			}
+		}
+	}
+	metrics := make(map[monitors.MetricName]promMetric, len(p.metrics))
+	for name, metric := range p.metrics {
+		metrics[name] = metric
+	}
+	return metrics
 }
 
-func (p *PromExtractor) addGaugeVector(name monitors.MetricName) {
-	p.GaugeVectors[name] = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: string(name),
-		},
-		[]string{"label"},
-	)
+func newPromMetric(desc monitors.MetricDesc) promMetric {
+	help := desc.Help
+	if desc.Unit != "" {
+		help = fmt.Sprintf("%s Unit: %s.", help, desc.Unit)
+	}
+	labelNames := append(append([]string{}, desc.LabelNames...), collector.DeploymentLabel, collector.ChainIDLabel)
+	metric := promMetric{
+		desc:      prometheus.NewDesc(string(desc.Name), help, labelNames, nil),
+		valueType: valueType(desc.Kind),
+	}
+	if desc.DeprecatedAlias != "" {
+		aliasHelp := fmt.Sprintf("Deprecated, use %s instead. %s", desc.Name, help)
+		metric.aliasDesc = prometheus.NewDesc(string(desc.DeprecatedAlias), aliasHelp, labelNames, nil)
+	}
+	return metric
+}
 
-	prometheus.MustRegister(p.GaugeVectors[name])
-	p.GaugeMetricVectors = append(p.GaugeMetricVectors, name)
+// Describe sends no descriptors, which makes PromExtractor an unchecked collector.
+func (p *PromExtractor) Describe(chan<- *prometheus.Desc) {
 }
 
-func (p *PromExtractor) updateGaugeValue(name monitors.MetricName) error {
-	value, err := p.collector.Get(name)
-	if err != nil {
-		return fmt.Errorf("failed to update metric \"%s\": %w", name, err)
+func (p *PromExtractor) Collect(ch chan<- prometheus.Metric) {
+	collectors := p.group.Collectors()
+	metrics := p.promMetrics(collectors)
+	for _, c := range collectors {
+		p.collectSnapshot(ch, metrics, c.Snapshot(), c.Deployment(), c.ChainID())
 	}
-
-	p.Gauges[name].Set(value)
-	return nil
+	p.stats.Collect(ch)
 }
 
-func (p *PromExtractor) updateGaugeVectorValue(name monitors.MetricName) error {
-	vector, err := p.collector.GetVector(name)
-	if err != nil {
-		return fmt.Errorf("failed to update metric \"%s\": %w", name, err)
+// collectSnapshot emits the snapshot values, deployment and chainID are appended to the label values of every metric.
+func (p *PromExtractor) collectSnapshot(
+	ch chan<- prometheus.Metric,
+	metrics map[monitors.MetricName]promMetric,
+	snapshot *collector.Snapshot,
+	deployment, chainID string,
+) {
+	for name, value := range snapshot.Metrics {
+		p.collect(ch, metrics, name, func(desc *prometheus.Desc, valueType prometheus.ValueType) (prometheus.Metric, error) {
+			return prometheus.NewConstMetric(desc, valueType, value, deployment, chainID)
+		})
 	}
-	p.GaugeVectors[name].Reset()
-	for _, label := range vector.Labels() {
-		p.GaugeVectors[name].With(prometheus.Labels{"label": label}).Set(vector.Get(label))
+	for name, histogram := range snapshot.Histograms {
+		p.collect(ch, metrics, name, func(desc *prometheus.Desc, _ prometheus.ValueType) (prometheus.Metric, error) {
+			return prometheus.NewConstHistogram(desc, histogram.Count, histogram.Sum, histogram.Buckets, deployment, chainID)
+		})
+	}
+	for name, vector := range snapshot.Vectors {
+		for _, v := range vector.Values {
+			labelValues := make([]string, 0, len(vector.LabelNames)+2)
+			for _, labelName := range vector.LabelNames {
+				labelValues = append(labelValues, v.Labels[labelName])
+			}
+			labelValues = append(labelValues, deployment, chainID)
+			value := v.Value
+			p.collect(ch, metrics, name, func(desc *prometheus.Desc, valueType prometheus.ValueType) (prometheus.Metric, error) {
+				return prometheus.NewConstMetric(desc, valueType, value, labelValues...)
+			})
+		}
 	}
-	return nil
 }
 
-func (p PromExtractor) UpdateMetrics() {
-	for _, gaugeName := range p.GaugeMetrics {
-		err := p.updateGaugeValue(gaugeName)
-		if err != nil {
-			p.log.Errorf("failed to update gauge value \"%s\": %v", gaugeName, err)
-		}
+// collect emits the metric built by newMetric under the metric name and its deprecated alias.
+func (p *PromExtractor) collect(
+	ch chan<- prometheus.Metric,
+	metrics map[monitors.MetricName]promMetric,
+	name monitors.MetricName,
+	newMetric func(desc *prometheus.Desc, valueType prometheus.ValueType) (prometheus.Metric, error),
+) {
+	metric, found := metrics[name]
+	if !found {
+		return
 	}
-	for _, gaugeName := range p.GaugeMetricVectors {
-		err := p.updateGaugeVectorValue(gaugeName)
+	for _, desc := range []*prometheus.Desc{metric.desc, metric.aliasDesc} {
+		if desc == nil {
+			continue
+		}
+		m, err := newMetric(desc, metric.valueType)
 		if err != nil {
-			p.log.Errorf("failed to update gauge value \"%s\": %v", gaugeName, err)
+			p.log.Errorf("failed to collect metric \"%s\": %v", name, err)
+			continue
 		}
+		ch <- m
+	}
+}
+
+func valueType(kind monitors.MetricKind) prometheus.ValueType {
+	if kind == monitors.CounterKind {
+		return prometheus.CounterValue
 	}
+	return prometheus.GaugeValue
 }
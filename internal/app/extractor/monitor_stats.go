@@ -0,0 +1,262 @@
+package extractor
+
+import (
+	"github.com/lidofinance/terra-monitors/internal/app/collector"
+	"github.com/lidofinance/terra-monitors/internal/pkg/source"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	monitorLabel  = "monitor"
+	endpointLabel = "endpoint"
+)
+
+var (
+	monitorUpDesc = prometheus.NewDesc(
+		"monitor_up",
+		"Whether the last run of the monitor was successful (1) or not (0).",
+		[]string{monitorLabel, collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	monitorLastSuccessDesc = prometheus.NewDesc(
+		"monitor_last_success_timestamp_seconds",
+		"Unix time of the last successful run of the monitor, 0 if there were no successful runs.",
+		[]string{monitorLabel, collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	monitorLastRunDurationDesc = prometheus.NewDesc(
+		"monitor_last_run_duration_seconds",
+		"Duration of the last run of the monitor.",
+		[]string{monitorLabel, collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	monitorRunsDesc = prometheus.NewDesc(
+		"monitor_runs_total",
+		"Number of the monitor runs by result (success or failure).",
+		[]string{monitorLabel, "result", collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	monitorsReadyDesc = prometheus.NewDesc(
+		"monitors_ready",
+		"Whether all the monitors of the deployment have completed their first runs (1) or not (0).",
+		[]string{collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	sourceRequestErrorsDesc = prometheus.NewDesc(
+		"source_request_errors_total",
+		"Number of the failed API request attempts of the monitor by error class (network, http_status, decode or validation).",
+		[]string{monitorLabel, "class", collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	sourceRequestRetriesDesc = prometheus.NewDesc(
+		"source_request_retries_total",
+		"Number of the retried API requests of the monitor.",
+		[]string{monitorLabel, collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	sourceEndpointRequestsDesc = prometheus.NewDesc(
+		"source_endpoint_requests_total",
+		"Number of the API requests sent to the source endpoint by result (success or failure).",
+		[]string{endpointLabel, "result", collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	sourceEndpointRequestDurationDesc = prometheus.NewDesc(
+		"source_endpoint_request_duration_seconds",
+		"Duration of the API requests sent to the source endpoint.",
+		[]string{endpointLabel, collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	sourceEndpointActiveDesc = prometheus.NewDesc(
+		"source_endpoint_active",
+		"Whether the API requests are sent to the source endpoint first (1) or not (0).",
+		[]string{endpointLabel, collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	sourceEndpointCircuitBreakerStateDesc = prometheus.NewDesc(
+		"source_endpoint_circuit_breaker_state",
+		"Whether the circuit breaker of the source endpoint is in the state (closed, open or half_open) (1) or not (0).",
+		[]string{endpointLabel, "state", collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	sourceFailoversDesc = prometheus.NewDesc(
+		"source_failovers_total",
+		"Number of the switches from the active source endpoint to another one.",
+		[]string{collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	sourceEndpointProbeUpDesc = prometheus.NewDesc(
+		"source_endpoint_probe_up",
+		"Whether the last latest block probe of the source endpoint was successful (1) or not (0).",
+		[]string{endpointLabel, collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	sourceEndpointLatestBlockDesc = prometheus.NewDesc(
+		"source_endpoint_latest_block_height",
+		"Latest block height of the source endpoint by the last successful probe.",
+		[]string{endpointLabel, collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	sourceEndpointBlockLagDesc = prometheus.NewDesc(
+		"source_endpoint_block_lag",
+		"Number of blocks the source endpoint lags behind the most advanced endpoint by the last probes.",
+		[]string{endpointLabel, collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	sourceRateLimiterWaitDesc = prometheus.NewDesc(
+		"source_rate_limiter_wait_seconds",
+		"Time the API requests to the source endpoint spent waiting in the rate limiter shared by all the deployments.",
+		[]string{endpointLabel}, nil,
+	)
+	validatorsCacheRequestsDesc = prometheus.NewDesc(
+		"validators_cache_requests_total",
+		"Number of the validators repository cache requests by result (hit, miss, stale or error).",
+		[]string{"result", collector.DeploymentLabel, collector.ChainIDLabel}, nil,
+	)
+	configLastReloadSuccessfulDesc = prometheus.NewDesc(
+		"config_last_reload_successful",
+		"Whether the last config reload was successful (1) or not (0), 1 if there were no reloads.",
+		nil, nil,
+	)
+	configLastReloadSuccessDesc = prometheus.NewDesc(
+		"config_last_reload_success_timestamp_seconds",
+		"Unix time of the last successful config reload, 0 if there were no successful reloads.",
+		nil, nil,
+	)
+	configReloadsDesc = prometheus.NewDesc(
+		"config_reloads_total",
+		"Number of the config reloads by result (success or failure).",
+		[]string{"result"}, nil,
+	)
+)
+
+// monitorStatsCollector exports the collectors self-observability metrics, i.e. the monitors run and API requests
+// stats, the source endpoints and rate limiter stats, the validators cache stats and the config reloads stats.
+type monitorStatsCollector struct {
+	group *collector.Group
+}
+
+func (m monitorStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- monitorUpDesc
+	ch <- monitorLastSuccessDesc
+	ch <- monitorLastRunDurationDesc
+	ch <- monitorRunsDesc
+	ch <- monitorsReadyDesc
+	ch <- sourceRequestErrorsDesc
+	ch <- sourceRequestRetriesDesc
+	ch <- sourceEndpointRequestsDesc
+	ch <- sourceEndpointRequestDurationDesc
+	ch <- sourceEndpointActiveDesc
+	ch <- sourceEndpointCircuitBreakerStateDesc
+	ch <- sourceFailoversDesc
+	ch <- sourceEndpointProbeUpDesc
+	ch <- sourceEndpointLatestBlockDesc
+	ch <- sourceEndpointBlockLagDesc
+	ch <- sourceRateLimiterWaitDesc
+	ch <- validatorsCacheRequestsDesc
+	ch <- configLastReloadSuccessfulDesc
+	ch <- configLastReloadSuccessDesc
+	ch <- configReloadsDesc
+}
+
+func (m monitorStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.group.Collectors() {
+		deployment, chainID := c.Deployment(), c.ChainID()
+		requestErrors, requestRetries := c.RequestStats().Errors(), c.RequestStats().Retries()
+		var ready float64
+		if c.Ready() {
+			ready = 1
+		}
+		ch <- prometheus.MustNewConstMetric(monitorsReadyDesc, prometheus.GaugeValue, ready, deployment, chainID)
+		for name, stats := range c.MonitorsStats() {
+			var up, lastSuccess float64
+			if stats.Up() {
+				up = 1
+			}
+			if !stats.LastSuccess.IsZero() {
+				lastSuccess = float64(stats.LastSuccess.UnixNano()) / 1e9
+			}
+
+			ch <- prometheus.MustNewConstMetric(monitorUpDesc, prometheus.GaugeValue, up, name, deployment, chainID)
+			ch <- prometheus.MustNewConstMetric(monitorLastSuccessDesc, prometheus.GaugeValue, lastSuccess, name, deployment, chainID)
+			ch <- prometheus.MustNewConstMetric(monitorLastRunDurationDesc, prometheus.GaugeValue, stats.LastDuration.Seconds(),
+				name, deployment, chainID)
+			ch <- prometheus.MustNewConstMetric(monitorRunsDesc, prometheus.CounterValue, float64(stats.Successes),
+				name, "success", deployment, chainID)
+			ch <- prometheus.MustNewConstMetric(monitorRunsDesc, prometheus.CounterValue, float64(stats.Failures),
+				name, "failure", deployment, chainID)
+
+			for _, class := range source.ErrorClasses {
+				errors := requestErrors[source.RequestErrorsKey{Monitor: name, Class: class}]
+				ch <- prometheus.MustNewConstMetric(sourceRequestErrorsDesc, prometheus.CounterValue, float64(errors),
+					name, string(class), deployment, chainID)
+			}
+			ch <- prometheus.MustNewConstMetric(sourceRequestRetriesDesc, prometheus.CounterValue, float64(requestRetries[name]),
+				name, deployment, chainID)
+		}
+
+		collectEndpointsStats(ch, c)
+
+		cacheStats := c.ValidatorsCacheStats()
+		for result, value := range map[string]uint64{
+			"hit":   cacheStats.Hits,
+			"miss":  cacheStats.Misses,
+			"stale": cacheStats.StaleHits,
+			"error": cacheStats.Errors,
+		} {
+			ch <- prometheus.MustNewConstMetric(validatorsCacheRequestsDesc, prometheus.CounterValue, float64(value),
+				result, deployment, chainID)
+		}
+	}
+
+	for host, stats := range m.group.RateLimiterWaitStats() {
+		ch <- prometheus.MustNewConstHistogram(sourceRateLimiterWaitDesc, stats.Count, stats.Sum.Seconds(), stats.Buckets, host)
+	}
+
+	reloadStats := m.group.ReloadStats()
+	var reloadSuccessful, lastReloadSuccess float64
+	if reloadStats.LastError == nil {
+		reloadSuccessful = 1
+	}
+	if !reloadStats.LastSuccess.IsZero() {
+		lastReloadSuccess = float64(reloadStats.LastSuccess.UnixNano()) / 1e9
+	}
+	ch <- prometheus.MustNewConstMetric(configLastReloadSuccessfulDesc, prometheus.GaugeValue, reloadSuccessful)
+	ch <- prometheus.MustNewConstMetric(configLastReloadSuccessDesc, prometheus.GaugeValue, lastReloadSuccess)
+	ch <- prometheus.MustNewConstMetric(configReloadsDesc, prometheus.CounterValue, float64(reloadStats.Successes), "success")
+	ch <- prometheus.MustNewConstMetric(configReloadsDesc, prometheus.CounterValue, float64(reloadStats.Failures), "failure")
+}
+
+func collectEndpointsStats(ch chan<- prometheus.Metric, c *collector.Collector) {
+	deployment, chainID := c.Deployment(), c.ChainID()
+	endpointsStats, failovers := c.EndpointsStats()
+	if endpointsStats == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(sourceFailoversDesc, prometheus.CounterValue, float64(failovers), deployment, chainID)
+
+	lags := source.BlockLags(endpointsStats)
+	for _, stats := range endpointsStats {
+		var active float64
+		if stats.Active {
+			active = 1
+		}
+		ch <- prometheus.MustNewConstMetric(sourceEndpointActiveDesc, prometheus.GaugeValue, active,
+			stats.Host, deployment, chainID)
+		for _, state := range source.BreakerStates {
+			var value float64
+			if stats.Breaker == state {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(sourceEndpointCircuitBreakerStateDesc, prometheus.GaugeValue, value,
+				stats.Host, state.String(), deployment, chainID)
+		}
+		ch <- prometheus.MustNewConstMetric(sourceEndpointRequestsDesc, prometheus.CounterValue, float64(stats.Successes),
+			stats.Host, "success", deployment, chainID)
+		ch <- prometheus.MustNewConstMetric(sourceEndpointRequestsDesc, prometheus.CounterValue, float64(stats.Failures),
+			stats.Host, "failure", deployment, chainID)
+		ch <- prometheus.MustNewConstHistogram(sourceEndpointRequestDurationDesc, stats.Successes+stats.Failures,
+			stats.LatencySum.Seconds(), stats.LatencyBuckets, stats.Host, deployment, chainID)
+
+		// the probe metrics are exported once the endpoint is probed
+		if stats.LastProbe.IsZero() {
+			continue
+		}
+		var probeUp float64
+		if stats.ProbeUp() {
+			probeUp = 1
+		}
+		ch <- prometheus.MustNewConstMetric(sourceEndpointProbeUpDesc, prometheus.GaugeValue, probeUp,
+			stats.Host, deployment, chainID)
+		if lag, found := lags[stats.Host]; found {
+			ch <- prometheus.MustNewConstMetric(sourceEndpointLatestBlockDesc, prometheus.GaugeValue, float64(stats.LatestHeight),
+				stats.Host, deployment, chainID)
+			ch <- prometheus.MustNewConstMetric(sourceEndpointBlockLagDesc, prometheus.GaugeValue, float64(lag),
+				stats.Host, deployment, chainID)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector"
+	"github.com/sirupsen/logrus"
+)
+
+// HealthHandler is the liveness check, it responds with 200 as long as the process serves the HTTP requests.
+func HealthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+// Readiness is the /readyz response body.
+type Readiness struct {
+	Ready       bool                           `json:"ready"`
+	Deployments map[string]DeploymentReadiness `json:"deployments"`
+}
+
+// DeploymentReadiness describes the readiness of a deployment collector.
+type DeploymentReadiness struct {
+	// MonitorsReady reports whether all the monitors have completed their first runs
+	MonitorsReady bool `json:"monitors_ready"`
+	// SourceReachable reports whether any source endpoint is not known to be down
+	SourceReachable bool `json:"source_reachable"`
+}
+
+// ReadyHandler is the readiness check, it responds with 200 once the monitors of all the deployments have completed
+// their first runs and the source of every deployment is reachable, with 503 otherwise.
+type ReadyHandler struct {
+	group  *collector.Group
+	logger *logrus.Logger
+}
+
+func NewReadyHandler(g *collector.Group, logger *logrus.Logger) ReadyHandler {
+	return ReadyHandler{
+		group:  g,
+		logger: logger,
+	}
+}
+
+func (h ReadyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	readiness := Readiness{Ready: true, Deployments: make(map[string]DeploymentReadiness)}
+	for _, c := range h.group.Collectors() {
+		deployment := DeploymentReadiness{MonitorsReady: c.Ready(), SourceReachable: c.SourceReachable()}
+		readiness.Deployments[c.Deployment()] = deployment
+		readiness.Ready = readiness.Ready && deployment.MonitorsReady && deployment.SourceReachable
+	}
+
+	status := http.StatusOK
+	if !readiness.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, readiness, h.logger)
+}
+
+// Status is the /status response body.
+type Status struct {
+	Deployments []DeploymentStatus `json:"deployments"`
+}
+
+// DeploymentStatus describes the monitors of a deployment collector.
+type DeploymentStatus struct {
+	Deployment     string          `json:"deployment"`
+	ChainID        string          `json:"chain_id"`
+	ActiveEndpoint string          `json:"active_endpoint"`
+	Ready          bool            `json:"ready"`
+	Monitors       []MonitorStatus `json:"monitors"`
+}
+
+// MonitorStatus describes a registered monitor. The times are omitted until the monitor has run.
+type MonitorStatus struct {
+	Name        string     `json:"name"`
+	Metrics     []string   `json:"metrics"`
+	Interval    string     `json:"interval"`
+	Up          bool       `json:"up"`
+	LastRun     *time.Time `json:"last_run,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	Successes   uint64     `json:"successes"`
+	Failures    uint64     `json:"failures"`
+}
+
+// StatusHandler responds with the status of the monitors of every deployment for debugging.
+type StatusHandler struct {
+	group  *collector.Group
+	logger *logrus.Logger
+}
+
+func NewStatusHandler(g *collector.Group, logger *logrus.Logger) StatusHandler {
+	return StatusHandler{
+		group:  g,
+		logger: logger,
+	}
+}
+
+func (h StatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := Status{Deployments: []DeploymentStatus{}}
+	for _, c := range h.group.Collectors() {
+		deployment := DeploymentStatus{
+			Deployment: c.Deployment(),
+			ChainID:    c.ChainID(),
+			Ready:      c.Ready(),
+			Monitors:   []MonitorStatus{},
+		}
+		endpoints, _ := c.EndpointsStats()
+		for _, endpoint := range endpoints {
+			if endpoint.Active {
+				deployment.ActiveEndpoint = endpoint.Host
+			}
+		}
+		for _, m := range c.MonitorsStatus() {
+			deployment.Monitors = append(deployment.Monitors, newMonitorStatus(m))
+		}
+		status.Deployments = append(status.Deployments, deployment)
+	}
+	writeJSON(w, http.StatusOK, status, h.logger)
+}
+
+func newMonitorStatus(m collector.MonitorStatus) MonitorStatus {
+	status := MonitorStatus{
+		Name:      m.Name,
+		Metrics:   make([]string, 0, len(m.Metrics)),
+		Interval:  m.Interval.String(),
+		Up:        m.Stats.Up(),
+		Successes: m.Stats.Successes,
+		Failures:  m.Stats.Failures,
+	}
+	for _, metric := range m.Metrics {
+		status.Metrics = append(status.Metrics, string(metric))
+	}
+	if !m.Stats.LastRun.IsZero() {
+		lastRun := m.Stats.LastRun.UTC()
+		status.LastRun = &lastRun
+	}
+	if !m.Stats.LastSuccess.IsZero() {
+		lastSuccess := m.Stats.LastSuccess.UTC()
+		status.LastSuccess = &lastSuccess
+	}
+	if m.Stats.LastError != nil {
+		status.LastError = m.Stats.LastError.Error()
+	}
+	return status
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}, logger *logrus.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Errorf("failed to write response: %v", err)
+	}
+}
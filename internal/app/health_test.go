@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector"
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHealthGroup creates the group of the mainnet collector running the HubState monitor, the source responds
+// once release is closed.
+func newTestHealthGroup(t *testing.T, release <-chan struct{}) (*collector.Group, *httptest.Server) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintln(w, hubStateResponse)
+	}))
+	t.Cleanup(ts.Close)
+
+	cfg := stubs.NewTestCollectorConfig(ts.URL)
+	cfg.BassetContractsVersion = config.V1Contracts
+	cfg.UpdateDataInterval = time.Hour
+	cfg.Deployment = "mainnet"
+	cfg.ChainID = "columbus-5"
+	cfg.EnabledMonitors = []string{"HubState"}
+
+	g, err := collector.NewGroup(context.Background(), cfg, stubs.NewTestLogger())
+	require.NoError(t, err)
+	t.Cleanup(g.Stop)
+	return g, ts
+}
+
+func TestHealthHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HealthHandler(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestNewMonitorStatus(t *testing.T) {
+	req := require.New(t)
+
+	lastRun := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	status := newMonitorStatus(collector.MonitorStatus{
+		Name:     "Slashing",
+		Metrics:  []monitors.MetricName{"slashing_num_jailed_validators", "slashing_num_tombstoned_validators"},
+		Interval: time.Minute,
+		Stats:    collector.MonitorStats{LastRun: lastRun, LastError: errors.New("timeout"), Failures: 1},
+	})
+	req.Equal(MonitorStatus{
+		Name:      "Slashing",
+		Metrics:   []string{"slashing_num_jailed_validators", "slashing_num_tombstoned_validators"},
+		Interval:  "1m0s",
+		LastRun:   &lastRun,
+		LastError: "timeout",
+		Failures:  1,
+	}, status)
+
+	status = newMonitorStatus(collector.MonitorStatus{Name: "HubState"})
+	req.Nil(status.LastRun, "the monitor has not run yet")
+	req.Empty(status.LastError)
+}
+
+func TestReadyHandler(t *testing.T) {
+	req := require.New(t)
+	release := make(chan struct{})
+	g, _ := newTestHealthGroup(t, release)
+	handler := NewReadyHandler(g, stubs.NewTestLogger())
+
+	var readiness Readiness
+	req.Equal(http.StatusServiceUnavailable, get(t, handler, "/readyz", &readiness))
+	req.Equal(Readiness{
+		Ready:       false,
+		Deployments: map[string]DeploymentReadiness{"mainnet": {MonitorsReady: false, SourceReachable: true}},
+	}, readiness)
+
+	close(release)
+	req.Eventually(g.Ready, 5*time.Second, time.Millisecond)
+	readiness = Readiness{}
+	req.Equal(http.StatusOK, get(t, handler, "/readyz", &readiness))
+	req.Equal(Readiness{
+		Ready:       true,
+		Deployments: map[string]DeploymentReadiness{"mainnet": {MonitorsReady: true, SourceReachable: true}},
+	}, readiness)
+}
+
+func TestStatusHandler(t *testing.T) {
+	req := require.New(t)
+	release := make(chan struct{})
+	close(release)
+	g, ts := newTestHealthGroup(t, release)
+	req.Eventually(g.Ready, 5*time.Second, time.Millisecond)
+	source, err := url.Parse(ts.URL)
+	req.NoError(err)
+
+	var status Status
+	req.Equal(http.StatusOK, get(t, NewStatusHandler(g, stubs.NewTestLogger()), "/status", &status))
+	req.Len(status.Deployments, 1)
+	deployment := status.Deployments[0]
+	req.Equal("mainnet", deployment.Deployment)
+	req.Equal("columbus-5", deployment.ChainID)
+	req.Equal(source.Host, deployment.ActiveEndpoint)
+	req.True(deployment.Ready)
+	req.Len(deployment.Monitors, 1)
+
+	monitor := deployment.Monitors[0]
+	req.Equal("HubState", monitor.Name)
+	req.Equal([]string{"bluna_bonded_amount", "bluna_exchange_rate"}, monitor.Metrics)
+	req.Equal("1h0m0s", monitor.Interval)
+	req.True(monitor.Up)
+	req.NotNil(monitor.LastRun)
+	req.NotNil(monitor.LastSuccess)
+	req.Empty(monitor.LastError)
+	req.EqualValues(1, monitor.Successes)
+	req.EqualValues(0, monitor.Failures)
+}
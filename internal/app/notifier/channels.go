@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+)
+
+// telegramMaxLength is the max number of characters of a Telegram message, the longer ones are rejected.
+const telegramMaxLength = 4096
+
+// Telegram sends the messages to a chat by the Telegram Bot API. The messages exceeding the Telegram limit
+// are truncated, see truncateLines.
+type Telegram struct {
+	cfg    config.TelegramConfig
+	client *http.Client
+	tmpl   *Template
+}
+
+func NewTelegram(cfg config.TelegramConfig, client *http.Client, tmpl *Template) *Telegram {
+	return &Telegram{cfg: cfg, client: client, tmpl: tmpl}
+}
+
+func (t *Telegram) Name() string {
+	return "telegram"
+}
+
+type telegramMessage struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+func (t *Telegram) Notify(ctx context.Context, n Notification) error {
+	text, err := t.tmpl.Render(n)
+	if err != nil {
+		return err
+	}
+	target := strings.TrimSuffix(t.cfg.APIURL, "/") + "/bot" + t.cfg.BotToken + "/sendMessage"
+	return postJSON(ctx, t.client, target, telegramMessage{
+		ChatID:                t.cfg.ChatID,
+		Text:                  truncateLines(text, telegramMaxLength),
+		DisableWebPagePreview: true,
+	})
+}
+
+// truncateLines cuts the text exceeding maxLength characters to the whole lines fitting into it and appends
+// the number of the lines cut off, e.g. "+5 more". The text of a large group keeps its header and the first
+// alerts, so it's delivered rather than rejected. The text is cut to maxLength characters if its first line
+// doesn't fit.
+func truncateLines(text string, maxLength int) string {
+	if utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	// the suffix of the most lines cut off is reserved, so any suffix fits
+	reserved := len(moreLines(len(lines)))
+	length, kept := 0, 0
+	for ; kept < len(lines); kept++ {
+		next := length + utf8.RuneCountInString(lines[kept])
+		if kept > 0 {
+			next++ // the line break
+		}
+		if next+reserved > maxLength {
+			break
+		}
+		length = next
+	}
+	if kept == 0 {
+		return string([]rune(text)[:maxLength])
+	}
+	return strings.Join(lines[:kept], "\n") + moreLines(len(lines)-kept)
+}
+
+func moreLines(n int) string {
+	return fmt.Sprintf("\n+%d more", n)
+}
+
+// Slack sends the messages to a Slack incoming webhook.
+type Slack struct {
+	cfg    config.SlackConfig
+	client *http.Client
+	tmpl   *Template
+}
+
+func NewSlack(cfg config.SlackConfig, client *http.Client, tmpl *Template) *Slack {
+	return &Slack{cfg: cfg, client: client, tmpl: tmpl}
+}
+
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+func (s *Slack) Notify(ctx context.Context, n Notification) error {
+	text, err := s.tmpl.Render(n)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, s.client, s.cfg.WebhookURL, slackMessage{Text: text})
+}
+
+// Webhook sends the notifications as JSON to a generic webhook, the payload is the GroupMessage with the rendered text.
+type Webhook struct {
+	cfg    config.WebhookConfig
+	client *http.Client
+	tmpl   *Template
+}
+
+func NewWebhook(cfg config.WebhookConfig, client *http.Client, tmpl *Template) *Webhook {
+	return &Webhook{cfg: cfg, client: client, tmpl: tmpl}
+}
+
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+// WebhookPayload is the body of the generic webhook requests.
+type WebhookPayload struct {
+	GroupMessage
+	Text string `json:"text"`
+}
+
+func (w *Webhook) Notify(ctx context.Context, n Notification) error {
+	text, err := w.tmpl.Render(n)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, w.client, w.cfg.URL, WebhookPayload{GroupMessage: NewGroupMessage(n), Text: text})
+}
@@ -0,0 +1,194 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Result is the outcome of a notification.
+type Result string
+
+const (
+	Sent Result = "sent"
+	// Failed notifications have failed all the delivery attempts
+	Failed Result = "failed"
+	// Dropped notifications haven't been attempted, since the channel queue was full
+	Dropped Result = "dropped"
+)
+
+// Results lists all the notification results.
+var Results = []Result{Sent, Failed, Dropped}
+
+// Dispatcher delivers the notifications to every notifier in background. Every notifier has its own queue, so a slow
+// channel doesn't delay the others. The deliveries failed with the retryable errors are retried with
+// an exponential backoff.
+type Dispatcher struct {
+	cfg    config.NotificationsConfig
+	logger *logrus.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	queues map[string]chan Notification
+
+	statsLock sync.Mutex
+	// stats are the notification counts by the channel and the result
+	stats map[string]map[Result]uint64
+
+	notificationsDesc *prometheus.Desc
+}
+
+// NewDispatcher starts the delivery to the notifiers, it's stopped once the ctx is cancelled or Stop is called.
+func NewDispatcher(
+	ctx context.Context,
+	notifiers []Notifier,
+	cfg config.NotificationsConfig,
+	logger *logrus.Logger,
+) *Dispatcher {
+	ctx, cancel := context.WithCancel(ctx)
+	d := &Dispatcher{
+		cfg:    cfg,
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+		queues: make(map[string]chan Notification),
+		stats:  make(map[string]map[Result]uint64),
+		notificationsDesc: prometheus.NewDesc(
+			"notifications_total",
+			"Number of the alert notifications by channel and result (sent, failed or dropped).",
+			[]string{"channel", "result"},
+			nil,
+		),
+	}
+	for _, n := range notifiers {
+		queue := make(chan Notification, cfg.QueueSize)
+		d.queues[n.Name()] = queue
+		d.stats[n.Name()] = make(map[Result]uint64)
+		d.wg.Add(1)
+		go d.run(n, queue)
+	}
+	return d
+}
+
+// Notify queues the notification for the delivery to every notifier, the notification is dropped for the notifiers
+// with a full queue. It never blocks.
+func (d *Dispatcher) Notify(n Notification) {
+	if d.ctx.Err() != nil {
+		return
+	}
+	for name, queue := range d.queues {
+		select {
+		case queue <- n:
+		default:
+			d.logger.Errorf("%s notification of alert %s is dropped: the queue is full", name, n.Rule)
+			d.record(name, Dropped)
+		}
+	}
+}
+
+// Stop cancels the running deliveries and waits for the delivery goroutines to exit, the queued notifications
+// are dropped.
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) run(n Notifier, queue chan Notification) {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case notification := <-queue:
+			if err := d.deliver(n, notification); err != nil {
+				if d.ctx.Err() != nil {
+					return
+				}
+				d.logger.Errorf("failed to send %s notification of alert %s: %v", n.Name(), notification.Rule, err)
+				d.record(n.Name(), Failed)
+				continue
+			}
+			d.record(n.Name(), Sent)
+		}
+	}
+}
+
+// deliver sends the notification to the notifier retrying the retryable errors.
+func (d *Dispatcher) deliver(n Notifier, notification Notification) error {
+	backoff := d.cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := d.attempt(n, notification)
+		if err == nil {
+			return nil
+		}
+		if d.ctx.Err() != nil {
+			return d.ctx.Err()
+		}
+		var deliveryErr *DeliveryError
+		if !errors.As(err, &deliveryErr) || !deliveryErr.Retryable() || attempt >= d.cfg.MaxRetries {
+			return err
+		}
+
+		d.logger.Warningf("retrying %s notification of alert %s in %s after attempt #%d failed: %v",
+			n.Name(), notification.Rule, backoff, attempt+1, err)
+		select {
+		case <-d.ctx.Done():
+			return d.ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > d.cfg.MaxRetryBackoff {
+			backoff = d.cfg.MaxRetryBackoff
+		}
+	}
+}
+
+// attempt makes a single delivery attempt limited by the timeout.
+func (d *Dispatcher) attempt(n Notifier, notification Notification) error {
+	ctx := d.ctx
+	if d.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.cfg.Timeout)
+		defer cancel()
+	}
+	return n.Notify(ctx, notification)
+}
+
+func (d *Dispatcher) record(channel string, result Result) {
+	d.statsLock.Lock()
+	defer d.statsLock.Unlock()
+	d.stats[channel][result]++
+}
+
+// Stats returns the notification counts by the channel and the result.
+func (d *Dispatcher) Stats() map[string]map[Result]uint64 {
+	d.statsLock.Lock()
+	defer d.statsLock.Unlock()
+	stats := make(map[string]map[Result]uint64, len(d.stats))
+	for channel, counts := range d.stats {
+		stats[channel] = make(map[Result]uint64, len(counts))
+		for result, count := range counts {
+			stats[channel][result] = count
+		}
+	}
+	return stats
+}
+
+func (d *Dispatcher) Describe(ch chan<- *prometheus.Desc) {
+	ch <- d.notificationsDesc
+}
+
+func (d *Dispatcher) Collect(ch chan<- prometheus.Metric) {
+	for channel, counts := range d.Stats() {
+		for _, result := range Results {
+			ch <- prometheus.MustNewConstMetric(d.notificationsDesc, prometheus.CounterValue,
+				float64(counts[result]), channel, string(result))
+		}
+	}
+}
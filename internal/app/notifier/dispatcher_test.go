@@ -0,0 +1,118 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/alerting"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testNotificationsConfig() config.NotificationsConfig {
+	return config.NotificationsConfig{
+		Timeout:         time.Second,
+		MaxRetries:      2,
+		RetryBackoff:    time.Millisecond,
+		MaxRetryBackoff: time.Millisecond,
+		QueueSize:       10,
+	}
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	req := require.New(t)
+	// the first delivery succeeds on the last retry, the second one fails without retries
+	r := newRecorder(t, http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusOK, http.StatusBadRequest)
+	n := NewWebhook(config.WebhookConfig{URL: r.server.URL}, http.DefaultClient, defaultTemplate(t))
+	d := NewDispatcher(context.Background(), []Notifier{n}, testNotificationsConfig(), stubs.NewTestLogger())
+	defer d.Stop()
+
+	d.Notify(testNotification(testAlert(alerting.StateFiring)))
+	d.Notify(testNotification(testAlert(alerting.StateResolved)))
+	var payload WebhookPayload
+	for i := 0; i < 3; i++ {
+		r.next(&payload)
+		req.Equal("FIRING", payload.Status)
+	}
+	r.next(&payload)
+	req.Equal("RESOLVED", payload.Status)
+
+	req.Eventually(func() bool {
+		return d.Stats()["webhook"][Failed] == 1
+	}, 5*time.Second, time.Millisecond)
+	req.Equal(map[string]map[Result]uint64{"webhook": {Sent: 1, Failed: 1}}, d.Stats())
+}
+
+func TestDispatcherRetriesExhausted(t *testing.T) {
+	req := require.New(t)
+	r := newRecorder(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	n := NewSlack(config.SlackConfig{WebhookURL: r.server.URL}, http.DefaultClient, defaultTemplate(t))
+	d := NewDispatcher(context.Background(), []Notifier{n}, testNotificationsConfig(), stubs.NewTestLogger())
+	defer d.Stop()
+
+	d.Notify(testNotification(testAlert(alerting.StateFiring)))
+	var msg slackMessage
+	for i := 0; i < 3; i++ {
+		r.next(&msg)
+	}
+	req.Eventually(func() bool {
+		return d.Stats()["slack"][Failed] == 1
+	}, 5*time.Second, time.Millisecond)
+	req.Len(r.paths, 0, "no attempts are made after MaxRetries retries")
+}
+
+// blockingNotifier blocks the deliveries until the ctx is cancelled.
+type blockingNotifier struct {
+	started chan struct{}
+	once    sync.Once
+}
+
+func (n *blockingNotifier) Name() string {
+	return "blocking"
+}
+
+func (n *blockingNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.once.Do(func() { close(n.started) })
+	<-ctx.Done()
+	return &DeliveryError{Err: ctx.Err()}
+}
+
+func TestDispatcherQueueFull(t *testing.T) {
+	req := require.New(t)
+	blocking := &blockingNotifier{started: make(chan struct{})}
+	r := newRecorder(t)
+	webhook := NewWebhook(config.WebhookConfig{URL: r.server.URL}, http.DefaultClient, defaultTemplate(t))
+	cfg := testNotificationsConfig()
+	cfg.QueueSize, cfg.Timeout = 1, 0
+	d := NewDispatcher(context.Background(), []Notifier{blocking, webhook}, cfg, stubs.NewTestLogger())
+
+	d.Notify(testNotification(testAlert(alerting.StateFiring)))
+	<-blocking.started
+	// the first alert is being delivered, the second one is queued and the third one is dropped
+	d.Notify(testNotification(testAlert(alerting.StateFiring)))
+	d.Notify(testNotification(testAlert(alerting.StateFiring)))
+	req.Equal(uint64(1), d.Stats()["blocking"][Dropped])
+
+	// the slow channel doesn't delay the others
+	var payload WebhookPayload
+	r.next(&payload)
+
+	stopped := make(chan struct{})
+	go func() {
+		d.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop doesn't cancel the running delivery")
+	}
+	req.Zero(d.Stats()["blocking"][Failed], "the cancelled delivery isn't a failure")
+	req.True(errors.Is(d.ctx.Err(), context.Canceled))
+}
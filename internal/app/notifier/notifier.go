@@ -0,0 +1,115 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/lidofinance/terra-monitors/internal/app/alerting"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Notifier delivers the alert notifications to a channel.
+type Notifier interface {
+	// Name is the channel name used in the logs and the metrics
+	Name() string
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Notification is a group of the alerts of a rule in a deployment sent as a single message.
+type Notification struct {
+	Rule       string
+	Deployment string
+	ChainID    string
+	// Alerts are the firing and resolved alerts of the group sorted by the fingerprint
+	Alerts []alerting.Alert
+}
+
+// NewNotifiers creates the notifiers of the configured channels, none of them is created if no channel is configured.
+func NewNotifiers(cfg config.CollectorConfig, logger *logrus.Logger) ([]Notifier, error) {
+	tmpl, err := LoadTemplate(cfg.Notifications.TemplateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{}
+	var notifiers []Notifier
+	if cfg.Telegram.BotToken != "" {
+		notifiers = append(notifiers, NewTelegram(cfg.Telegram, client, tmpl))
+	}
+	if cfg.Slack.WebhookURL != "" {
+		notifiers = append(notifiers, NewSlack(cfg.Slack, client, tmpl))
+	}
+	if cfg.Webhook.URL != "" {
+		notifiers = append(notifiers, NewWebhook(cfg.Webhook, client, tmpl))
+	}
+	for _, n := range notifiers {
+		logger.Infof("alert notifications are sent to %s", n.Name())
+	}
+	return notifiers, nil
+}
+
+// DeliveryError is a failed notification delivery.
+type DeliveryError struct {
+	// StatusCode is the HTTP status of the response, 0 if there was no response
+	StatusCode int
+	Err        error
+}
+
+func (e *DeliveryError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("delivery failed with status %d: %v", e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("delivery failed: %v", e.Err)
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the delivery may succeed if it's attempted again: the network errors and
+// the 5xx and 429 statuses are retryable.
+func (e *DeliveryError) Retryable() bool {
+	return e.StatusCode == 0 || e.StatusCode >= http.StatusInternalServerError ||
+		e.StatusCode == http.StatusTooManyRequests
+}
+
+// maxErrorBodySize limits the response body quoted in the delivery errors.
+const maxErrorBodySize = 512
+
+// postJSON sends the body as JSON to the target, the non-2xx responses are returned as DeliveryError.
+// The target isn't included in the errors, since the webhook URLs and the bot tokens are secrets.
+func postJSON(ctx context.Context, client *http.Client, target string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return &DeliveryError{Err: err}
+	}
+	defer resp.Body.Close()
+	respBody, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return &DeliveryError{StatusCode: resp.StatusCode, Err: fmt.Errorf("unexpected response: %s", respBody)}
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lidofinance/terra-monitors/internal/app/alerting"
+	"github.com/lidofinance/terra-monitors/internal/app/collector"
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Date(2021, 11, 1, 12, 30, 0, 0, time.UTC)
+
+func testAlert(state alerting.State) alerting.Alert {
+	alert := alerting.Alert{
+		Rule:     "ValidatorJailed",
+		Severity: "critical",
+		Summary:  "Validator is jailed",
+		Labels: map[string]string{
+			collector.DeploymentLabel:      "mainnet",
+			collector.ChainIDLabel:         "columbus-5",
+			monitors.ValidatorAddressLabel: "terravaloper1xyz",
+			monitors.MonikerLabel:          "Lido Validator",
+		},
+		State:    state,
+		Value:    1,
+		ActiveAt: testTime,
+		FiredAt:  testTime,
+	}
+	if state == alerting.StateResolved {
+		alert.ResolvedAt = testTime.Add(time.Hour)
+	}
+	return alert
+}
+
+func testNotification(alerts ...alerting.Alert) Notification {
+	return Notification{Rule: "ValidatorJailed", Deployment: "mainnet", ChainID: "columbus-5", Alerts: alerts}
+}
+
+// recorder is the httptest stand-in of a channel API recording the request bodies.
+type recorder struct {
+	t        *testing.T
+	server   *httptest.Server
+	paths    chan string
+	bodies   chan []byte
+	statuses []int
+}
+
+// newRecorder starts the server responding with the statuses in order and with 200 once they are over.
+func newRecorder(t *testing.T, statuses ...int) *recorder {
+	r := &recorder{t: t, paths: make(chan string, 10), bodies: make(chan []byte, 10), statuses: statuses}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.MethodPost, req.Method)
+		require.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		r.paths <- req.URL.Path
+		r.bodies <- body
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *recorder) next(body interface{}) string {
+	select {
+	case path := <-r.paths:
+		require.NoError(r.t, json.Unmarshal(<-r.bodies, body))
+		return path
+	case <-time.After(5 * time.Second):
+		r.t.Fatal("no request received")
+		return ""
+	}
+}
+
+func defaultTemplate(t *testing.T) *Template {
+	tmpl, err := LoadTemplate("")
+	require.NoError(t, err)
+	return tmpl
+}
+
+func TestDefaultTemplate(t *testing.T) {
+	req := require.New(t)
+	tmpl := defaultTemplate(t)
+
+	other := testAlert(alerting.StateResolved)
+	other.Labels = map[string]string{monitors.ValidatorAddressLabel: "terravaloper1abc"}
+	text, err := tmpl.Render(testNotification(testAlert(alerting.StateFiring), other))
+	req.NoError(err)
+	req.Equal(`[FIRING:1] ValidatorJailed (critical)
+Validator is jailed
+Deployment: mainnet (columbus-5)
+- Validator Lido Validator terravaloper1xyz: 1
+- Validator terravaloper1abc: 1 (resolved)`, text)
+
+	alert := testAlert(alerting.StateResolved)
+	alert.Summary = ""
+	alert.Value = 0.25
+	delete(alert.Labels, monitors.ValidatorAddressLabel)
+	text, err = tmpl.Render(testNotification(alert))
+	req.NoError(err)
+	req.Equal(`[RESOLVED] ValidatorJailed (critical)
+Deployment: mainnet (columbus-5)
+- 0.25 (resolved)`, text)
+}
+
+func TestLoadTemplate(t *testing.T) {
+	req := require.New(t)
+	path := filepath.Join(t.TempDir(), "message.tmpl")
+	req.NoError(ioutil.WriteFile(path, []byte(`{{ .Status }}:{{ range .Alerts }} {{ .Moniker }} ({{ .ValidatorAddress }}){{ end }}`), 0600))
+
+	tmpl, err := LoadTemplate(path)
+	req.NoError(err)
+	text, err := tmpl.Render(testNotification(testAlert(alerting.StateFiring)))
+	req.NoError(err)
+	req.Equal("FIRING: Lido Validator (terravaloper1xyz)", text)
+
+	req.NoError(ioutil.WriteFile(path, []byte(`{{ .Unknown }}`), 0600))
+	tmpl, err = LoadTemplate(path)
+	req.NoError(err)
+	_, err = tmpl.Render(testNotification(testAlert(alerting.StateFiring)))
+	req.Error(err)
+
+	_, err = LoadTemplate(filepath.Join(t.TempDir(), "missing.tmpl"))
+	req.Error(err)
+}
+
+func TestTelegram(t *testing.T) {
+	req := require.New(t)
+	r := newRecorder(t)
+	n := NewTelegram(config.TelegramConfig{BotToken: "123:secret", ChatID: "-100", APIURL: r.server.URL + "/"},
+		http.DefaultClient, defaultTemplate(t))
+
+	req.NoError(n.Notify(context.Background(), testNotification(testAlert(alerting.StateFiring))))
+	var msg telegramMessage
+	req.Equal("/bot123:secret/sendMessage", r.next(&msg))
+	req.Equal("-100", msg.ChatID)
+	req.True(msg.DisableWebPagePreview)
+	req.Contains(msg.Text, "Validator Lido Validator terravaloper1xyz")
+}
+
+func TestTelegramLongMessage(t *testing.T) {
+	req := require.New(t)
+	r := newRecorder(t)
+	n := NewTelegram(config.TelegramConfig{BotToken: "123:secret", ChatID: "-100", APIURL: r.server.URL},
+		http.DefaultClient, defaultTemplate(t))
+
+	alerts := make([]alerting.Alert, 200)
+	for i := range alerts {
+		alerts[i] = testAlert(alerting.StateFiring)
+	}
+	text, err := defaultTemplate(t).Render(testNotification(alerts...))
+	req.NoError(err)
+	req.Greater(utf8.RuneCountInString(text), telegramMaxLength)
+
+	req.NoError(n.Notify(context.Background(), testNotification(alerts...)))
+	var msg telegramMessage
+	r.next(&msg)
+	req.LessOrEqual(utf8.RuneCountInString(msg.Text), telegramMaxLength)
+	req.True(strings.HasPrefix(msg.Text, "[FIRING:200] ValidatorJailed (critical)\n"), "the header must be kept")
+	lines := strings.Split(msg.Text, "\n")
+	// the header lines, the kept alerts and the suffix
+	kept := len(lines) - 4
+	req.Equal(fmt.Sprintf("+%d more", len(alerts)-kept), lines[len(lines)-1])
+	req.Equal("- Validator Lido Validator terravaloper1xyz: 1", lines[len(lines)-2], "the alerts must be kept whole")
+}
+
+func TestTruncateLines(t *testing.T) {
+	req := require.New(t)
+	req.Equal("short\ntext", truncateLines("short\ntext", 10))
+	req.Equal("line 1\n+2 more", truncateLines("line 1\nline 2\nline 3", 16))
+	req.Equal("long first", truncateLines("long first line\nline 2", 10))
+}
+
+func TestSlack(t *testing.T) {
+	req := require.New(t)
+	r := newRecorder(t)
+	n := NewSlack(config.SlackConfig{WebhookURL: r.server.URL + "/services/T000/B000/XXX"},
+		http.DefaultClient, defaultTemplate(t))
+
+	req.NoError(n.Notify(context.Background(), testNotification(testAlert(alerting.StateResolved))))
+	var msg slackMessage
+	req.Equal("/services/T000/B000/XXX", r.next(&msg))
+	req.True(strings.HasPrefix(msg.Text, "[RESOLVED] ValidatorJailed"))
+}
+
+func TestWebhook(t *testing.T) {
+	req := require.New(t)
+	r := newRecorder(t)
+	n := NewWebhook(config.WebhookConfig{URL: r.server.URL + "/alerts"}, http.DefaultClient, defaultTemplate(t))
+
+	req.NoError(n.Notify(context.Background(), testNotification(testAlert(alerting.StateFiring))))
+	var payload WebhookPayload
+	req.Equal("/alerts", r.next(&payload))
+	req.Equal("FIRING", payload.Status)
+	req.Equal("ValidatorJailed", payload.Rule)
+	req.Equal("mainnet", payload.Deployment)
+	req.Equal(1, payload.Firing)
+	req.Len(payload.Alerts, 1)
+	req.Equal("terravaloper1xyz", payload.Alerts[0].ValidatorAddress)
+	req.Equal("Lido Validator", payload.Alerts[0].Moniker)
+	req.Equal("1", payload.Alerts[0].Value)
+	req.Equal(testTime, *payload.Alerts[0].FiredAt)
+	req.Nil(payload.Alerts[0].ResolvedAt)
+	req.Contains(payload.Text, "[FIRING:1] ValidatorJailed")
+}
+
+func TestDeliveryError(t *testing.T) {
+	req := require.New(t)
+	r := newRecorder(t, http.StatusBadRequest)
+	n := NewTelegram(config.TelegramConfig{BotToken: "123:secret", ChatID: "-100", APIURL: r.server.URL},
+		http.DefaultClient, defaultTemplate(t))
+
+	err := n.Notify(context.Background(), testNotification(testAlert(alerting.StateFiring)))
+	var deliveryErr *DeliveryError
+	req.True(errors.As(err, &deliveryErr))
+	req.Equal(http.StatusBadRequest, deliveryErr.StatusCode)
+	req.False(deliveryErr.Retryable())
+
+	r.server.Close()
+	err = n.Notify(context.Background(), testNotification(testAlert(alerting.StateFiring)))
+	req.True(errors.As(err, &deliveryErr))
+	req.True(deliveryErr.Retryable())
+	req.NotContains(err.Error(), "secret", "the bot token must not be logged")
+}
+
+func TestNewNotifiers(t *testing.T) {
+	req := require.New(t)
+	cfg, err := config.NewCollectorConfig()
+	req.NoError(err)
+
+	notifiers, err := NewNotifiers(cfg, stubs.NewTestLogger())
+	req.NoError(err)
+	req.Empty(notifiers)
+
+	cfg.Telegram = config.TelegramConfig{BotToken: "123:secret", ChatID: "-100"}
+	cfg.Webhook.URL = "http://localhost/alerts"
+	notifiers, err = NewNotifiers(cfg, stubs.NewTestLogger())
+	req.NoError(err)
+	req.Len(notifiers, 2)
+	req.Equal("telegram", notifiers[0].Name())
+	req.Equal("webhook", notifiers[1].Name())
+
+	cfg.Notifications.TemplateFile = filepath.Join(t.TempDir(), "missing.tmpl")
+	_, err = NewNotifiers(cfg, stubs.NewTestLogger())
+	req.Error(err)
+}
@@ -0,0 +1,508 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/alerting"
+	"github.com/lidofinance/terra-monitors/internal/app/collector"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrNotFound is returned by Router if the silence or the maintenance window doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalid is wrapped by the Router errors of the invalid silences and maintenance windows.
+	ErrInvalid = errors.New("invalid")
+)
+
+// routerTick is the time between the checks of the groups due to be notified.
+const routerTick = time.Second
+
+// alertGroup is the state of the alerts of a rule in a deployment.
+type alertGroup struct {
+	Rule       string `json:"rule"`
+	Deployment string `json:"deployment"`
+	ChainID    string `json:"chain_id"`
+	// Alerts are the firing alerts and the resolved ones not notified yet, keyed by the fingerprint
+	Alerts map[string]alerting.Alert `json:"alerts"`
+	// Notified are the fingerprints of the alerts notified as firing
+	Notified map[string]bool `json:"notified"`
+	// Changed reports whether the group has the changes not notified yet, ChangedAt is the time of the first of them
+	Changed    bool      `json:"changed"`
+	ChangedAt  time.Time `json:"changed_at"`
+	NotifiedAt time.Time `json:"notified_at"`
+}
+
+func groupKey(rule, deployment string) string {
+	return rule + "\xff" + deployment
+}
+
+func (g *alertGroup) firing() bool {
+	for _, alert := range g.Alerts {
+		if alert.State == alerting.StateFiring {
+			return true
+		}
+	}
+	return false
+}
+
+// due reports whether the group notification is due: the first changes are notified after the group wait, the next
+// ones after the group interval since the last notification, the unchanged firing alerts after the repeat interval.
+func (g *alertGroup) due(cfg config.NotificationsConfig, now time.Time) bool {
+	switch {
+	case g.Changed && g.NotifiedAt.IsZero():
+		return now.Sub(g.ChangedAt) >= cfg.GroupWait
+	case g.Changed:
+		return now.Sub(g.NotifiedAt) >= cfg.GroupInterval
+	default:
+		return g.firing() && now.Sub(g.NotifiedAt) >= cfg.RepeatInterval
+	}
+}
+
+// routerState is the format of the state file.
+type routerState struct {
+	Groups             []*alertGroup       `json:"groups"`
+	Silences           []Silence           `json:"silences"`
+	MaintenanceWindows []MaintenanceWindow `json:"maintenance_windows"`
+}
+
+// Router groups the alert changes by the rule and the deployment and sends the groups due to be notified,
+// so a flapping alert is notified at most once per the group interval. The alerts muted by the silences and
+// the maintenance windows are not sent. The groups, the silences and the maintenance windows are saved
+// to the state file on every change and restored on start.
+type Router struct {
+	cfg    config.NotificationsConfig
+	send   func(n Notification)
+	logger *logrus.Logger
+	// now returns the current time, it's replaced by the tests
+	now func() time.Time
+
+	lock     sync.Mutex
+	groups   map[string]*alertGroup
+	silences map[string]Silence
+	windows  map[string]MaintenanceWindow
+	// current and ready provide the engine alerts and the collectors readiness to reconcile the restored groups
+	current func() []alerting.Alert
+	ready   func() bool
+	// restored reports whether the groups restored from the state file are not reconciled yet
+	restored bool
+}
+
+// NewRouter restores the state from the state file and starts checking the groups in background until the ctx
+// is cancelled. send is called with the notifications due, it must not block.
+func NewRouter(
+	ctx context.Context,
+	cfg config.NotificationsConfig,
+	send func(n Notification),
+	logger *logrus.Logger,
+) (*Router, error) {
+	r := &Router{
+		cfg:      cfg,
+		send:     send,
+		logger:   logger,
+		now:      time.Now,
+		groups:   make(map[string]*alertGroup),
+		silences: make(map[string]Silence),
+		windows:  make(map[string]MaintenanceWindow),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		ticker := time.NewTicker(routerTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.flush(r.now())
+			}
+		}
+	}()
+	return r, nil
+}
+
+// Watch routes the alert changes of the engine. The firing alerts restored from the state file are resolved
+// once the collectors are ready unless the engine reports them as pending or firing.
+func (r *Router) Watch(e *alerting.Engine, g *collector.Group) {
+	r.lock.Lock()
+	r.current, r.ready = e.Alerts, g.Ready
+	r.lock.Unlock()
+	e.OnChange(r.Notify)
+}
+
+// Notify adds the fired or resolved alert to its group. The alert already known in the same state isn't a change,
+// e.g. the firing alert restored from the state file and fired again after the restart. The changed groups are
+// saved at once, so the changes not notified yet survive a restart.
+func (r *Router) Notify(alert alerting.Alert) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.add(alert, r.now()) {
+		return
+	}
+	if err := r.save(); err != nil {
+		r.logger.Errorf("failed to save notifications state: %v", err)
+	}
+}
+
+// add adds the alert to its group and reports whether the group has changed.
+func (r *Router) add(alert alerting.Alert, now time.Time) bool {
+	deployment := alert.Labels[collector.DeploymentLabel]
+	key := groupKey(alert.Rule, deployment)
+	g, found := r.groups[key]
+	if !found && alert.State == alerting.StateResolved {
+		return false
+	}
+	if !found {
+		g = &alertGroup{
+			Rule:       alert.Rule,
+			Deployment: deployment,
+			ChainID:    alert.Labels[collector.ChainIDLabel],
+			Alerts:     make(map[string]alerting.Alert),
+			Notified:   make(map[string]bool),
+		}
+		r.groups[key] = g
+	}
+
+	fingerprint := alert.Fingerprint()
+	known, found := g.Alerts[fingerprint]
+	if found && known.State == alert.State || !found && alert.State == alerting.StateResolved {
+		return false
+	}
+	g.Alerts[fingerprint] = alert
+	if !g.Changed {
+		g.Changed, g.ChangedAt = true, now
+	}
+	return true
+}
+
+// muted reports whether the alert is muted by a silence or a maintenance window.
+func (r *Router) muted(alert alerting.Alert, now time.Time) bool {
+	labels := alertLabels(alert)
+	for _, s := range r.silences {
+		if s.mutes(labels, now) {
+			return true
+		}
+	}
+	for _, w := range r.windows {
+		if w.mutes(labels, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// flush sends the groups due to be notified and prunes the expired silences and maintenance windows.
+func (r *Router) flush(now time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	changed := false
+	if r.restored && r.ready != nil && r.ready() {
+		r.reconcile(now)
+		r.restored, changed = false, true
+	}
+
+	keys := make([]string, 0, len(r.groups))
+	for key := range r.groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		g := r.groups[key]
+		if !g.due(r.cfg, now) {
+			continue
+		}
+		if r.notify(g, now) {
+			changed = true
+		}
+		if len(g.Alerts) == 0 {
+			delete(r.groups, key)
+		}
+	}
+
+	for id, s := range r.silences {
+		if !now.Before(s.EndsAt) {
+			r.logger.Infof("silence %s expired", id)
+			delete(r.silences, id)
+			changed = true
+		}
+	}
+	for id, w := range r.windows {
+		if !now.Before(w.EndsAt) {
+			r.logger.Infof("maintenance window %s expired", w.Name)
+			delete(r.windows, id)
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := r.save(); err != nil {
+			r.logger.Errorf("failed to save notifications state: %v", err)
+		}
+	}
+}
+
+// notify sends the group notification without the muted alerts and reports whether the group has changed.
+// The resolved alerts are dropped once they are notified or muted.
+func (r *Router) notify(g *alertGroup, now time.Time) bool {
+	changed := g.Changed
+	g.Changed = false
+
+	fingerprints := make([]string, 0, len(g.Alerts))
+	for fingerprint := range g.Alerts {
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	sort.Strings(fingerprints)
+
+	n := Notification{Rule: g.Rule, Deployment: g.Deployment, ChainID: g.ChainID}
+	for _, fingerprint := range fingerprints {
+		alert := g.Alerts[fingerprint]
+		resolved := alert.State == alerting.StateResolved
+		if resolved && !g.Notified[fingerprint] || r.muted(alert, now) {
+			// the resolved alerts never notified as firing are dropped silently
+			if resolved {
+				delete(g.Alerts, fingerprint)
+				delete(g.Notified, fingerprint)
+				changed = true
+			}
+			continue
+		}
+		n.Alerts = append(n.Alerts, alert)
+	}
+	if len(n.Alerts) == 0 {
+		return changed
+	}
+
+	r.send(n)
+	g.NotifiedAt = now
+	for _, alert := range n.Alerts {
+		fingerprint := alert.Fingerprint()
+		if alert.State == alerting.StateResolved {
+			delete(g.Alerts, fingerprint)
+			delete(g.Notified, fingerprint)
+		} else {
+			g.Notified[fingerprint] = true
+		}
+	}
+	return true
+}
+
+// reconcile resolves the firing alerts of the groups which the engine doesn't report as pending or firing.
+func (r *Router) reconcile(now time.Time) {
+	active := make(map[string]bool)
+	for _, alert := range r.current() {
+		if alert.State != alerting.StateResolved {
+			active[alert.Fingerprint()] = true
+		}
+	}
+	for _, g := range r.groups {
+		for fingerprint, alert := range g.Alerts {
+			if alert.State != alerting.StateFiring || active[fingerprint] {
+				continue
+			}
+			r.logger.Infof("restored alert %s is resolved for %v", alert.Rule, alert.Labels)
+			alert.State, alert.ResolvedAt = alerting.StateResolved, now
+			g.Alerts[fingerprint] = alert
+			if !g.Changed {
+				g.Changed, g.ChangedAt = true, now
+			}
+		}
+	}
+}
+
+// Silences returns the active and the scheduled silences sorted by the start time.
+func (r *Router) Silences() []Silence {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	silences := make([]Silence, 0, len(r.silences))
+	for _, s := range r.silences {
+		silences = append(silences, s)
+	}
+	sort.Slice(silences, func(i, j int) bool {
+		if !silences[i].StartsAt.Equal(silences[j].StartsAt) {
+			return silences[i].StartsAt.Before(silences[j].StartsAt)
+		}
+		return silences[i].ID < silences[j].ID
+	})
+	return silences
+}
+
+// AddSilence validates and saves the silence, the zero StartsAt means now. The silence isn't added if it fails
+// to be saved.
+func (r *Router) AddSilence(s Silence) (Silence, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := r.now()
+	s.ID, s.CreatedAt = newID(), now
+	if s.StartsAt.IsZero() {
+		s.StartsAt = now
+	}
+	if err := s.validate(); err != nil {
+		return s, fmt.Errorf("%w silence: %v", ErrInvalid, err)
+	}
+	if !now.Before(s.EndsAt) {
+		return s, fmt.Errorf("%w silence: ends_at %s has passed", ErrInvalid, s.EndsAt)
+	}
+	r.silences[s.ID] = s
+	if err := r.save(); err != nil {
+		delete(r.silences, s.ID)
+		return s, err
+	}
+	r.logger.Infof("silence %s added by %s until %s", s.ID, s.CreatedBy, s.EndsAt)
+	return s, nil
+}
+
+// DeleteSilence expires the silence at once. The silence is kept if the deletion fails to be saved.
+func (r *Router) DeleteSilence(id string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	s, found := r.silences[id]
+	if !found {
+		return ErrNotFound
+	}
+	delete(r.silences, id)
+	if err := r.save(); err != nil {
+		r.silences[id] = s
+		return err
+	}
+	r.logger.Infof("silence %s deleted", id)
+	return nil
+}
+
+// MaintenanceWindows returns the active and the scheduled maintenance windows sorted by the start time.
+func (r *Router) MaintenanceWindows() []MaintenanceWindow {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	windows := make([]MaintenanceWindow, 0, len(r.windows))
+	for _, w := range r.windows {
+		windows = append(windows, w)
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		if !windows[i].StartsAt.Equal(windows[j].StartsAt) {
+			return windows[i].StartsAt.Before(windows[j].StartsAt)
+		}
+		return windows[i].ID < windows[j].ID
+	})
+	return windows
+}
+
+// AddMaintenanceWindow validates and saves the maintenance window. The window isn't added if it fails to be saved.
+func (r *Router) AddMaintenanceWindow(w MaintenanceWindow) (MaintenanceWindow, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := r.now()
+	w.ID, w.CreatedAt = newID(), now
+	if err := w.validate(); err != nil {
+		return w, fmt.Errorf("%w maintenance window: %v", ErrInvalid, err)
+	}
+	if !now.Before(w.EndsAt) {
+		return w, fmt.Errorf("%w maintenance window: ends_at %s has passed", ErrInvalid, w.EndsAt)
+	}
+	r.windows[w.ID] = w
+	if err := r.save(); err != nil {
+		delete(r.windows, w.ID)
+		return w, err
+	}
+	r.logger.Infof("maintenance window %s scheduled from %s until %s", w.Name, w.StartsAt, w.EndsAt)
+	return w, nil
+}
+
+// DeleteMaintenanceWindow cancels the maintenance window. The window is kept if the deletion fails to be saved.
+func (r *Router) DeleteMaintenanceWindow(id string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	w, found := r.windows[id]
+	if !found {
+		return ErrNotFound
+	}
+	delete(r.windows, id)
+	if err := r.save(); err != nil {
+		r.windows[id] = w
+		return err
+	}
+	r.logger.Infof("maintenance window %s deleted", w.Name)
+	return nil
+}
+
+// load restores the state from the state file, the missing file is an empty state.
+func (r *Router) load() error {
+	if r.cfg.StateFile == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(r.cfg.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read notifications state file: %w", err)
+	}
+
+	var state routerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse notifications state file %s: %w", r.cfg.StateFile, err)
+	}
+	for _, g := range state.Groups {
+		if g.Notified == nil {
+			g.Notified = make(map[string]bool)
+		}
+		r.groups[groupKey(g.Rule, g.Deployment)] = g
+	}
+	for _, s := range state.Silences {
+		if err := s.validate(); err != nil {
+			return fmt.Errorf("invalid silence %s in notifications state file: %w", s.ID, err)
+		}
+		r.silences[s.ID] = s
+	}
+	for _, w := range state.MaintenanceWindows {
+		if err := w.validate(); err != nil {
+			return fmt.Errorf("invalid maintenance window %s in notifications state file: %w", w.ID, err)
+		}
+		r.windows[w.ID] = w
+	}
+	r.restored = len(r.groups) > 0
+	r.logger.Infof("restored %d alert groups, %d silences and %d maintenance windows",
+		len(r.groups), len(r.silences), len(r.windows))
+	return nil
+}
+
+// save writes the state to the state file atomically, it's called with the lock held.
+func (r *Router) save() error {
+	if r.cfg.StateFile == "" {
+		return nil
+	}
+	state := routerState{
+		Groups:             make([]*alertGroup, 0, len(r.groups)),
+		Silences:           make([]Silence, 0, len(r.silences)),
+		MaintenanceWindows: make([]MaintenanceWindow, 0, len(r.windows)),
+	}
+	for _, g := range r.groups {
+		state.Groups = append(state.Groups, g)
+	}
+	for _, s := range r.silences {
+		state.Silences = append(state.Silences, s)
+	}
+	for _, w := range r.windows {
+		state.MaintenanceWindows = append(state.MaintenanceWindows, w)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal notifications state: %w", err)
+	}
+	if err := utils.WriteFileAtomic(r.cfg.StateFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write notifications state file: %w", err)
+	}
+	return nil
+}
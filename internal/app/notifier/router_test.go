@@ -0,0 +1,318 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/alerting"
+	"github.com/lidofinance/terra-monitors/internal/app/collector"
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testRouterConfig(stateFile string) config.NotificationsConfig {
+	return config.NotificationsConfig{
+		GroupWait:      30 * time.Second,
+		GroupInterval:  5 * time.Minute,
+		RepeatInterval: time.Hour,
+		StateFile:      stateFile,
+	}
+}
+
+// testRouter is the router with the controlled time, the groups are flushed by the tests only.
+type testRouter struct {
+	*Router
+	sent []Notification
+	at   time.Time
+}
+
+func newTestRouter(t *testing.T, stateFile string) *testRouter {
+	ctx, cancel := context.WithCancel(context.Background())
+	// the background flushes are stopped at once
+	cancel()
+	tr := &testRouter{at: testTime}
+	r, err := NewRouter(ctx, testRouterConfig(stateFile), func(n Notification) {
+		tr.sent = append(tr.sent, n)
+	}, stubs.NewTestLogger())
+	require.NoError(t, err)
+	r.now = func() time.Time {
+		return tr.at
+	}
+	tr.Router = r
+	return tr
+}
+
+// notify adds the alert at the time after the test start.
+func (tr *testRouter) notify(after time.Duration, alert alerting.Alert) {
+	tr.at = testTime.Add(after)
+	tr.Notify(alert)
+}
+
+// flush flushes the groups at the time after the test start and returns the notifications sent.
+func (tr *testRouter) flush(after time.Duration) []Notification {
+	tr.at = testTime.Add(after)
+	tr.sent = nil
+	tr.Router.flush(tr.at)
+	return tr.sent
+}
+
+func validatorAlert(deployment, address string, state alerting.State) alerting.Alert {
+	return alerting.Alert{
+		Rule:     "ValidatorJailed",
+		Severity: "critical",
+		Labels: map[string]string{
+			collector.DeploymentLabel:      deployment,
+			collector.ChainIDLabel:         "columbus-5",
+			monitors.ValidatorAddressLabel: address,
+		},
+		State: state,
+		Value: 1,
+	}
+}
+
+func alertAddresses(n Notification) map[string]alerting.State {
+	states := make(map[string]alerting.State)
+	for _, alert := range n.Alerts {
+		states[alert.Labels[monitors.ValidatorAddressLabel]] = alert.State
+	}
+	return states
+}
+
+func TestRouterGrouping(t *testing.T) {
+	req := require.New(t)
+	tr := newTestRouter(t, "")
+
+	tr.notify(0, validatorAlert("mainnet", "val1", alerting.StateFiring))
+	tr.notify(10*time.Second, validatorAlert("mainnet", "val2", alerting.StateFiring))
+	tr.notify(10*time.Second, validatorAlert("testnet", "val3", alerting.StateFiring))
+	req.Empty(tr.flush(20 * time.Second))
+
+	// the alerts firing within the group wait are sent in a single notification per deployment
+	sent := tr.flush(30 * time.Second)
+	req.Len(sent, 1)
+	req.Equal("mainnet", sent[0].Deployment)
+	req.Equal(map[string]alerting.State{"val1": alerting.StateFiring, "val2": alerting.StateFiring}, alertAddresses(sent[0]))
+	sent = tr.flush(40 * time.Second)
+	req.Len(sent, 1)
+	req.Equal("testnet", sent[0].Deployment)
+
+	// the flapping alert is notified once per the group interval
+	tr.notify(time.Minute, validatorAlert("mainnet", "val1", alerting.StateResolved))
+	tr.notify(2*time.Minute, validatorAlert("mainnet", "val1", alerting.StateFiring))
+	tr.notify(3*time.Minute, validatorAlert("mainnet", "val2", alerting.StateResolved))
+	req.Empty(tr.flush(4 * time.Minute))
+	sent = tr.flush(5*time.Minute + 30*time.Second)
+	req.Len(sent, 1)
+	req.Equal(map[string]alerting.State{"val1": alerting.StateFiring, "val2": alerting.StateResolved}, alertAddresses(sent[0]))
+
+	// the unchanged firing alerts are repeated after the repeat interval, the resolved ones are not
+	req.Empty(tr.flush(time.Hour))
+	sent = tr.flush(time.Hour + 6*time.Minute)
+	req.Len(sent, 2)
+	req.Equal(map[string]alerting.State{"val1": alerting.StateFiring}, alertAddresses(sent[0]))
+	req.Equal("testnet", sent[1].Deployment)
+
+	// the alert known in the same state isn't a change
+	tr.notify(time.Hour+7*time.Minute, validatorAlert("mainnet", "val1", alerting.StateFiring))
+	req.Empty(tr.flush(time.Hour + 20*time.Minute))
+}
+
+func TestRouterResolvedNotNotified(t *testing.T) {
+	req := require.New(t)
+	tr := newTestRouter(t, "")
+
+	// the alert resolved within the group wait isn't notified at all
+	tr.notify(0, validatorAlert("mainnet", "val1", alerting.StateFiring))
+	tr.notify(10*time.Second, validatorAlert("mainnet", "val1", alerting.StateResolved))
+	req.Empty(tr.flush(time.Minute))
+	req.Empty(tr.groups)
+
+	tr.notify(2*time.Minute, validatorAlert("mainnet", "val2", alerting.StateResolved))
+	req.Empty(tr.groups)
+}
+
+func TestRouterSilences(t *testing.T) {
+	req := require.New(t)
+	tr := newTestRouter(t, "")
+
+	silence, err := tr.AddSilence(Silence{
+		Matchers: []Matcher{
+			{Name: alerting.AlertNameLabel, Value: "ValidatorJailed"},
+			{Name: monitors.ValidatorAddressLabel, Value: "val[12]", IsRegex: true},
+		},
+		EndsAt:    testTime.Add(time.Hour),
+		CreatedBy: "on-call",
+	})
+	req.NoError(err)
+	req.NotEmpty(silence.ID)
+	req.Equal(testTime, silence.StartsAt)
+	req.Equal([]Silence{silence}, tr.Silences())
+
+	tr.notify(0, validatorAlert("mainnet", "val1", alerting.StateFiring))
+	tr.notify(0, validatorAlert("mainnet", "val10", alerting.StateFiring))
+	sent := tr.flush(time.Minute)
+	req.Len(sent, 1)
+	req.Equal(map[string]alerting.State{"val10": alerting.StateFiring}, alertAddresses(sent[0]))
+
+	// the silenced alert is notified after the silence expires once the repeat interval is over
+	req.Empty(tr.flush(time.Hour))
+	req.Empty(tr.Silences(), "the expired silence is pruned")
+	sent = tr.flush(time.Hour + time.Minute)
+	req.Len(sent, 1)
+	req.Equal(map[string]alerting.State{"val1": alerting.StateFiring, "val10": alerting.StateFiring},
+		alertAddresses(sent[0]))
+
+	_, err = tr.AddSilence(Silence{EndsAt: testTime.Add(2 * time.Hour)})
+	req.Error(err)
+	_, err = tr.AddSilence(Silence{Matchers: []Matcher{{Name: "moniker", Value: "(", IsRegex: true}},
+		EndsAt: testTime.Add(2 * time.Hour)})
+	req.Error(err)
+	_, err = tr.AddSilence(Silence{Matchers: []Matcher{{Name: "moniker", Value: "Lido"}},
+		StartsAt: testTime, EndsAt: testTime.Add(time.Minute)})
+	req.Error(err, "the silence ended in the past is rejected")
+
+	silence, err = tr.AddSilence(Silence{Matchers: []Matcher{{Name: "moniker", Value: "Lido"}},
+		EndsAt: testTime.Add(2 * time.Hour)})
+	req.NoError(err)
+	req.NoError(tr.DeleteSilence(silence.ID))
+	req.True(errors.Is(tr.DeleteSilence(silence.ID), ErrNotFound))
+}
+
+func TestRouterMaintenanceWindow(t *testing.T) {
+	req := require.New(t)
+	tr := newTestRouter(t, "")
+
+	window, err := tr.AddMaintenanceWindow(MaintenanceWindow{
+		Name:        "hub migration",
+		Deployments: []string{"mainnet"},
+		StartsAt:    testTime.Add(time.Hour),
+		EndsAt:      testTime.Add(2 * time.Hour),
+	})
+	req.NoError(err)
+	req.Equal([]MaintenanceWindow{window}, tr.MaintenanceWindows())
+
+	// the window is scheduled, it doesn't mute the alerts yet
+	tr.notify(0, validatorAlert("mainnet", "val1", alerting.StateFiring))
+	req.Len(tr.flush(time.Minute), 1)
+
+	tr.notify(time.Hour, validatorAlert("mainnet", "val2", alerting.StateFiring))
+	tr.notify(time.Hour, validatorAlert("testnet", "val3", alerting.StateFiring))
+	sent := tr.flush(time.Hour + time.Minute)
+	req.Len(sent, 1)
+	req.Equal("testnet", sent[0].Deployment)
+
+	// the muted alerts are notified after the window ends once the repeat interval is over
+	sent = tr.flush(2 * time.Hour)
+	req.Empty(tr.MaintenanceWindows(), "the expired window is pruned")
+	req.Len(sent, 1)
+	req.Equal(map[string]alerting.State{"val1": alerting.StateFiring, "val2": alerting.StateFiring},
+		alertAddresses(sent[0]))
+
+	_, err = tr.AddMaintenanceWindow(MaintenanceWindow{Name: "no end", StartsAt: testTime})
+	req.Error(err)
+	_, err = tr.AddMaintenanceWindow(MaintenanceWindow{EndsAt: testTime.Add(4 * time.Hour)})
+	req.Error(err)
+	window, err = tr.AddMaintenanceWindow(MaintenanceWindow{Name: "all", EndsAt: testTime.Add(4 * time.Hour)})
+	req.NoError(err)
+	req.NoError(tr.DeleteMaintenanceWindow(window.ID))
+	req.True(errors.Is(tr.DeleteMaintenanceWindow(window.ID), ErrNotFound))
+}
+
+func TestRouterState(t *testing.T) {
+	req := require.New(t)
+	stateFile := filepath.Join(t.TempDir(), "notifications.json")
+	tr := newTestRouter(t, stateFile)
+
+	tr.notify(0, validatorAlert("mainnet", "val1", alerting.StateFiring))
+	tr.notify(0, validatorAlert("mainnet", "val2", alerting.StateFiring))
+	req.Len(tr.flush(time.Minute), 1)
+	silence, err := tr.AddSilence(Silence{
+		Matchers: []Matcher{{Name: monitors.ValidatorAddressLabel, Value: "val[3]", IsRegex: true}},
+		EndsAt:   testTime.Add(3 * time.Hour),
+	})
+	req.NoError(err)
+	window, err := tr.AddMaintenanceWindow(MaintenanceWindow{Name: "migration", EndsAt: testTime.Add(3 * time.Hour),
+		StartsAt: testTime.Add(2 * time.Hour)})
+	req.NoError(err)
+
+	// the restarted router restores the state
+	restarted := newTestRouter(t, stateFile)
+	restarted.at = testTime.Add(2 * time.Minute)
+	req.Len(restarted.Silences(), 1)
+	req.Equal(silence.ID, restarted.Silences()[0].ID)
+	req.Len(restarted.MaintenanceWindows(), 1)
+	req.Equal(window.ID, restarted.MaintenanceWindows()[0].ID)
+	req.True(restarted.Silences()[0].mutes(map[string]string{monitors.ValidatorAddressLabel: "val3"},
+		testTime.Add(2*time.Minute)), "the restored regex matchers are compiled")
+
+	// the alert fired again after the restart isn't notified again
+	restarted.notify(2*time.Minute, validatorAlert("mainnet", "val1", alerting.StateFiring))
+	req.Empty(restarted.flush(10 * time.Minute))
+
+	// the restored alert the engine doesn't report is resolved once the collectors are ready
+	ready := false
+	restarted.current = func() []alerting.Alert {
+		return []alerting.Alert{validatorAlert("mainnet", "val1", alerting.StatePending)}
+	}
+	restarted.ready = func() bool {
+		return ready
+	}
+	req.Empty(restarted.flush(11 * time.Minute))
+	ready = true
+	sent := restarted.flush(12 * time.Minute)
+	req.Len(sent, 1)
+	req.Equal(map[string]alerting.State{"val1": alerting.StateFiring, "val2": alerting.StateResolved},
+		alertAddresses(sent[0]))
+}
+
+func TestRouterStateNotNotified(t *testing.T) {
+	req := require.New(t)
+	stateFile := filepath.Join(t.TempDir(), "notifications.json")
+	tr := newTestRouter(t, stateFile)
+
+	// the change is saved before its group wait is over
+	tr.notify(0, validatorAlert("mainnet", "val1", alerting.StateFiring))
+	restarted := newTestRouter(t, stateFile)
+	sent := restarted.flush(time.Minute)
+	req.Len(sent, 1)
+	req.Equal(map[string]alerting.State{"val1": alerting.StateFiring}, alertAddresses(sent[0]))
+}
+
+func TestRouterStateSaveFailed(t *testing.T) {
+	req := require.New(t)
+	// the state file directory doesn't exist, so the state fails to be saved
+	tr := newTestRouter(t, filepath.Join(t.TempDir(), "missing", "notifications.json"))
+
+	_, err := tr.AddSilence(Silence{
+		Matchers: []Matcher{{Name: monitors.ValidatorAddressLabel, Value: "val1"}},
+		EndsAt:   testTime.Add(time.Hour),
+	})
+	req.Error(err)
+	req.False(errors.Is(err, ErrInvalid))
+	req.Empty(tr.Silences(), "the silence failed to be saved must not be active")
+
+	_, err = tr.AddMaintenanceWindow(MaintenanceWindow{Name: "migration", EndsAt: testTime.Add(time.Hour)})
+	req.Error(err)
+	req.Empty(tr.MaintenanceWindows(), "the maintenance window failed to be saved must not be active")
+}
+
+func TestRouterInvalidState(t *testing.T) {
+	for name, state := range map[string]string{
+		"malformed":           "{",
+		"invalid silence":     `{"silences": [{"id": "1", "ends_at": "2030-01-01T00:00:00Z"}]}`,
+		"window without end":  `{"maintenance_windows": [{"id": "1", "name": "migration"}]}`,
+		"window without name": `{"maintenance_windows": [{"id": "1", "ends_at": "2030-01-01T00:00:00Z"}]}`,
+	} {
+		stateFile := filepath.Join(t.TempDir(), "notifications.json")
+		require.NoError(t, ioutil.WriteFile(stateFile, []byte(state), 0600))
+		_, err := NewRouter(context.Background(), testRouterConfig(stateFile), func(Notification) {}, stubs.NewTestLogger())
+		require.Error(t, err, name)
+	}
+}
@@ -0,0 +1,150 @@
+package notifier
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/alerting"
+	"github.com/lidofinance/terra-monitors/internal/app/collector"
+)
+
+// Matcher matches the alerts having the label with the value. The alertname and severity labels match the rule name
+// and the severity of the alert. The value is a regular expression matching the whole label value if IsRegex is set.
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"is_regex,omitempty"`
+
+	re *regexp.Regexp
+}
+
+func (m *Matcher) compile() error {
+	if m.Name == "" {
+		return errors.New("matcher name is required")
+	}
+	if !m.IsRegex {
+		return nil
+	}
+	re, err := regexp.Compile("^(?:" + m.Value + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid matcher %s regex: %w", m.Name, err)
+	}
+	m.re = re
+	return nil
+}
+
+func (m Matcher) matches(labels map[string]string) bool {
+	if m.re != nil {
+		return m.re.MatchString(labels[m.Name])
+	}
+	return labels[m.Name] == m.Value
+}
+
+// alertLabels returns the alert labels with the alertname and severity labels.
+func alertLabels(alert alerting.Alert) map[string]string {
+	labels := make(map[string]string, len(alert.Labels)+2)
+	for name, value := range alert.Labels {
+		labels[name] = value
+	}
+	labels[alerting.AlertNameLabel] = alert.Rule
+	labels[alerting.SeverityLabel] = alert.Severity
+	return labels
+}
+
+// Silence mutes the notifications of the alerts matching all the matchers from StartsAt until EndsAt.
+type Silence struct {
+	ID        string    `json:"id"`
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Active reports whether the silence mutes the alerts at the time.
+func (s Silence) Active(now time.Time) bool {
+	return !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+func (s Silence) mutes(labels map[string]string, now time.Time) bool {
+	if !s.Active(now) {
+		return false
+	}
+	for _, m := range s.Matchers {
+		if !m.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// validate checks the silence and compiles its matchers.
+func (s *Silence) validate() error {
+	if len(s.Matchers) == 0 {
+		return errors.New("at least one matcher is required")
+	}
+	for i := range s.Matchers {
+		if err := s.Matchers[i].compile(); err != nil {
+			return err
+		}
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("ends_at %s must be after starts_at %s", s.EndsAt, s.StartsAt)
+	}
+	return nil
+}
+
+// MaintenanceWindow mutes the notifications of all the alerts of the deployments from StartsAt until EndsAt,
+// e.g. during a planned contract migration. The empty Deployments match all the deployments.
+type MaintenanceWindow struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Deployments []string  `json:"deployments,omitempty"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Comment     string    `json:"comment,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Active reports whether the window mutes the alerts at the time.
+func (w MaintenanceWindow) Active(now time.Time) bool {
+	return !now.Before(w.StartsAt) && now.Before(w.EndsAt)
+}
+
+func (w MaintenanceWindow) mutes(labels map[string]string, now time.Time) bool {
+	if !w.Active(now) {
+		return false
+	}
+	if len(w.Deployments) == 0 {
+		return true
+	}
+	for _, deployment := range w.Deployments {
+		if labels[collector.DeploymentLabel] == deployment {
+			return true
+		}
+	}
+	return false
+}
+
+func (w MaintenanceWindow) validate() error {
+	if w.Name == "" {
+		return errors.New("name is required")
+	}
+	if !w.EndsAt.After(w.StartsAt) {
+		return fmt.Errorf("ends_at %s must be after starts_at %s", w.EndsAt, w.StartsAt)
+	}
+	return nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate id: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,134 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/alerting"
+	"github.com/lidofinance/terra-monitors/internal/app/collector/monitors"
+)
+
+// DefaultTemplate is the text/template of the Telegram and Slack messages.
+const DefaultTemplate = `{{ if .Firing }}[FIRING:{{ .Firing }}]{{ else }}[RESOLVED]{{ end }} {{ .Rule }} ({{ .Severity }})
+{{- with .Summary }}
+{{ . }}{{ end }}
+Deployment: {{ .Deployment }} ({{ .ChainID }})
+{{- range $alert := .Alerts }}
+- {{ with $alert.ValidatorAddress }}Validator {{ with $alert.Moniker }}{{ . }} {{ end }}{{ . }}: {{ end }}{{ $alert.Value }}
+{{- if eq $alert.Status "RESOLVED" }} (resolved){{ end }}
+{{- end }}
+`
+
+// GroupMessage is the data of the message templates and the generic webhook payload.
+type GroupMessage struct {
+	// Status is FIRING if any alert of the group is firing, RESOLVED otherwise
+	Status     string `json:"status"`
+	Rule       string `json:"rule"`
+	Severity   string `json:"severity"`
+	Summary    string `json:"summary,omitempty"`
+	Deployment string `json:"deployment"`
+	ChainID    string `json:"chain_id"`
+	// Firing and Resolved are the numbers of the firing and resolved alerts of the group
+	Firing   int       `json:"firing"`
+	Resolved int       `json:"resolved"`
+	Alerts   []Message `json:"alerts"`
+}
+
+// NewGroupMessage builds the message data of the notification.
+func NewGroupMessage(n Notification) GroupMessage {
+	m := GroupMessage{
+		Status:     strings.ToUpper(string(alerting.StateResolved)),
+		Rule:       n.Rule,
+		Deployment: n.Deployment,
+		ChainID:    n.ChainID,
+		Alerts:     make([]Message, 0, len(n.Alerts)),
+	}
+	for _, alert := range n.Alerts {
+		m.Severity, m.Summary = alert.Severity, alert.Summary
+		if alert.State == alerting.StateFiring {
+			m.Status = strings.ToUpper(string(alerting.StateFiring))
+			m.Firing++
+		} else {
+			m.Resolved++
+		}
+		m.Alerts = append(m.Alerts, NewMessage(alert))
+	}
+	return m
+}
+
+// Message is an alert of the GroupMessage. The validator values are taken from the alert labels, they are empty
+// if the alert has no such labels.
+type Message struct {
+	// Status is FIRING or RESOLVED
+	Status           string            `json:"status"`
+	Rule             string            `json:"rule"`
+	Severity         string            `json:"severity"`
+	Summary          string            `json:"summary,omitempty"`
+	ValidatorAddress string            `json:"validator_address,omitempty"`
+	Moniker          string            `json:"moniker,omitempty"`
+	Labels           map[string]string `json:"labels"`
+	// Value is the last value satisfying the rule condition, the rules evaluate the float64 values, so it's
+	// the shortest decimal representation of the float64 value rather than the exact decimal of the metric
+	Value      string     `json:"value"`
+	ActiveAt   time.Time  `json:"active_at"`
+	FiredAt    *time.Time `json:"fired_at,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+func NewMessage(alert alerting.Alert) Message {
+	m := Message{
+		Status:           strings.ToUpper(string(alert.State)),
+		Rule:             alert.Rule,
+		Severity:         alert.Severity,
+		Summary:          alert.Summary,
+		ValidatorAddress: alert.Labels[monitors.ValidatorAddressLabel],
+		Moniker:          alert.Labels[monitors.MonikerLabel],
+		Labels:           alert.Labels,
+		Value:            monitors.FormatFloat(alert.Value),
+		ActiveAt:         alert.ActiveAt.UTC(),
+	}
+	if !alert.FiredAt.IsZero() {
+		firedAt := alert.FiredAt.UTC()
+		m.FiredAt = &firedAt
+	}
+	if !alert.ResolvedAt.IsZero() {
+		resolvedAt := alert.ResolvedAt.UTC()
+		m.ResolvedAt = &resolvedAt
+	}
+	return m
+}
+
+// Template renders the alert messages.
+type Template struct {
+	tmpl *template.Template
+}
+
+// LoadTemplate parses the template file at path, DefaultTemplate is used if the path is empty.
+func LoadTemplate(path string) (*Template, error) {
+	text := DefaultTemplate
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file: %w", err)
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New("message").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Render renders the message of the notification.
+func (t *Template) Render(n Notification) (string, error) {
+	var b bytes.Buffer
+	if err := t.tmpl.Execute(&b, NewGroupMessage(n)); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/lidofinance/terra-monitors/internal/app/collector"
+	"github.com/sirupsen/logrus"
+)
+
+// ReloadHandler reloads the collectors config on POST requests and responds with the reload results
+// keyed by the deployment name.
+type ReloadHandler struct {
+	group  *collector.Group
+	load   collector.ConfigLoader
+	logger *logrus.Logger
+}
+
+func NewReloadHandler(g *collector.Group, load collector.ConfigLoader, logger *logrus.Logger) ReloadHandler {
+	return ReloadHandler{
+		group:  g,
+		load:   load,
+		logger: logger,
+	}
+}
+
+func (h ReloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	results, err := h.group.Reload(h.load)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		h.logger.Errorf("failed to write reload response: %v", err)
+	}
+}
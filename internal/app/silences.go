@@ -0,0 +1,167 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/notifier"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// SilenceRequest is the POST /admin/silences request body. The silence starts at StartsAt, now if it's empty,
+// and ends at EndsAt or after Duration.
+type SilenceRequest struct {
+	Matchers  []notifier.Matcher `json:"matchers"`
+	StartsAt  *time.Time         `json:"starts_at"`
+	EndsAt    *time.Time         `json:"ends_at"`
+	Duration  string             `json:"duration"`
+	CreatedBy string             `json:"created_by"`
+	Comment   string             `json:"comment"`
+}
+
+// MaintenanceWindowRequest is the POST /admin/maintenance request body.
+type MaintenanceWindowRequest struct {
+	Name        string    `json:"name"`
+	Deployments []string  `json:"deployments"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Comment     string    `json:"comment"`
+}
+
+// SilencesHandler manages the silences and the maintenance windows muting the alert notifications.
+type SilencesHandler struct {
+	router *notifier.Router
+	logger *logrus.Logger
+}
+
+// NewSilencesHandler creates the router serving the silences under /admin/silences and the maintenance windows
+// under /admin/maintenance.
+func NewSilencesHandler(r *notifier.Router, logger *logrus.Logger) http.Handler {
+	h := SilencesHandler{router: r, logger: logger}
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/silences", h.silences).Methods(http.MethodGet)
+	router.HandleFunc("/admin/silences", h.addSilence).Methods(http.MethodPost)
+	router.HandleFunc("/admin/silences/{id}", h.deleteSilence).Methods(http.MethodDelete)
+	router.HandleFunc("/admin/maintenance", h.maintenanceWindows).Methods(http.MethodGet)
+	router.HandleFunc("/admin/maintenance", h.addMaintenanceWindow).Methods(http.MethodPost)
+	router.HandleFunc("/admin/maintenance/{id}", h.deleteMaintenanceWindow).Methods(http.MethodDelete)
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, APIError{Error: "not found"}, logger)
+	})
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusMethodNotAllowed, APIError{Error: "method not allowed"}, logger)
+	})
+	return router
+}
+
+func (h SilencesHandler) silences(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.router.Silences(), h.logger)
+}
+
+func (h SilencesHandler) addSilence(w http.ResponseWriter, r *http.Request) {
+	var body SilenceRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeJSON(w, http.StatusBadRequest, APIError{Error: err.Error()}, h.logger)
+		return
+	}
+
+	silence := notifier.Silence{Matchers: body.Matchers, CreatedBy: body.CreatedBy, Comment: body.Comment}
+	if body.StartsAt != nil {
+		silence.StartsAt = *body.StartsAt
+	}
+	switch {
+	case body.EndsAt != nil && body.Duration != "":
+		writeJSON(w, http.StatusBadRequest, APIError{Error: "either ends_at or duration is expected"}, h.logger)
+		return
+	case body.EndsAt != nil:
+		silence.EndsAt = *body.EndsAt
+	case body.Duration != "":
+		duration, err := time.ParseDuration(body.Duration)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, APIError{Error: fmt.Sprintf("invalid duration: %v", err)}, h.logger)
+			return
+		}
+		if silence.StartsAt.IsZero() {
+			silence.StartsAt = time.Now()
+		}
+		silence.EndsAt = silence.StartsAt.Add(duration)
+	default:
+		writeJSON(w, http.StatusBadRequest, APIError{Error: "ends_at or duration is required"}, h.logger)
+		return
+	}
+
+	silence, err := h.router.AddSilence(silence)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, silence, h.logger)
+}
+
+func (h SilencesHandler) deleteSilence(w http.ResponseWriter, r *http.Request) {
+	h.writeDeleted(w, h.router.DeleteSilence(mux.Vars(r)["id"]))
+}
+
+func (h SilencesHandler) maintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.router.MaintenanceWindows(), h.logger)
+}
+
+func (h SilencesHandler) addMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	var body MaintenanceWindowRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeJSON(w, http.StatusBadRequest, APIError{Error: err.Error()}, h.logger)
+		return
+	}
+
+	window, err := h.router.AddMaintenanceWindow(notifier.MaintenanceWindow{
+		Name:        body.Name,
+		Deployments: body.Deployments,
+		StartsAt:    body.StartsAt,
+		EndsAt:      body.EndsAt,
+		Comment:     body.Comment,
+	})
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, window, h.logger)
+}
+
+func (h SilencesHandler) deleteMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	h.writeDeleted(w, h.router.DeleteMaintenanceWindow(mux.Vars(r)["id"]))
+}
+
+func (h SilencesHandler) writeDeleted(w http.ResponseWriter, err error) {
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError responds with 400 to the validation errors, 404 to the unknown IDs and 500 to the state saving errors.
+func (h SilencesHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, notifier.ErrInvalid):
+		writeJSON(w, http.StatusBadRequest, APIError{Error: err.Error()}, h.logger)
+	case errors.Is(err, notifier.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, APIError{Error: err.Error()}, h.logger)
+	default:
+		h.logger.Errorf("failed to save notifications state: %v", err)
+		writeJSON(w, http.StatusInternalServerError, APIError{Error: err.Error()}, h.logger)
+	}
+}
+
+func decodeJSON(r *http.Request, body interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(body); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
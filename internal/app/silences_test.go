@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/app/notifier"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSilencesHandler(t *testing.T) http.Handler {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	r, err := notifier.NewRouter(ctx, config.NotificationsConfig{
+		GroupWait:      30 * time.Second,
+		GroupInterval:  5 * time.Minute,
+		RepeatInterval: time.Hour,
+		StateFile:      filepath.Join(t.TempDir(), "state.json"),
+	}, func(notifier.Notification) {}, stubs.NewTestLogger())
+	require.NoError(t, err)
+	return NewSilencesHandler(r, stubs.NewTestLogger())
+}
+
+func serve(t *testing.T, handler http.Handler, method, target, body string, resp interface{}) int {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	if resp != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+	}
+	return rec.Code
+}
+
+func TestSilencesHandler(t *testing.T) {
+	handler := newTestSilencesHandler(t)
+
+	var silence notifier.Silence
+	code := serve(t, handler, http.MethodPost, "/admin/silences", `{
+		"matchers": [{"name": "alertname", "value": "ValidatorJailed"}, {"name": "moniker", "value": "lido.*", "is_regex": true}],
+		"duration": "2h",
+		"created_by": "ops",
+		"comment": "the validators are migrated"
+	}`, &silence)
+	require.Equal(t, http.StatusCreated, code)
+	require.NotEmpty(t, silence.ID)
+	require.Len(t, silence.Matchers, 2)
+	require.Equal(t, 2*time.Hour, silence.EndsAt.Sub(silence.StartsAt))
+	require.Equal(t, "ops", silence.CreatedBy)
+
+	var silences []notifier.Silence
+	require.Equal(t, http.StatusOK, serve(t, handler, http.MethodGet, "/admin/silences", "", &silences))
+	require.Len(t, silences, 1)
+	require.Equal(t, silence.ID, silences[0].ID)
+
+	require.Equal(t, http.StatusNoContent, serve(t, handler, http.MethodDelete, "/admin/silences/"+silence.ID, "", nil))
+	var apiErr APIError
+	require.Equal(t, http.StatusNotFound,
+		serve(t, handler, http.MethodDelete, "/admin/silences/"+silence.ID, "", &apiErr))
+	require.Equal(t, http.StatusOK, serve(t, handler, http.MethodGet, "/admin/silences", "", &silences))
+	require.Empty(t, silences)
+}
+
+func TestSilencesHandlerInvalid(t *testing.T) {
+	handler := newTestSilencesHandler(t)
+
+	for name, body := range map[string]string{
+		"no end":         `{"matchers": [{"name": "alertname", "value": "ValidatorJailed"}]}`,
+		"both ends":      `{"matchers": [{"name": "alertname", "value": "x"}], "duration": "1h", "ends_at": "2030-01-01T00:00:00Z"}`,
+		"bad duration":   `{"matchers": [{"name": "alertname", "value": "x"}], "duration": "1 hour"}`,
+		"no matchers":    `{"duration": "1h"}`,
+		"bad regex":      `{"matchers": [{"name": "moniker", "value": "(", "is_regex": true}], "duration": "1h"}`,
+		"ended":          `{"matchers": [{"name": "alertname", "value": "x"}], "ends_at": "2020-01-01T00:00:00Z"}`,
+		"unknown field":  `{"matchers": [{"name": "alertname", "value": "x"}], "duration": "1h", "until": "tomorrow"}`,
+		"malformed body": `{"matchers": [`,
+	} {
+		var apiErr APIError
+		code := serve(t, handler, http.MethodPost, "/admin/silences", body, &apiErr)
+		require.Equal(t, http.StatusBadRequest, code, name)
+		require.NotEmpty(t, apiErr.Error, name)
+	}
+}
+
+func TestMaintenanceHandler(t *testing.T) {
+	handler := newTestSilencesHandler(t)
+	start := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	end := start.Add(3 * time.Hour)
+
+	var window notifier.MaintenanceWindow
+	code := serve(t, handler, http.MethodPost, "/admin/maintenance", `{
+		"name": "hub migration",
+		"deployments": ["mainnet"],
+		"starts_at": "`+start.Format(time.RFC3339)+`",
+		"ends_at": "`+end.Format(time.RFC3339)+`"
+	}`, &window)
+	require.Equal(t, http.StatusCreated, code)
+	require.NotEmpty(t, window.ID)
+	require.Equal(t, []string{"mainnet"}, window.Deployments)
+	require.True(t, start.Equal(window.StartsAt))
+	require.True(t, end.Equal(window.EndsAt))
+
+	var apiErr APIError
+	code = serve(t, handler, http.MethodPost, "/admin/maintenance", `{
+		"starts_at": "`+start.Format(time.RFC3339)+`",
+		"ends_at": "`+end.Format(time.RFC3339)+`"
+	}`, &apiErr)
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Contains(t, apiErr.Error, "name is required")
+
+	var windows []notifier.MaintenanceWindow
+	require.Equal(t, http.StatusOK, serve(t, handler, http.MethodGet, "/admin/maintenance", "", &windows))
+	require.Len(t, windows, 1)
+
+	require.Equal(t, http.StatusNoContent,
+		serve(t, handler, http.MethodDelete, "/admin/maintenance/"+window.ID, "", nil))
+	require.Equal(t, http.StatusNotFound, serve(t, handler, http.MethodDelete, "/admin/maintenance/unknown", "", &apiErr))
+	require.Equal(t, http.StatusMethodNotAllowed, serve(t, handler, http.MethodPut, "/admin/maintenance", "", &apiErr))
+}
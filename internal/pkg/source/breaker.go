@@ -0,0 +1,141 @@
+package source
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+)
+
+// ErrCircuitOpen is returned by FailoverTransport if the circuits of all the endpoints are open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState is the state of the circuit breaker of an endpoint.
+type BreakerState int
+
+const (
+	// BreakerClosed lets all the requests to the endpoint through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen skips the endpoint until the open timeout is over.
+	BreakerOpen
+	// BreakerHalfOpen lets a single trial request through at a time, its result closes or opens the circuit again.
+	BreakerHalfOpen
+)
+
+// BreakerStates lists all the circuit breaker states.
+var BreakerStates = []BreakerState{BreakerClosed, BreakerOpen, BreakerHalfOpen}
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// circuitBreaker opens the circuit of an endpoint after FailureThreshold consecutive failed requests, so the endpoint
+// is skipped for OpenTimeout. Then the trial requests are let through one at a time, the circuit is closed after
+// SuccessThreshold successful ones or opened again after a failed one.
+type circuitBreaker struct {
+	cfg config.CircuitBreakerConfig
+
+	lock      sync.Mutex
+	state     BreakerState
+	failures  int
+	successes int
+	openedAt  time.Time
+	// trial reports whether the half-open circuit has a request in flight
+	trial bool
+}
+
+func newCircuitBreaker(cfg config.CircuitBreakerConfig) *circuitBreaker {
+	return &circuitBreaker{cfg: cfg}
+}
+
+// allow reports whether a request may be sent to the endpoint. The allowed request must be reported
+// by record or release.
+func (b *circuitBreaker) allow() bool {
+	if b.cfg.FailureThreshold <= 0 {
+		return true
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cfg.OpenTimeout {
+			return false
+		}
+		b.state, b.successes = BreakerHalfOpen, 0
+	case BreakerHalfOpen:
+		if b.trial {
+			return false
+		}
+	default:
+		return true
+	}
+	b.trial = true
+	return true
+}
+
+// record records the result of the allowed request.
+func (b *circuitBreaker) record(err error) {
+	if b.cfg.FailureThreshold <= 0 {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.state == BreakerHalfOpen {
+		b.trial = false
+		if err != nil {
+			b.open()
+			return
+		}
+		b.successes++
+		if b.successes >= b.cfg.SuccessThreshold {
+			b.state, b.failures = BreakerClosed, 0
+		}
+		return
+	}
+
+	if b.state == BreakerOpen {
+		// the request was allowed before another one opened the circuit
+		return
+	}
+	if err == nil {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.cfg.FailureThreshold {
+		b.open()
+	}
+}
+
+// release gives up the allowed request without a result, e.g. once the caller has cancelled it.
+func (b *circuitBreaker) release() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.state == BreakerHalfOpen {
+		b.trial = false
+	}
+}
+
+func (b *circuitBreaker) open() {
+	b.state, b.openedAt, b.failures = BreakerOpen, time.Now(), 0
+}
+
+// currentState returns the state, the open circuit is reported as half-open once the open timeout is over.
+func (b *circuitBreaker) currentState() BreakerState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cfg.OpenTimeout {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,194 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/tendermint_rpc"
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/factory"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errTestRequest = errors.New("connection refused")
+
+func TestCircuitBreaker(t *testing.T) {
+	req := require.New(t)
+	b := newCircuitBreaker(config.CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      50 * time.Millisecond,
+		SuccessThreshold: 2,
+	})
+
+	req.True(b.allow())
+	b.record(errTestRequest)
+	req.True(b.allow())
+	b.record(nil)
+	req.True(b.allow())
+	b.record(errTestRequest)
+	req.Equal(BreakerClosed, b.currentState(), "the failures must be consecutive")
+	req.True(b.allow())
+	b.record(errTestRequest)
+	req.Equal(BreakerOpen, b.currentState())
+	req.False(b.allow())
+
+	time.Sleep(60 * time.Millisecond)
+	req.Equal(BreakerHalfOpen, b.currentState())
+	req.True(b.allow())
+	req.False(b.allow(), "a single trial request is let through at a time")
+	b.record(errTestRequest)
+	req.Equal(BreakerOpen, b.currentState(), "the failed trial opens the circuit again")
+
+	time.Sleep(60 * time.Millisecond)
+	req.True(b.allow())
+	b.release()
+	req.True(b.allow(), "the released trial lets the next one through")
+	b.record(nil)
+	req.Equal(BreakerHalfOpen, b.currentState())
+	req.True(b.allow())
+	b.record(nil)
+	req.Equal(BreakerClosed, b.currentState())
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := newCircuitBreaker(config.CircuitBreakerConfig{})
+	for i := 0; i < 10; i++ {
+		require.True(t, b.allow())
+		b.record(errTestRequest)
+	}
+	require.Equal(t, BreakerClosed, b.currentState())
+}
+
+func TestFailoverTransportSkipsOpenCircuit(t *testing.T) {
+	req := require.New(t)
+
+	var failingRequests, healthyRequests int32
+	failing := newCountingServer(t, http.StatusInternalServerError, &failingRequests)
+	healthy := newCountingServer(t, http.StatusOK, &healthyRequests)
+	transport := NewFailoverTransport(
+		[]factory.Endpoint{endpoint(t, failing), endpoint(t, healthy)},
+		NewHTTPClient(config.HTTPClientConfig{MaxIdleConnsPerHost: 1, IdleConnTimeout: time.Minute}),
+		nil,
+		// the failback makes every request start from the failing endpoint
+		0,
+		config.CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Hour, SuccessThreshold: 1},
+		stubs.NewTestLogger(),
+	)
+	apiClient := client.New(transport, nil)
+
+	for i := 0; i < 5; i++ {
+		req.NoError(getLatestBlock(apiClient))
+	}
+	req.EqualValues(2, atomic.LoadInt32(&failingRequests), "the endpoint is skipped once its circuit is open")
+	req.EqualValues(5, atomic.LoadInt32(&healthyRequests))
+	stats := transport.EndpointsStats()
+	req.Equal(BreakerOpen, stats[0].Breaker)
+	req.Equal(BreakerClosed, stats[1].Breaker)
+}
+
+func TestFailoverTransportAllCircuitsOpen(t *testing.T) {
+	var requests int32
+	failing := newCountingServer(t, http.StatusInternalServerError, &requests)
+	transport := NewFailoverTransport(
+		[]factory.Endpoint{endpoint(t, failing)},
+		NewHTTPClient(config.HTTPClientConfig{MaxIdleConnsPerHost: 1, IdleConnTimeout: time.Minute}),
+		nil,
+		time.Hour,
+		config.CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Hour, SuccessThreshold: 1},
+		stubs.NewTestLogger(),
+	)
+	apiClient := client.New(transport, nil)
+
+	require.Error(t, getLatestBlock(apiClient))
+	require.ErrorIs(t, getLatestBlock(apiClient), ErrCircuitOpen)
+	require.EqualValues(t, 1, atomic.LoadInt32(&requests))
+}
+
+func TestFailoverTransportBreakerCountsEndpointErrors(t *testing.T) {
+	req := require.New(t)
+	cfg := config.HTTPClientConfig{
+		MaxIdleConnsPerHost: 1,
+		IdleConnTimeout:     time.Minute,
+		CircuitBreaker:      config.CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Hour, SuccessThreshold: 1},
+	}
+	newTransport := func(ts *httptest.Server) (*FailoverTransport, *client.TerraRESTApis) {
+		logger := stubs.NewTestLogger()
+		failover := NewFailoverTransport([]factory.Endpoint{endpoint(t, ts)}, NewHTTPClient(cfg), nil, time.Hour, cfg.CircuitBreaker, logger)
+		return failover, client.New(NewRequestTransport(failover, cfg, logger), nil)
+	}
+
+	// the errors of the request itself don't open the circuit
+	for name, response := range map[string]struct {
+		status int
+		body   string
+	}{
+		"client error": {status: http.StatusNotFound, body: "{}"},
+		"decode error": {status: http.StatusOK, body: "not a json"},
+	} {
+		ts, requests := newScriptedServer(t, response.body, 0, response.status)
+		failover, apiClient := newTransport(ts)
+		req.Error(getLatestBlockAs(nil, apiClient), name)
+		req.Error(getLatestBlockAs(nil, apiClient), name)
+		req.EqualValues(2, atomic.LoadInt32(requests), name)
+		req.Equal(BreakerClosed, failover.EndpointsStats()[0].Breaker, name)
+	}
+
+	ts, _ := newScriptedServer(t, "{}", 0, http.StatusBadGateway)
+	failover, apiClient := newTransport(ts)
+	req.Error(getLatestBlockAs(nil, apiClient))
+	req.Equal(BreakerOpen, failover.EndpointsStats()[0].Breaker)
+}
+
+func TestFailoverTransportHungEndpoint(t *testing.T) {
+	req := require.New(t)
+	cfg := config.HTTPClientConfig{
+		MaxIdleConnsPerHost: 1,
+		IdleConnTimeout:     time.Minute,
+		CircuitBreaker:      config.CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Hour, SuccessThreshold: 1},
+	}
+	hung, _ := newScriptedServer(t, "{}", 2*time.Second, http.StatusOK)
+	healthy, healthyRequests := newScriptedServer(t, "{}", 0, http.StatusOK)
+	newTransport := func(cfg config.HTTPClientConfig) (*FailoverTransport, *client.TerraRESTApis) {
+		logger := stubs.NewTestLogger()
+		failover := NewFailoverTransport([]factory.Endpoint{endpoint(t, hung), endpoint(t, healthy)},
+			NewHTTPClient(cfg), nil, time.Hour, cfg.CircuitBreaker, logger)
+		return failover, client.New(NewRequestTransport(failover, cfg, logger), nil)
+	}
+
+	// the backup serves the requests once the hung endpoint times out
+	timeoutCfg := cfg
+	timeoutCfg.RequestTimeout = 100 * time.Millisecond
+	failover, apiClient := newTransport(timeoutCfg)
+	for i := 0; i < 2; i++ {
+		req.NoError(getLatestBlockAs(nil, apiClient))
+	}
+	req.EqualValues(2, atomic.LoadInt32(healthyRequests))
+	stats := failover.EndpointsStats()
+	req.Equal(BreakerClosed, stats[1].Breaker)
+	req.EqualValues(2, stats[1].Successes)
+	req.EqualValues(0, stats[1].Failures)
+
+	// the backup isn't charged with the caller deadline passed before it's tried
+	failover, apiClient = newTransport(cfg)
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		params := tendermint_rpc.GetBlocksLatestParams{}
+		params.SetContext(ctx)
+		_, err := apiClient.TendermintRPC.GetBlocksLatest(&params)
+		cancel()
+		req.ErrorIs(err, context.DeadlineExceeded)
+	}
+	req.EqualValues(2, atomic.LoadInt32(healthyRequests))
+	stats = failover.EndpointsStats()
+	req.Equal(BreakerClosed, stats[0].Breaker, "the caller deadline says nothing about the endpoint")
+	req.Equal(BreakerClosed, stats[1].Breaker)
+	req.EqualValues(0, stats[1].Successes+stats[1].Failures)
+}
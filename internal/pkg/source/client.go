@@ -0,0 +1,67 @@
+package source
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/factory"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewClient creates a Terra REST API client to the source endpoints. The client is safe for concurrent use
+// and is meant to be shared by all the monitors of a deployment, so they reuse the connections and follow
+// the same failover decisions. The requests are limited by the timeout and retried by RequestTransport.
+// The returned failover transport reports the endpoints stats. The limiter is meant to be shared by all the clients
+// of the process, so the limits apply to the total traffic.
+func NewClient(
+	source config.Source,
+	cfg config.HTTPClientConfig,
+	limiter *RateLimiter,
+	logger *logrus.Logger,
+) (*client.TerraRESTApis, *FailoverTransport) {
+	transport := NewFailoverTransport(
+		sourceToEndpoints(source),
+		NewHTTPClient(cfg),
+		limiter,
+		cfg.FailbackInterval,
+		cfg.CircuitBreaker,
+		logger,
+	)
+	return client.New(NewRequestTransport(transport, cfg, logger), nil), transport
+}
+
+// sourceToEndpoints parses the source parameters and creates a list of endpoints based on it.
+func sourceToEndpoints(source config.Source) []factory.Endpoint {
+	endpoints := make([]factory.Endpoint, 0, len(source.Endpoints))
+	for _, endpoint := range source.Endpoints {
+		endpoints = append(endpoints, factory.Endpoint{
+			Host:    endpoint,
+			Schemes: source.Schemes,
+		})
+	}
+	return endpoints
+}
+
+// NewHTTPClient creates an HTTP client with the keep-alive connections limited by the config.
+func NewHTTPClient(cfg config.HTTPClientConfig) *http.Client {
+	return &http.Client{
+		Transport: statusRecorder{next: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          0, // limited by MaxIdleConnsPerHost
+			MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+			MaxConnsPerHost:       cfg.MaxConnsPerHost,
+			IdleConnTimeout:       cfg.IdleConnTimeout,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		}},
+	}
+}
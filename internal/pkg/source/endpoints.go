@@ -0,0 +1,224 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/tendermint_rpc"
+)
+
+// LatencyBuckets are the upper bounds of the request latency and the rate limiter wait time histograms buckets
+// in seconds.
+var LatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// histogram counts the durations by LatencyBuckets.
+type histogram struct {
+	buckets []uint64
+	sum     time.Duration
+	count   uint64
+}
+
+func newHistogram() *histogram {
+	return &histogram{buckets: make([]uint64, len(LatencyBuckets))}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	h.count++
+	h.sum += d
+	for i, bound := range LatencyBuckets {
+		if d.Seconds() <= bound {
+			h.buckets[i]++
+		}
+	}
+}
+
+// cumulativeBuckets returns the cumulative counts by the LatencyBuckets upper bounds.
+func (h *histogram) cumulativeBuckets() map[float64]uint64 {
+	buckets := make(map[float64]uint64, len(LatencyBuckets))
+	for i, bound := range LatencyBuckets {
+		buckets[bound] = h.buckets[i]
+	}
+	return buckets
+}
+
+// EndpointStats describes the requests to an endpoint and its latest block probes.
+type EndpointStats struct {
+	Host string
+	// Active reports whether the requests are sent to the endpoint first
+	Active bool
+	// Breaker is the state of the endpoint circuit breaker
+	Breaker   BreakerState
+	Successes uint64
+	Failures  uint64
+	// LatencyBuckets are the cumulative numbers of the requests by the LatencyBuckets upper bounds
+	LatencyBuckets map[float64]uint64
+	// LatencySum is the total duration of the requests
+	LatencySum time.Duration
+
+	// LastProbe is the time of the last probe, zero if the endpoint was never probed
+	LastProbe time.Time
+	// ProbeError is the error of the last probe
+	ProbeError error
+	// LatestHeight is the latest block height returned by the last successful probe
+	LatestHeight int64
+}
+
+// ProbeUp reports whether the last probe of the endpoint succeeded.
+func (s EndpointStats) ProbeUp() bool {
+	return !s.LastProbe.IsZero() && s.ProbeError == nil
+}
+
+// endpointStats accumulates the EndpointStats fields, except for Host and Active known from the transport.
+type endpointStats struct {
+	successes uint64
+	failures  uint64
+	latency   *histogram
+
+	lastProbe    time.Time
+	probeError   error
+	latestHeight int64
+}
+
+func newEndpointsStats(n int) []*endpointStats {
+	stats := make([]*endpointStats, n)
+	for i := range stats {
+		stats[i] = &endpointStats{latency: newHistogram()}
+	}
+	return stats
+}
+
+func (s *endpointStats) recordRequest(latency time.Duration, err error) {
+	if err != nil {
+		s.failures++
+	} else {
+		s.successes++
+	}
+	s.latency.observe(latency)
+}
+
+// recordRequest records the request to the endpoint id.
+func (t *FailoverTransport) recordRequest(id int, latency time.Duration, err error) {
+	t.statsLock.Lock()
+	defer t.statsLock.Unlock()
+	t.stats[id].recordRequest(latency, err)
+}
+
+// EndpointsStats returns the stats of the endpoints sorted by priority. Reading the stats doesn't fail back,
+// the endpoint is active until the next request fails back to the first one.
+func (t *FailoverTransport) EndpointsStats() []EndpointStats {
+	t.lock.Lock()
+	current := t.current
+	t.lock.Unlock()
+	t.statsLock.Lock()
+	defer t.statsLock.Unlock()
+	stats := make([]EndpointStats, 0, len(t.endpoints))
+	for id, endpoint := range t.endpoints {
+		s := t.stats[id]
+		endpointStats := EndpointStats{
+			Host:           endpoint.Host,
+			Active:         id == current,
+			Breaker:        t.breakers[id].currentState(),
+			Successes:      s.successes,
+			Failures:       s.failures,
+			LatencyBuckets: s.latency.cumulativeBuckets(),
+			LatencySum:     s.latency.sum,
+			LastProbe:      s.lastProbe,
+			ProbeError:     s.probeError,
+			LatestHeight:   s.latestHeight,
+		}
+		stats = append(stats, endpointStats)
+	}
+	return stats
+}
+
+// Failovers returns the number of the switches from the current endpoint to another one, the failbacks
+// to the first endpoint are not counted.
+func (t *FailoverTransport) Failovers() uint64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.failovers
+}
+
+// BlockLags returns the number of blocks every endpoint lags behind the most advanced one by the last probes,
+// keyed by the endpoint host. The endpoints never probed successfully are omitted.
+func BlockLags(stats []EndpointStats) map[string]int64 {
+	var latest int64
+	for _, s := range stats {
+		if s.LatestHeight > latest {
+			latest = s.LatestHeight
+		}
+	}
+	lags := make(map[string]int64)
+	for _, s := range stats {
+		if s.LatestHeight > 0 {
+			lags[s.Host] = latest - s.LatestHeight
+		}
+	}
+	return lags
+}
+
+// Probe requests the latest block of every endpoint each interval until the ctx is done, so a lagging endpoint
+// can be detected even if it doesn't serve the requests. Every probe is limited by the timeout.
+func (t *FailoverTransport) Probe(ctx context.Context, interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		t.probeAll(ctx, timeout)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *FailoverTransport) probeAll(ctx context.Context, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for id := range t.endpoints {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			height, err := t.probe(ctx, id, timeout)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				t.logger.Warningf("failed to probe endpoint %s: %v", t.endpoints[id].Host, err)
+			}
+
+			t.statsLock.Lock()
+			defer t.statsLock.Unlock()
+			s := t.stats[id]
+			s.lastProbe, s.probeError = time.Now(), err
+			if err == nil {
+				s.latestHeight = height
+			}
+		}(id)
+	}
+	wg.Wait()
+}
+
+// probe returns the latest block height of the endpoint id.
+func (t *FailoverTransport) probe(ctx context.Context, id int, timeout time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req := tendermint_rpc.GetBlocksLatestParams{}
+	req.SetContext(ctx)
+	resp, err := client.New(t.endpoints[id], nil).TendermintRPC.GetBlocksLatest(&req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	block := resp.GetPayload().Block
+	if block == nil || block.Header == nil {
+		return 0, fmt.Errorf("failed to get latest block: no block header in response")
+	}
+	height, err := strconv.ParseInt(block.Header.Height, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse latest block height: %w", err)
+	}
+	return height, nil
+}
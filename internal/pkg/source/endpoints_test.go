@@ -0,0 +1,131 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/factory"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newBlockServer responds with the latest block of the height.
+func newBlockServer(t *testing.T, height int) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"block":{"header":{"height":"%d"}}}`, height)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func newTestFailoverTransport(endpoints ...factory.Endpoint) *FailoverTransport {
+	return NewFailoverTransport(
+		endpoints,
+		NewHTTPClient(config.HTTPClientConfig{MaxIdleConnsPerHost: 1, IdleConnTimeout: time.Minute}),
+		nil,
+		time.Hour,
+		config.CircuitBreakerConfig{},
+		stubs.NewTestLogger(),
+	)
+}
+
+func TestFailoverTransportEndpointsStats(t *testing.T) {
+	req := require.New(t)
+
+	var failingRequests, healthyRequests int32
+	failing := newCountingServer(t, http.StatusInternalServerError, &failingRequests)
+	healthy := newCountingServer(t, http.StatusOK, &healthyRequests)
+	transport := newTestFailoverTransport(endpoint(t, failing), endpoint(t, healthy))
+	apiClient := client.New(transport, nil)
+
+	req.NoError(getLatestBlock(apiClient))
+	req.NoError(getLatestBlock(apiClient))
+
+	stats := transport.EndpointsStats()
+	req.Len(stats, 2)
+	req.Equal(endpoint(t, failing).Host, stats[0].Host)
+	req.False(stats[0].Active)
+	req.EqualValues(0, stats[0].Successes)
+	req.EqualValues(1, stats[0].Failures)
+	req.True(stats[1].Active)
+	req.EqualValues(2, stats[1].Successes)
+	req.EqualValues(0, stats[1].Failures)
+	req.EqualValues(2, stats[1].LatencyBuckets[LatencyBuckets[len(LatencyBuckets)-1]])
+	req.Positive(int64(stats[1].LatencySum))
+	req.EqualValues(1, transport.Failovers())
+}
+
+func TestFailoverTransportEndpointsStatsNoFailback(t *testing.T) {
+	req := require.New(t)
+
+	var failingRequests, healthyRequests int32
+	failing := newCountingServer(t, http.StatusInternalServerError, &failingRequests)
+	healthy := newCountingServer(t, http.StatusOK, &healthyRequests)
+	transport := NewFailoverTransport(
+		[]factory.Endpoint{endpoint(t, failing), endpoint(t, healthy)},
+		NewHTTPClient(config.HTTPClientConfig{MaxIdleConnsPerHost: 1, IdleConnTimeout: time.Minute}),
+		nil,
+		// the failback is due right after the failover
+		0,
+		config.CircuitBreakerConfig{},
+		stubs.NewTestLogger(),
+	)
+	req.NoError(getLatestBlock(client.New(transport, nil)))
+
+	for i := 0; i < 2; i++ {
+		stats := transport.EndpointsStats()
+		req.False(stats[0].Active)
+		req.True(stats[1].Active, "reading the stats must not fail back")
+	}
+	req.EqualValues(1, atomic.LoadInt32(&failingRequests))
+}
+
+func TestFailoverTransportProbe(t *testing.T) {
+	req := require.New(t)
+
+	ahead := newBlockServer(t, 1005)
+	lagging := newBlockServer(t, 1000)
+	var requests int32
+	failing := newCountingServer(t, http.StatusInternalServerError, &requests)
+	transport := newTestFailoverTransport(endpoint(t, lagging), endpoint(t, ahead), endpoint(t, failing))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		transport.Probe(ctx, time.Hour, time.Second)
+	}()
+	req.Eventually(func() bool {
+		for _, stats := range transport.EndpointsStats() {
+			if stats.LastProbe.IsZero() {
+				return false
+			}
+		}
+		return true
+	}, time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	stats := transport.EndpointsStats()
+	req.True(stats[0].ProbeUp())
+	req.EqualValues(1000, stats[0].LatestHeight)
+	req.True(stats[1].ProbeUp())
+	req.EqualValues(1005, stats[1].LatestHeight)
+	req.False(stats[2].ProbeUp())
+	req.Error(stats[2].ProbeError)
+	req.Equal(map[string]int64{
+		endpoint(t, lagging).Host: 5,
+		endpoint(t, ahead).Host:   0,
+	}, BlockLags(stats))
+	// the probes are not counted as the requests
+	req.EqualValues(0, stats[0].Successes+stats[1].Successes)
+}
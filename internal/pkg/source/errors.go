@@ -0,0 +1,106 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// ErrorClass is the kind of a failed request.
+type ErrorClass string
+
+const (
+	// NetworkError is a failed connection or a timed out request, it's retryable.
+	NetworkError ErrorClass = "network"
+	// HTTPStatusError is a response with a non-2xx status, only 5xx and 429 statuses are retryable.
+	HTTPStatusError ErrorClass = "http_status"
+	// DecodeError is a 2xx response which can't be decoded to the operation model.
+	DecodeError ErrorClass = "decode"
+	// ValidationError is a decoded response failed the schema validation.
+	ValidationError ErrorClass = "validation"
+	// RateLimitError is a request which can't be let through by the rate limiter within the attempt timeout,
+	// it's not retryable.
+	RateLimitError ErrorClass = "rate_limit"
+)
+
+// ErrorClasses lists all the request error classes.
+var ErrorClasses = []ErrorClass{NetworkError, HTTPStatusError, DecodeError, ValidationError, RateLimitError}
+
+// RequestError is an API request error classified by its cause.
+type RequestError struct {
+	Class ErrorClass
+	// Operation is the API operation ID, empty for the validation errors
+	Operation string
+	// StatusCode is the HTTP status of the response, 0 if there was no response
+	StatusCode int
+	Err        error
+}
+
+func (e *RequestError) Error() string {
+	switch {
+	case e.Operation == "":
+		return fmt.Sprintf("%s error: %v", e.Class, e.Err)
+	case e.StatusCode != 0:
+		return fmt.Sprintf("%s error of %s (status %d): %v", e.Class, e.Operation, e.StatusCode, e.Err)
+	default:
+		return fmt.Sprintf("%s error of %s: %v", e.Class, e.Operation, e.Err)
+	}
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the request may succeed if it's sent again.
+func (e *RequestError) Retryable() bool {
+	switch e.Class {
+	case NetworkError:
+		return true
+	case HTTPStatusError:
+		return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+	default:
+		return false
+	}
+}
+
+// ClassOf returns the class of the request error, the empty class if the err is not a RequestError.
+func ClassOf(err error) ErrorClass {
+	var requestErr *RequestError
+	if errors.As(err, &requestErr) {
+		return requestErr.Class
+	}
+	return ""
+}
+
+// classify builds the request error by the status of the last response, statusCode is 0 if there was no response.
+func classify(operation string, statusCode int, err error) *RequestError {
+	requestErr := &RequestError{Operation: operation, StatusCode: statusCode, Err: err}
+	switch {
+	case statusCode == 0:
+		requestErr.Class = NetworkError
+	case statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices:
+		requestErr.Class = HTTPStatusError
+	default:
+		requestErr.Class = DecodeError
+	}
+	return requestErr
+}
+
+// Payload is a decoded response model.
+type Payload interface {
+	Validate(formats strfmt.Registry) error
+}
+
+// ValidatePayload validates the response payload against the API schema. The validation error is counted
+// as a request error of the ctx caller.
+func ValidatePayload(ctx context.Context, payload Payload) error {
+	if err := payload.Validate(nil); err != nil {
+		caller := callerFromContext(ctx)
+		caller.Stats.recordError(caller.Monitor, ValidationError)
+		return &RequestError{Class: ValidationError, Err: err}
+	}
+	return nil
+}
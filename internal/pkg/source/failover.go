@@ -0,0 +1,189 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/factory"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+
+	"github.com/go-openapi/runtime"
+	openapiTransport "github.com/go-openapi/runtime/client"
+	"github.com/sirupsen/logrus"
+)
+
+// FailoverTransport is the runtime.ClientTransport implementation querying the endpoints one after another until
+// the request succeeds. Unlike the failover transport of the factory package, it sticks to the last healthy endpoint,
+// so the failover decision made by a request applies to the subsequent ones. The requests return
+// to the most prioritized endpoint (the first one) after the failback interval. The request to every endpoint
+// is limited by the timeout set to the operation context by RequestTransport.
+type FailoverTransport struct {
+	endpoints        []*openapiTransport.Runtime
+	failbackInterval time.Duration
+	limiter          *RateLimiter
+	// breakers have the same indexes as the endpoints
+	breakers []*circuitBreaker
+	logger   *logrus.Logger
+
+	lock sync.Mutex
+	// current is the index of the endpoint the requests are sent to first
+	current int
+	// switchedAt is the time of the last failover
+	switchedAt time.Time
+	failovers  uint64
+
+	// statsLock guards the stats of the endpoints, they have the same indexes as the endpoints
+	statsLock sync.Mutex
+	stats     []*endpointStats
+}
+
+// NewFailoverTransport creates a transport to the endpoints sorted by priority, all of them share the httpClient.
+// Every request to an endpoint waits for the limiter, the nil limiter limits nothing. The endpoints are skipped
+// while their circuit breakers configured by breakerCfg are open.
+func NewFailoverTransport(
+	endpoints []factory.Endpoint,
+	httpClient *http.Client,
+	limiter *RateLimiter,
+	failbackInterval time.Duration,
+	breakerCfg config.CircuitBreakerConfig,
+	logger *logrus.Logger,
+) *FailoverTransport {
+	t := &FailoverTransport{
+		failbackInterval: failbackInterval,
+		limiter:          limiter,
+		logger:           logger,
+	}
+	for _, endpoint := range endpoints {
+		t.endpoints = append(
+			t.endpoints,
+			openapiTransport.NewWithClient(endpoint.Host, client.DefaultBasePath, endpoint.Schemes, httpClient),
+		)
+		t.breakers = append(t.breakers, newCircuitBreaker(breakerCfg))
+	}
+	t.stats = newEndpointsStats(len(t.endpoints))
+	return t
+}
+
+// Current returns the host of the endpoint the requests are sent to first.
+func (t *FailoverTransport) Current() string {
+	return t.endpoints[t.first()].Host
+}
+
+// first returns the index of the endpoint to start the requests from.
+func (t *FailoverTransport) first() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.current != 0 && time.Since(t.switchedAt) >= t.failbackInterval {
+		t.logger.Infof("failing back to endpoint %s", t.endpoints[0].Host)
+		t.current = 0
+	}
+	return t.current
+}
+
+// failover makes the endpoint the first one for the subsequent requests, unless another request has already
+// changed the current endpoint.
+func (t *FailoverTransport) failover(from, to int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.current != from {
+		return
+	}
+	t.logger.Warningf("failing over from endpoint %s to %s", t.endpoints[from].Host, t.endpoints[to].Host)
+	t.current = to
+	t.switchedAt = time.Now()
+	t.failovers++
+}
+
+func (t *FailoverTransport) Submit(operation *runtime.ClientOperation) (interface{}, error) {
+	if len(t.endpoints) == 0 {
+		return nil, fmt.Errorf("failed to Submit: no endpoints configured")
+	}
+
+	ctx := operation.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	first := t.first()
+	var err error
+	for i := 0; i < len(t.endpoints); i++ {
+		if ctx.Err() != nil {
+			// the caller has given up, the endpoints not tried yet must not be charged with its error
+			return nil, ctx.Err()
+		}
+		id := (first + i) % len(t.endpoints)
+		breaker := t.breakers[id]
+		if !breaker.allow() {
+			t.logger.Debugf("skipping endpoint #%d (%s): %v", id, t.endpoints[id].Host, ErrCircuitOpen)
+			continue
+		}
+		endpointCtx, cancel := endpointContext(ctx)
+		if err := t.limiter.Wait(endpointCtx, t.endpoints[id].Host); err != nil {
+			timedOut := endpointCtx.Err() != nil
+			cancel()
+			breaker.release()
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if timedOut {
+				// the endpoint timeout is over, it's classified as a network error and retried
+				return nil, fmt.Errorf("failed to wait for rate limiter: %w", err)
+			}
+			// the limiter can't let the request through before the deadline, it must not be classified
+			// as a network error
+			return nil, &RequestError{
+				Class:     RateLimitError,
+				Operation: operation.ID,
+				Err:       fmt.Errorf("failed to wait for rate limiter: %w", err),
+			}
+		}
+		var resp interface{}
+		endpointOperation := *operation
+		endpointOperation.Context = endpointCtx
+		start := time.Now()
+		resp, err = t.endpoints[id].Submit(&endpointOperation)
+		cancel()
+		t.recordRequest(id, time.Since(start), err)
+		if ctx.Err() != nil {
+			// the caller has given up or its deadline is over, it says nothing about the endpoint
+			breaker.release()
+		} else {
+			breaker.record(endpointError(ctx, err))
+		}
+		if err != nil {
+			t.logger.Errorf("failed to Submit to endpoint #%d (%s): %s", id, t.endpoints[id].Host, err)
+			continue
+		}
+		if id != first {
+			t.failover(first, id)
+		}
+		return resp, nil
+	}
+	if err == nil {
+		// no endpoint was requested
+		err = ErrCircuitOpen
+	}
+	return nil, fmt.Errorf("failed to Submit (all retries failed): %w", err)
+}
+
+// endpointError returns the request error if it tells the endpoint is unhealthy, i.e. it's a network error,
+// a timeout or a 5xx response, or nil otherwise, e.g. for the 4xx responses and the decode errors, which are
+// the errors of the request itself. The request error is returned as is if the response status isn't recorded
+// to the ctx by statusRecorder.
+func endpointError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	status, recorded := ctx.Value(statusKey{}).(*int32)
+	if !recorded {
+		return err
+	}
+	if code := atomic.LoadInt32(status); code != 0 && code < http.StatusInternalServerError {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/tendermint_rpc"
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/factory"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingServer responds with the status code and counts the requests.
+func newCountingServer(t *testing.T, status int, requests *int32) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func endpoint(t *testing.T, ts *httptest.Server) factory.Endpoint {
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	return factory.Endpoint{Host: u.Host, Schemes: []string{u.Scheme}}
+}
+
+func getLatestBlock(apiClient *client.TerraRESTApis) error {
+	req := tendermint_rpc.GetBlocksLatestParams{}
+	req.SetContext(context.Background())
+	_, err := apiClient.TendermintRPC.GetBlocksLatest(&req)
+	return err
+}
+
+func TestFailoverTransportSticksToHealthyEndpoint(t *testing.T) {
+	req := require.New(t)
+
+	var failingRequests, healthyRequests int32
+	failing := newCountingServer(t, http.StatusInternalServerError, &failingRequests)
+	healthy := newCountingServer(t, http.StatusOK, &healthyRequests)
+
+	transport := NewFailoverTransport(
+		[]factory.Endpoint{endpoint(t, failing), endpoint(t, healthy)},
+		NewHTTPClient(config.HTTPClientConfig{MaxIdleConnsPerHost: 1, IdleConnTimeout: time.Minute}),
+		nil,
+		time.Hour,
+		config.CircuitBreakerConfig{},
+		stubs.NewTestLogger(),
+	)
+	apiClient := client.New(transport, nil)
+
+	req.NoError(getLatestBlock(apiClient))
+	req.Equal(int32(1), atomic.LoadInt32(&failingRequests))
+	req.Equal(endpoint(t, healthy).Host, transport.Current())
+
+	// the failover decision is applied to the subsequent requests
+	req.NoError(getLatestBlock(apiClient))
+	req.NoError(getLatestBlock(apiClient))
+	req.Equal(int32(1), atomic.LoadInt32(&failingRequests))
+	req.Equal(int32(3), atomic.LoadInt32(&healthyRequests))
+}
+
+func TestFailoverTransportFailback(t *testing.T) {
+	req := require.New(t)
+
+	var primaryRequests, backupRequests int32
+	primary := newCountingServer(t, http.StatusOK, &primaryRequests)
+	backup := newCountingServer(t, http.StatusOK, &backupRequests)
+
+	transport := NewFailoverTransport(
+		[]factory.Endpoint{endpoint(t, primary), endpoint(t, backup)},
+		NewHTTPClient(config.HTTPClientConfig{MaxIdleConnsPerHost: 1, IdleConnTimeout: time.Minute}),
+		nil,
+		50*time.Millisecond,
+		config.CircuitBreakerConfig{},
+		stubs.NewTestLogger(),
+	)
+	transport.failover(0, 1)
+	req.Equal(endpoint(t, backup).Host, transport.Current(), "the failback interval is not passed yet")
+
+	time.Sleep(60 * time.Millisecond)
+	req.NoError(getLatestBlock(client.New(transport, nil)))
+	req.Equal(int32(1), atomic.LoadInt32(&primaryRequests))
+	req.Equal(int32(0), atomic.LoadInt32(&backupRequests))
+}
+
+func TestFailoverTransportAllEndpointsFailed(t *testing.T) {
+	var requests int32
+	failing := newCountingServer(t, http.StatusInternalServerError, &requests)
+
+	transport := NewFailoverTransport(
+		[]factory.Endpoint{endpoint(t, failing), endpoint(t, failing)},
+		NewHTTPClient(config.HTTPClientConfig{MaxIdleConnsPerHost: 1, IdleConnTimeout: time.Minute}),
+		nil,
+		time.Hour,
+		config.CircuitBreakerConfig{},
+		stubs.NewTestLogger(),
+	)
+	err := getLatestBlock(client.New(transport, nil))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "all retries failed")
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
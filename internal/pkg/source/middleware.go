@@ -0,0 +1,123 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+
+	"github.com/go-openapi/runtime"
+	"github.com/sirupsen/logrus"
+)
+
+// RequestTransport is the runtime.ClientTransport middleware limiting the request to every endpoint by the timeout
+// and retrying the requests failed with the retryable errors with an exponential backoff. The returned errors
+// are *RequestError, the failed attempts and the retries are counted by the caller from the operation context.
+type RequestTransport struct {
+	next            runtime.ClientTransport
+	timeout         time.Duration
+	maxRetries      int
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+	logger          *logrus.Logger
+}
+
+// NewRequestTransport wraps the next transport, which must send the requests with an HTTP client
+// created by NewHTTPClient, so the response statuses are known to classify the errors.
+func NewRequestTransport(next runtime.ClientTransport, cfg config.HTTPClientConfig, logger *logrus.Logger) *RequestTransport {
+	return &RequestTransport{
+		next:            next,
+		timeout:         cfg.RequestTimeout,
+		maxRetries:      cfg.MaxRetries,
+		retryBackoff:    cfg.RetryBackoff,
+		maxRetryBackoff: cfg.MaxRetryBackoff,
+		logger:          logger,
+	}
+}
+
+func (t *RequestTransport) Submit(operation *runtime.ClientOperation) (interface{}, error) {
+	ctx := operation.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	caller := callerFromContext(ctx)
+
+	backoff := t.retryBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.submit(ctx, operation)
+		if err == nil {
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			// the caller has given up, it's not a request error
+			return nil, ctx.Err()
+		}
+		caller.Stats.recordError(caller.Monitor, err.Class)
+		if !err.Retryable() || attempt >= t.maxRetries {
+			return nil, err
+		}
+
+		t.logger.Warningf("retrying %s in %s after attempt #%d failed: %v", operation.ID, backoff, attempt+1, err)
+		caller.Stats.recordRetry(caller.Monitor)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > t.maxRetryBackoff {
+			backoff = t.maxRetryBackoff
+		}
+	}
+}
+
+// submit makes a single request attempt. The timeout is applied by the next transport to the request to every
+// endpoint, so the endpoint timed out leaves the time to fail over to the next one.
+func (t *RequestTransport) submit(ctx context.Context, operation *runtime.ClientOperation) (interface{}, *RequestError) {
+	status := new(int32)
+	attemptOperation := *operation
+	attemptOperation.Context = context.WithValue(context.WithValue(ctx, statusKey{}, status), timeoutKey{}, t.timeout)
+
+	resp, err := t.next.Submit(&attemptOperation)
+	var requestErr *RequestError
+	if errors.As(err, &requestErr) && requestErr.Class == RateLimitError {
+		return nil, requestErr
+	}
+	if err != nil {
+		return nil, classify(operation.ID, int(atomic.LoadInt32(status)), err)
+	}
+	return resp, nil
+}
+
+type statusKey struct{}
+
+type timeoutKey struct{}
+
+// endpointContext limits the ctx by the timeout of a request to a single endpoint set by RequestTransport, if any.
+func endpointContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout, _ := ctx.Value(timeoutKey{}).(time.Duration); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// statusRecorder stores the status of the last response to the request context,
+// so RequestTransport can tell the network errors from the HTTP status and decode ones.
+type statusRecorder struct {
+	next http.RoundTripper
+}
+
+func (r statusRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, _ := req.Context().Value(statusKey{}).(*int32)
+	if status != nil {
+		atomic.StoreInt32(status, 0)
+	}
+	resp, err := r.next.RoundTrip(req)
+	if err == nil && status != nil {
+		atomic.StoreInt32(status, int32(resp.StatusCode))
+	}
+	return resp, err
+}
@@ -0,0 +1,231 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client"
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/client/tendermint_rpc"
+	"github.com/lidofinance/terra-fcd-rest-client/columbus-5/factory"
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+	"github.com/lidofinance/terra-monitors/internal/pkg/stubs"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+)
+
+const testMonitor = "TestMonitor"
+
+// newScriptedServer responds with the statuses one after another and repeats the last one.
+func newScriptedServer(t *testing.T, body string, delay time.Duration, statuses ...int) (*httptest.Server, *int32) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if n > len(statuses) {
+			n = len(statuses)
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statuses[n-1])
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &requests
+}
+
+func newTestRequestClient(t *testing.T, ts *httptest.Server, cfg config.HTTPClientConfig) *client.TerraRESTApis {
+	cfg.MaxIdleConnsPerHost = 1
+	cfg.IdleConnTimeout = time.Minute
+	logger := stubs.NewTestLogger()
+	failover := NewFailoverTransport([]factory.Endpoint{endpoint(t, ts)}, NewHTTPClient(cfg), nil, time.Hour, cfg.CircuitBreaker, logger)
+	return client.New(NewRequestTransport(failover, cfg, logger), nil)
+}
+
+func getLatestBlockAs(stats *RequestStats, apiClient *client.TerraRESTApis) error {
+	req := tendermint_rpc.GetBlocksLatestParams{}
+	req.SetContext(WithCaller(context.Background(), Caller{Monitor: testMonitor, Stats: stats}))
+	_, err := apiClient.TendermintRPC.GetBlocksLatest(&req)
+	return err
+}
+
+var testRetryPolicy = config.HTTPClientConfig{
+	RequestTimeout:  time.Second,
+	MaxRetries:      2,
+	RetryBackoff:    time.Millisecond,
+	MaxRetryBackoff: 2 * time.Millisecond,
+}
+
+func TestRequestTransportRetriesServerErrors(t *testing.T) {
+	req := require.New(t)
+	ts, requests := newScriptedServer(t, "{}", 0, http.StatusServiceUnavailable, http.StatusOK)
+	stats := NewRequestStats()
+
+	req.NoError(getLatestBlockAs(stats, newTestRequestClient(t, ts, testRetryPolicy)))
+	req.EqualValues(2, atomic.LoadInt32(requests))
+	req.Equal(map[RequestErrorsKey]uint64{{Monitor: testMonitor, Class: HTTPStatusError}: 1}, stats.Errors())
+	req.Equal(map[string]uint64{testMonitor: 1}, stats.Retries())
+}
+
+func TestRequestTransportDoesNotRetryClientErrors(t *testing.T) {
+	req := require.New(t)
+	ts, requests := newScriptedServer(t, "{}", 0, http.StatusNotFound)
+	stats := NewRequestStats()
+
+	err := getLatestBlockAs(stats, newTestRequestClient(t, ts, testRetryPolicy))
+	var requestErr *RequestError
+	req.True(errors.As(err, &requestErr))
+	req.Equal(HTTPStatusError, requestErr.Class)
+	req.Equal(http.StatusNotFound, requestErr.StatusCode)
+	req.False(requestErr.Retryable())
+	req.EqualValues(1, atomic.LoadInt32(requests))
+	req.Empty(stats.Retries())
+}
+
+func TestRequestTransportTimeout(t *testing.T) {
+	req := require.New(t)
+	ts, requests := newScriptedServer(t, "{}", time.Second, http.StatusOK)
+	stats := NewRequestStats()
+	cfg := testRetryPolicy
+	cfg.RequestTimeout = 20 * time.Millisecond
+	cfg.MaxRetries = 1
+
+	start := time.Now()
+	err := getLatestBlockAs(stats, newTestRequestClient(t, ts, cfg))
+	req.Equal(NetworkError, ClassOf(err))
+	req.Less(int64(time.Since(start)), int64(500*time.Millisecond))
+	req.EqualValues(2, atomic.LoadInt32(requests))
+	req.Equal(map[RequestErrorsKey]uint64{{Monitor: testMonitor, Class: NetworkError}: 2}, stats.Errors())
+}
+
+func TestRequestTransportDecodeError(t *testing.T) {
+	req := require.New(t)
+	ts, requests := newScriptedServer(t, "not a json", 0, http.StatusOK)
+	stats := NewRequestStats()
+
+	err := getLatestBlockAs(stats, newTestRequestClient(t, ts, testRetryPolicy))
+	req.Equal(DecodeError, ClassOf(err))
+	req.EqualValues(1, atomic.LoadInt32(requests))
+	req.Equal(map[RequestErrorsKey]uint64{{Monitor: testMonitor, Class: DecodeError}: 1}, stats.Errors())
+}
+
+func TestRequestTransportCallerCancelled(t *testing.T) {
+	ts, _ := newScriptedServer(t, "{}", time.Second, http.StatusOK)
+	stats := NewRequestStats()
+	ctx, cancel := context.WithTimeout(WithCaller(context.Background(), Caller{Monitor: testMonitor, Stats: stats}),
+		20*time.Millisecond)
+	defer cancel()
+
+	params := tendermint_rpc.GetBlocksLatestParams{}
+	params.SetContext(ctx)
+	_, err := newTestRequestClient(t, ts, testRetryPolicy).TendermintRPC.GetBlocksLatest(&params)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Empty(t, stats.Errors(), "the caller cancellation is not a request error")
+}
+
+// testPayload fails the validation with err.
+type testPayload struct {
+	err error
+}
+
+func (p testPayload) Validate(strfmt.Registry) error {
+	return p.err
+}
+
+func TestValidatePayload(t *testing.T) {
+	stats := NewRequestStats()
+	ctx := WithCaller(context.Background(), Caller{Monitor: testMonitor, Stats: stats})
+
+	require.NoError(t, ValidatePayload(ctx, testPayload{}))
+
+	err := ValidatePayload(ctx, testPayload{err: errors.New("height in body is required")})
+	require.Equal(t, ValidationError, ClassOf(err))
+	require.EqualError(t, err, "validation error: height in body is required")
+	require.Equal(t, map[RequestErrorsKey]uint64{{Monitor: testMonitor, Class: ValidationError}: 1}, stats.Errors())
+}
+
+func TestRequestTransportRateLimited(t *testing.T) {
+	req := require.New(t)
+	ts, requests := newScriptedServer(t, "{}", 0, http.StatusOK)
+	stats := NewRequestStats()
+	cfg := testRetryPolicy
+	cfg.RequestTimeout = 50 * time.Millisecond
+	cfg.MaxIdleConnsPerHost = 1
+	cfg.IdleConnTimeout = time.Minute
+	logger := stubs.NewTestLogger()
+	limiter := NewRateLimiter(config.RateLimitConfig{EndpointRequestsPerSecond: 1, Burst: 1})
+	failover := NewFailoverTransport([]factory.Endpoint{endpoint(t, ts)}, NewHTTPClient(cfg), limiter, time.Hour, cfg.CircuitBreaker, logger)
+	apiClient := client.New(NewRequestTransport(failover, cfg, logger), nil)
+
+	req.NoError(getLatestBlockAs(stats, apiClient))
+	// the next token is available in a second, i.e. after the attempt timeout
+	err := getLatestBlockAs(stats, apiClient)
+	var requestErr *RequestError
+	req.True(errors.As(err, &requestErr))
+	req.Equal(RateLimitError, requestErr.Class)
+	req.False(requestErr.Retryable())
+	req.EqualValues(1, atomic.LoadInt32(requests))
+	req.Equal(map[RequestErrorsKey]uint64{{Monitor: testMonitor, Class: RateLimitError}: 1}, stats.Errors())
+	req.Empty(stats.Retries())
+	req.Equal(BreakerClosed, failover.breakers[0].currentState())
+}
+
+func TestRequestTransportTimeoutFailover(t *testing.T) {
+	req := require.New(t)
+	hung, hungRequests := newScriptedServer(t, "{}", 2*time.Second, http.StatusOK)
+	healthy, healthyRequests := newScriptedServer(t, "{}", 0, http.StatusOK)
+	stats := NewRequestStats()
+	cfg := testRetryPolicy
+	cfg.RequestTimeout = 200 * time.Millisecond
+	cfg.MaxIdleConnsPerHost = 1
+	cfg.IdleConnTimeout = time.Minute
+	logger := stubs.NewTestLogger()
+	failover := NewFailoverTransport([]factory.Endpoint{endpoint(t, hung), endpoint(t, healthy)},
+		NewHTTPClient(cfg), nil, time.Hour, cfg.CircuitBreaker, logger)
+	apiClient := client.New(NewRequestTransport(failover, cfg, logger), nil)
+
+	// the timed out request to the first endpoint leaves the time to fail over to the next one
+	start := time.Now()
+	req.NoError(getLatestBlockAs(stats, apiClient))
+	req.Less(int64(time.Since(start)), int64(time.Second))
+	req.EqualValues(1, atomic.LoadInt32(hungRequests))
+	req.EqualValues(1, atomic.LoadInt32(healthyRequests))
+	req.Empty(stats.Errors())
+
+	// the attempt timed out on all the endpoints is a network error and it's retried
+	both := NewFailoverTransport([]factory.Endpoint{endpoint(t, hung), endpoint(t, hung)},
+		NewHTTPClient(cfg), nil, time.Hour, cfg.CircuitBreaker, logger)
+	err := getLatestBlockAs(stats, client.New(NewRequestTransport(both, cfg, logger), nil))
+	req.Equal(NetworkError, ClassOf(err))
+	req.Equal(map[RequestErrorsKey]uint64{{Monitor: testMonitor, Class: NetworkError}: 3}, stats.Errors())
+	req.Equal(map[string]uint64{testMonitor: 2}, stats.Retries())
+}
+
+func TestRequestTransportTimeoutWithRateLimiter(t *testing.T) {
+	req := require.New(t)
+	ts, requests := newScriptedServer(t, "{}", time.Second, http.StatusOK)
+	stats := NewRequestStats()
+	cfg := testRetryPolicy
+	cfg.RequestTimeout = 20 * time.Millisecond
+	cfg.MaxRetries = 1
+	cfg.MaxIdleConnsPerHost = 1
+	cfg.IdleConnTimeout = time.Minute
+	logger := stubs.NewTestLogger()
+	limiter := NewRateLimiter(config.RateLimitConfig{EndpointRequestsPerSecond: 100, Burst: 10})
+	failover := NewFailoverTransport([]factory.Endpoint{endpoint(t, ts), endpoint(t, ts)}, NewHTTPClient(cfg), limiter, time.Hour, cfg.CircuitBreaker, logger)
+
+	// the timeouts are network errors even if the requests wait for the limiter
+	err := getLatestBlockAs(stats, client.New(NewRequestTransport(failover, cfg, logger), nil))
+	req.Equal(NetworkError, ClassOf(err))
+	req.EqualValues(4, atomic.LoadInt32(requests))
+	req.Equal(map[RequestErrorsKey]uint64{{Monitor: testMonitor, Class: NetworkError}: 2}, stats.Errors())
+	req.Equal(map[string]uint64{testMonitor: 1}, stats.Retries())
+}
@@ -0,0 +1,112 @@
+package source
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+
+	"golang.org/x/time/rate"
+)
+
+// WaitStats describes the time the requests to an endpoint spent waiting in the rate limiter.
+type WaitStats struct {
+	Count uint64
+	// Buckets are the cumulative numbers of the requests by the LatencyBuckets upper bounds
+	Buckets map[float64]uint64
+	Sum     time.Duration
+}
+
+// RateLimiter is a token bucket rate limiter of the API requests. Every request takes a token of the global bucket
+// and a token of the bucket of its endpoint, so a single limiter is meant to be shared by all the API clients
+// of the process. The nil RateLimiter limits nothing.
+type RateLimiter struct {
+	lock      sync.Mutex
+	cfg       config.RateLimitConfig
+	global    *rate.Limiter
+	endpoints map[string]*rate.Limiter
+	waits     map[string]*histogram
+}
+
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		cfg:       cfg,
+		global:    rate.NewLimiter(limit(cfg.RequestsPerSecond), cfg.Burst),
+		endpoints: make(map[string]*rate.Limiter),
+		waits:     make(map[string]*histogram),
+	}
+}
+
+// limit converts the requests per second to the rate limit, 0 means no limit.
+func limit(requestsPerSecond float64) rate.Limit {
+	if requestsPerSecond <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(requestsPerSecond)
+}
+
+// Configure applies the config to the global and the endpoints buckets, the tokens taken so far are kept.
+func (l *RateLimiter) Configure(cfg config.RateLimitConfig) {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.cfg.Equal(cfg) {
+		return
+	}
+	l.cfg = cfg
+	l.global.SetLimit(limit(cfg.RequestsPerSecond))
+	l.global.SetBurst(cfg.Burst)
+	for host, limiter := range l.endpoints {
+		limiter.SetLimit(limit(cfg.EndpointLimit(host)))
+		limiter.SetBurst(cfg.Burst)
+	}
+}
+
+// Wait blocks until the request to the endpoint host is allowed or the ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, host string) error {
+	if l == nil {
+		return nil
+	}
+	start := time.Now()
+	endpoint := l.endpoint(host)
+	if err := l.global.Wait(ctx); err != nil {
+		return err
+	}
+	if err := endpoint.Wait(ctx); err != nil {
+		return err
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.waits[host].observe(time.Since(start))
+	return nil
+}
+
+func (l *RateLimiter) endpoint(host string) *rate.Limiter {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	limiter, found := l.endpoints[host]
+	if !found {
+		limiter = rate.NewLimiter(limit(l.cfg.EndpointLimit(host)), l.cfg.Burst)
+		l.endpoints[host] = limiter
+		l.waits[host] = newHistogram()
+	}
+	return limiter
+}
+
+// WaitStats returns the time the requests spent waiting in the limiter by the endpoint host.
+func (l *RateLimiter) WaitStats() map[string]WaitStats {
+	stats := make(map[string]WaitStats)
+	if l == nil {
+		return stats
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for host, waits := range l.waits {
+		stats[host] = WaitStats{Count: waits.count, Buckets: waits.cumulativeBuckets(), Sum: waits.sum}
+	}
+	return stats
+}
@@ -0,0 +1,63 @@
+package source
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lidofinance/terra-monitors/internal/app/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+// waitN waits for the limiter n times and returns the total duration.
+func waitN(t *testing.T, limiter *RateLimiter, n int, hosts ...string) time.Duration {
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		require.NoError(t, limiter.Wait(context.Background(), hosts[i%len(hosts)]))
+	}
+	return time.Since(start)
+}
+
+func TestRateLimiterEndpointQuotas(t *testing.T) {
+	req := require.New(t)
+	limiter := NewRateLimiter(config.RateLimitConfig{
+		EndpointRequestsPerSecond: 20,
+		EndpointQuotas:            config.EndpointQuotas{"unlimited.terra.dev": 0},
+		Burst:                     1,
+	})
+
+	req.Less(int64(waitN(t, limiter, 5, "unlimited.terra.dev")), int64(50*time.Millisecond))
+	// the first request takes the burst token, the rest wait for 50ms each
+	req.GreaterOrEqual(int64(waitN(t, limiter, 3, "limited.terra.dev")), int64(90*time.Millisecond))
+
+	stats := limiter.WaitStats()
+	req.EqualValues(5, stats["unlimited.terra.dev"].Count)
+	req.EqualValues(3, stats["limited.terra.dev"].Count)
+	req.GreaterOrEqual(int64(stats["limited.terra.dev"].Sum), int64(90*time.Millisecond))
+}
+
+func TestRateLimiterGlobalLimit(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimitConfig{RequestsPerSecond: 20, Burst: 1})
+	// the global limit applies to all the endpoints together
+	require.GreaterOrEqual(t, int64(waitN(t, limiter, 3, "fcd.terra.dev", "scp.terra.dev")), int64(90*time.Millisecond))
+}
+
+func TestRateLimiterConfigure(t *testing.T) {
+	req := require.New(t)
+	limiter := NewRateLimiter(config.RateLimitConfig{EndpointRequestsPerSecond: 1, Burst: 1})
+	waitN(t, limiter, 1, "fcd.terra.dev")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req.Error(limiter.Wait(ctx, "fcd.terra.dev"), "the next token is available in a second")
+
+	limiter.Configure(config.RateLimitConfig{EndpointRequestsPerSecond: 0, Burst: 1})
+	req.NoError(limiter.Wait(ctx, "fcd.terra.dev"))
+}
+
+func TestNilRateLimiter(t *testing.T) {
+	var limiter *RateLimiter
+	require.NoError(t, limiter.Wait(context.Background(), "fcd.terra.dev"))
+	require.Empty(t, limiter.WaitStats())
+}
@@ -0,0 +1,95 @@
+package source
+
+import (
+	"context"
+	"sync"
+)
+
+// RequestErrorsKey identifies the request errors counter.
+type RequestErrorsKey struct {
+	Monitor string
+	Class   ErrorClass
+}
+
+// RequestStats counts the failed and retried API requests by the caller monitor. The nil RequestStats counts nothing.
+type RequestStats struct {
+	lock    sync.Mutex
+	errors  map[RequestErrorsKey]uint64
+	retries map[string]uint64
+}
+
+func NewRequestStats() *RequestStats {
+	return &RequestStats{
+		errors:  make(map[RequestErrorsKey]uint64),
+		retries: make(map[string]uint64),
+	}
+}
+
+// Errors returns the numbers of the failed requests by the monitor and the error class. The retried requests
+// are counted once per failed attempt.
+func (s *RequestStats) Errors() map[RequestErrorsKey]uint64 {
+	errors := make(map[RequestErrorsKey]uint64)
+	if s == nil {
+		return errors
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for key, count := range s.errors {
+		errors[key] = count
+	}
+	return errors
+}
+
+// Retries returns the numbers of the retried requests by the monitor.
+func (s *RequestStats) Retries() map[string]uint64 {
+	retries := make(map[string]uint64)
+	if s == nil {
+		return retries
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for monitor, count := range s.retries {
+		retries[monitor] = count
+	}
+	return retries
+}
+
+func (s *RequestStats) recordError(monitor string, class ErrorClass) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.errors[RequestErrorsKey{Monitor: monitor, Class: class}]++
+}
+
+func (s *RequestStats) recordRetry(monitor string) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.retries[monitor]++
+}
+
+// Caller is the monitor making the API requests with the ctx.
+type Caller struct {
+	Monitor string
+	// Stats counts the monitor request errors, optional
+	Stats *RequestStats
+}
+
+type callerKey struct{}
+
+// WithCaller returns the ctx of the requests made by the caller.
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+func callerFromContext(ctx context.Context) Caller {
+	if ctx == nil {
+		return Caller{}
+	}
+	caller, _ := ctx.Value(callerKey{}).(Caller)
+	return caller
+}
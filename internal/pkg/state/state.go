@@ -0,0 +1,77 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/lidofinance/terra-monitors/internal/pkg/utils"
+)
+
+// Store keeps the small states of the monitors, e.g. the last checked block and the accumulated counters,
+// across the restarts. The states are kept by key in a JSON file, which is rewritten atomically on every Save.
+// The states are kept in memory only if the path is empty.
+type Store struct {
+	path   string
+	lock   sync.Mutex
+	values map[string]json.RawMessage
+}
+
+// Open reads the states from the file at the path, the missing file is an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, values: make(map[string]json.RawMessage)}
+	if path == "" {
+		return s, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.values); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Load decodes the state saved by the key to v. It reports whether the state is found.
+func (s *Store) Load(key string, v interface{}) (bool, error) {
+	s.lock.Lock()
+	data, found := s.values[key]
+	s.lock.Unlock()
+	if !found {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to decode state %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// Save replaces the state by the key with v and writes all the states to the file.
+func (s *Store) Save(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode state %s: %w", key, err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.values[key] = data
+	if s.path == "" {
+		return nil
+	}
+	file, err := json.MarshalIndent(s.values, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode states: %w", err)
+	}
+	if err := utils.WriteFileAtomic(s.path, file, 0600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package state
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testState struct {
+	Cursor   int64              `json:"cursor"`
+	Counters map[string]float64 `json:"counters"`
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	var loaded testState
+	found, err := s.Load("mainnet/MissedBlocks", &loaded)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	saved := testState{Cursor: 42, Counters: map[string]float64{"missed": 3}}
+	require.NoError(t, s.Save("mainnet/MissedBlocks", saved))
+	require.NoError(t, s.Save("testnet/MissedBlocks", testState{Cursor: 7}))
+
+	// the states are restored by the reopened store
+	s, err = Open(path)
+	require.NoError(t, err)
+	found, err = s.Load("mainnet/MissedBlocks", &loaded)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, saved, loaded)
+
+	found, err = s.Load("testnet/MissedBlocks", &loaded)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, int64(7), loaded.Cursor)
+
+	var invalid string
+	_, err = s.Load("testnet/MissedBlocks", &invalid)
+	require.Error(t, err)
+}
+
+func TestStoreInMemory(t *testing.T) {
+	s, err := Open("")
+	require.NoError(t, err)
+	require.NoError(t, s.Save("cursor", 1))
+
+	var cursor int
+	found, err := s.Load("cursor", &cursor)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, 1, cursor)
+}
+
+func TestStoreInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte("{"), 0600))
+	_, err := Open(path)
+	require.Error(t, err)
+
+	// the state file directory must exist
+	s, err := Open(filepath.Join(t.TempDir(), "missing", "state.json"))
+	require.NoError(t, err)
+	require.Error(t, s.Save("cursor", 1))
+}
@@ -48,6 +48,8 @@ func NewTestCollectorConfig(urlsWithScheme ...string) config.CollectorConfig {
 			ValidatorsRegistryContract:  types.ValidatorsRegistryContract,
 			AirDropRegistryContract:     types.AirDropRegistryContract,
 		},
+		FetchConcurrency: 4,
+		State:            config.StateConfig{MaxBackfillBlocks: 1000, MaxBackfillTxPages: 10},
 	}
 
 	return cfg
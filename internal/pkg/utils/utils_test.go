@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -28,3 +30,33 @@ func TestTerraMonitorsPath(t *testing.T) {
 		t.Errorf("expected: %s, got: %s", expected, path)
 	}
 }
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	for _, data := range []string{`{"version": 1}`, `{"version": 2}`} {
+		if err := WriteFileAtomic(path, []byte(data), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		written, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(written) != data {
+			t.Errorf("expected: %s, got: %s", data, written)
+		}
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected the temporary files to be removed, got %d files", len(files))
+	}
+
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "state.json"), nil, 0600); err == nil {
+		t.Error("expected an error for the missing directory")
+	}
+}
@@ -0,0 +1,97 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Task processes the i-th item of a batch. The tasks of a batch run concurrently, so a task must only write
+// the results of its own item, e.g. to the i-th element of a preallocated slice.
+type Task func(ctx context.Context, i int) error
+
+// Result describes a processed batch.
+type Result struct {
+	// Total is the number of the batch items
+	Total int
+	// Errors are the errors of the failed tasks by the item index
+	Errors map[int]error
+}
+
+// Failed returns the number of the failed tasks.
+func (r Result) Failed() int {
+	return len(r.Errors)
+}
+
+// AllFailed reports whether the batch is not empty and none of its tasks succeeded.
+func (r Result) AllFailed() bool {
+	return r.Total > 0 && r.Failed() == r.Total
+}
+
+// Err returns an error describing the failed tasks or nil if all the tasks succeeded.
+func (r Result) Err() error {
+	if r.Failed() == 0 {
+		return nil
+	}
+	indexes := make([]int, 0, len(r.Errors))
+	for i := range r.Errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	messages := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		messages = append(messages, fmt.Sprintf("item %d: %v", i, r.Errors[i]))
+	}
+	return fmt.Errorf("%d of %d tasks failed: %s", r.Failed(), r.Total, strings.Join(messages, "; "))
+}
+
+// Run runs the task for every item of the batch of size n with at most concurrency tasks running at once
+// and waits for them. A failed task doesn't stop the rest. Once the ctx is done, the tasks not started yet
+// fail with the ctx error.
+func Run(ctx context.Context, concurrency int, n int, task Task) Result {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+
+	result := Result{Total: n, Errors: make(map[int]error)}
+	var lock sync.Mutex
+	fail := func(i int, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		result.Errors[i] = err
+	}
+
+	items := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range items {
+				if err := task(ctx, i); err != nil {
+					fail(i, err)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		if ctx.Err() != nil {
+			fail(i, ctx.Err())
+			continue
+		}
+		select {
+		case items <- i:
+		case <-ctx.Done():
+			fail(i, ctx.Err())
+		}
+	}
+	close(items)
+	wg.Wait()
+	return result
+}
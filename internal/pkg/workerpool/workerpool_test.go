@@ -0,0 +1,85 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunConcurrencyLimit(t *testing.T) {
+	const concurrency = 3
+	var running, maxRunning int32
+	results := make([]int, 20)
+
+	result := Run(context.Background(), concurrency, len(results), func(ctx context.Context, i int) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		results[i] = i * i
+		return nil
+	})
+
+	require.NoError(t, result.Err())
+	require.Equal(t, 20, result.Total)
+	require.EqualValues(t, concurrency, maxRunning)
+	for i, value := range results {
+		require.Equal(t, i*i, value)
+	}
+}
+
+func TestRunPartialFailure(t *testing.T) {
+	errOdd := errors.New("odd item")
+	results := make([]int, 5)
+
+	result := Run(context.Background(), 2, len(results), func(ctx context.Context, i int) error {
+		if i%2 == 1 {
+			return errOdd
+		}
+		results[i] = i + 1
+		return nil
+	})
+
+	require.Equal(t, 2, result.Failed())
+	require.False(t, result.AllFailed())
+	require.ErrorIs(t, result.Errors[1], errOdd)
+	require.ErrorIs(t, result.Errors[3], errOdd)
+	require.Equal(t, []int{1, 0, 3, 0, 5}, results)
+	require.EqualError(t, result.Err(), "2 of 5 tasks failed: item 1: odd item; item 3: odd item")
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var started int32
+
+	result := Run(ctx, 1, 10, func(ctx context.Context, i int) error {
+		if atomic.AddInt32(&started, 1) == 2 {
+			cancel()
+		}
+		return nil
+	})
+
+	require.Less(t, int(started), 10)
+	require.Equal(t, 10-int(started), result.Failed())
+	for _, err := range result.Errors {
+		require.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func TestRunEmpty(t *testing.T) {
+	result := Run(context.Background(), 4, 0, func(ctx context.Context, i int) error {
+		t.Fatal("no tasks expected")
+		return nil
+	})
+	require.NoError(t, result.Err())
+	require.False(t, result.AllFailed())
+}